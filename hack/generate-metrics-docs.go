@@ -28,6 +28,7 @@ func main() {
 	cfg := config.Load().Target
 	cfg.WorkflowRuns.Labels = config.RunLabels()
 	cfg.WorkflowJobs.Labels = config.JobLabels()
+	cfg.WorkflowCosts.Rates = config.CostRates()
 	cfg.Runners.Labels = config.RunnerLabels()
 
 	collectors = append(
@@ -65,6 +66,31 @@ func main() {
 		exporter.NewWorkflowJobCollector(slog.Default(), nil, nil, nil, nil, cfg).Metrics()...,
 	)
 
+	collectors = append(
+		collectors,
+		exporter.NewWorkflowCostCollector(slog.Default(), nil, nil, nil, nil, cfg).Metrics()...,
+	)
+
+	collectors = append(
+		collectors,
+		exporter.NewDeploymentCollector(slog.Default(), nil, nil, nil, nil, cfg).Metrics()...,
+	)
+
+	collectors = append(
+		collectors,
+		exporter.NewWorkflowRollupCollector(slog.Default(), nil, nil, nil, nil, cfg).Metrics()...,
+	)
+
+	collectors = append(
+		collectors,
+		exporter.NewStoreCollector(slog.Default(), nil, nil, nil, nil, cfg).Metrics()...,
+	)
+
+	collectors = append(
+		collectors,
+		exporter.NewRateLimitCollector(slog.Default(), nil, nil, nil, nil, cfg, nil).Metrics()...,
+	)
+
 	metrics := make([]metric, 0)
 
 	metrics = append(metrics, metric{
@@ -79,6 +105,150 @@ func main() {
 		Labels: []string{"collector"},
 	})
 
+	metrics = append(metrics, metric{
+		Name:   "github_leader",
+		Help:   "Whether this replica is the leader running singleton work like pruning",
+		Labels: []string{},
+	})
+
+	metrics = append(metrics, metric{
+		Name:   "github_rate_limit_requests_total",
+		Help:   "Total number of rate limited requests to the api per collector and resource",
+		Labels: []string{"collector", "resource"},
+	})
+
+	metrics = append(metrics, metric{
+		Name:   "github_rate_limit_response_remaining",
+		Help:   "Remaining requests reported by the last response per collector and resource",
+		Labels: []string{"collector", "resource"},
+	})
+
+	metrics = append(metrics, metric{
+		Name:   "github_cache_requests_total",
+		Help:   "Total number of cacheable requests to the api per collector and result",
+		Labels: []string{"collector", "result"},
+	})
+
+	metrics = append(metrics, metric{
+		Name:   "github_cache_dropped_total",
+		Help:   "Total number of cached responses not persisted as the queue has been full",
+		Labels: []string{},
+	})
+
+	metrics = append(metrics, metric{
+		Name:   "github_credential_requests_total",
+		Help:   "Total number of requests to the api per pooled credential and resource",
+		Labels: []string{"credential", "resource"},
+	})
+
+	metrics = append(metrics, metric{
+		Name:   "github_credential_remaining",
+		Help:   "Remaining requests reported by the last response per pooled credential and resource",
+		Labels: []string{"credential", "resource"},
+	})
+
+	metrics = append(metrics, metric{
+		Name:   "github_credential_rate_limited_total",
+		Help:   "Total number of rate limited responses per pooled credential and resource",
+		Labels: []string{"credential", "resource"},
+	})
+
+	metrics = append(metrics, metric{
+		Name:   "github_request_retries_total",
+		Help:   "Total number of retried requests per collector and reason",
+		Labels: []string{"collector", "reason"},
+	})
+
+	metrics = append(metrics, metric{
+		Name:   "github_snapshot_last_success_timestamp_seconds",
+		Help:   "Timestamp of the last successful snapshot refresh per collector",
+		Labels: []string{"collector"},
+	})
+
+	metrics = append(metrics, metric{
+		Name:   "github_snapshot_stale",
+		Help:   "Whether the snapshot of the collector exceeds the staleness limit and is not served",
+		Labels: []string{"collector"},
+	})
+
+	metrics = append(metrics, metric{
+		Name:   "github_snapshot_refresh_duration_seconds",
+		Help:   "Histogram of latencies for snapshot refreshes per collector",
+		Labels: []string{"collector"},
+	})
+
+	metrics = append(metrics, metric{
+		Name:   "github_snapshot_refresh_failures_total",
+		Help:   "Total number of failed snapshot refreshes per collector",
+		Labels: []string{"collector"},
+	})
+
+	metrics = append(metrics, metric{
+		Name:   "github_app_installations",
+		Help:   "Number of discovered installations of the GitHub app",
+		Labels: []string{},
+	})
+
+	metrics = append(metrics, metric{
+		Name:   "github_app_refresh_failures_total",
+		Help:   "Total number of failed refreshes of the GitHub app installations",
+		Labels: []string{},
+	})
+
+	metrics = append(metrics, metric{
+		Name:   "github_prune_rows_deleted_total",
+		Help:   "Total number of rows deleted by the pruner per table",
+		Labels: []string{"table"},
+	})
+
+	metrics = append(metrics, metric{
+		Name:   "github_prune_rule_rows_deleted_total",
+		Help:   "Total number of rows deleted by the pruner per table and retention rule",
+		Labels: []string{"table", "rule"},
+	})
+
+	metrics = append(metrics, metric{
+		Name:   "github_prune_failures_total",
+		Help:   "Total number of failed prune runs per table",
+		Labels: []string{"table"},
+	})
+
+	metrics = append(metrics, metric{
+		Name:   "github_prune_duration_seconds",
+		Help:   "Histogram of latencies for prune runs per table",
+		Labels: []string{"table"},
+	})
+
+	metrics = append(metrics, metric{
+		Name:   "github_prune_last_success_timestamp_seconds",
+		Help:   "Timestamp of the last successful prune run per table",
+		Labels: []string{"table"},
+	})
+
+	metrics = append(metrics, metric{
+		Name:   "github_rollup_failures_total",
+		Help:   "Total number of failed workflow run rollups",
+		Labels: []string{},
+	})
+
+	metrics = append(metrics, metric{
+		Name:   "github_rollup_last_success_timestamp_seconds",
+		Help:   "Timestamp of the last successful workflow run rollup",
+		Labels: []string{},
+	})
+
+	metrics = append(metrics, metric{
+		Name:   "github_store_operation_duration_seconds",
+		Help:   "Histogram of latencies for database operations per operation and table",
+		Labels: []string{"operation", "table"},
+	})
+
+	metrics = append(metrics, metric{
+		Name:   "github_webhook_regressions_total",
+		Help:   "Total number of webhook events delivered out of order per type",
+		Labels: []string{"type"},
+	})
+
 	for _, desc := range collectors {
 		m := metric{
 			Name:   reflect.ValueOf(desc).Elem().FieldByName("fqName").String(),
@@ -0,0 +1,99 @@
+package action
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/promhippie/github_exporter/pkg/config"
+	"github.com/promhippie/github_exporter/pkg/store"
+)
+
+const (
+	// rollupLease defines the name of the lease shared by all aggregating
+	// replicas.
+	rollupLease = "rollup"
+)
+
+// aggregator periodically refreshes the workflow run rollups independent of
+// the pruner. All replicas share a dedicated lease, even without leader
+// election, that way exactly one replica aggregates at a time.
+type aggregator struct {
+	logger   *slog.Logger
+	db       store.Store
+	interval time.Duration
+	lookback time.Duration
+	holder   string
+}
+
+// newAggregator prepares the aggregator for the workflow run rollups.
+func newAggregator(cfg *config.Config, db store.Store, logger *slog.Logger) *aggregator {
+	holder := leaderIdentity(cfg)
+
+	return &aggregator{
+		logger:   logger.With("actor", "aggregator", "holder", holder),
+		db:       db,
+		interval: cfg.Target.WorkflowRollups.Interval,
+		lookback: cfg.Target.WorkflowRollups.Lookback,
+		holder:   holder,
+	}
+}
+
+// Run aggregates once on startup and afterwards on every interval until the
+// context gets canceled.
+func (a *aggregator) Run(ctx context.Context) error {
+	a.logger.Info("Starting aggregator",
+		"interval", a.interval,
+		"lookback", a.lookback,
+	)
+
+	for {
+		a.aggregate()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(a.interval):
+		}
+	}
+}
+
+// aggregate refreshes the workflow run rollups once. The lease outlives two
+// intervals, that way the current holder keeps it while another replica
+// takes over if the holder disappears.
+func (a *aggregator) aggregate() {
+	acquired, err := a.db.AcquireLease(rollupLease, a.holder, 2*a.interval)
+
+	if err != nil {
+		a.logger.Error("Failed to acquire rollup lease",
+			"err", err,
+		)
+
+		rollupFailures.Inc()
+		return
+	}
+
+	if !acquired {
+		a.logger.Debug("Skipping rollup, lease is held by another replica")
+		return
+	}
+
+	now := time.Now()
+	written, err := a.db.RollupWorkflowRuns(a.lookback)
+
+	if err != nil {
+		a.logger.Error("Failed to rollup workflow runs",
+			"err", err,
+		)
+
+		rollupFailures.Inc()
+		return
+	}
+
+	a.logger.Debug("Refreshed workflow run rollups",
+		"written", written,
+		"duration", time.Since(now),
+	)
+
+	rollupLastSuccess.SetToCurrentTime()
+}
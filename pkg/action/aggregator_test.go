@@ -0,0 +1,31 @@
+package action
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/promhippie/github_exporter/pkg/config"
+	"github.com/promhippie/github_exporter/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregator(t *testing.T) {
+	db, err := store.New("memory://", slog.Default())
+	require.NoError(t, err)
+
+	cfg := config.Load()
+	cfg.Target.WorkflowRollups.Interval = time.Minute
+	cfg.Target.WorkflowRollups.Lookback = time.Hour
+
+	cfg.Leader.Identity = "first"
+
+	a := newAggregator(cfg, db, slog.Default())
+	assert.Equal(t, "first", a.holder)
+
+	rollupLastSuccess.Set(0)
+	a.aggregate()
+	assert.NotZero(t, testutil.ToFloat64(rollupLastSuccess))
+}
@@ -0,0 +1,155 @@
+package action
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/promhippie/github_exporter/pkg/store"
+)
+
+const (
+	// apiDefaultLimit defines the page size if no limit has been requested.
+	apiDefaultLimit = 100
+
+	// apiMaxLimit defines the maximum page size of the API.
+	apiMaxLimit = 1000
+)
+
+// apiPage defines the response of the API list endpoints.
+type apiPage[T any] struct {
+	Offset  int `json:"offset"`
+	Limit   int `json:"limit"`
+	Records []T `json:"records"`
+}
+
+// apiError defines the response of the API if a request fails.
+type apiError struct {
+	Message string `json:"message"`
+}
+
+// api provides the read-only JSON API for the stored workflow runs and jobs.
+func api(db store.Store, logger *slog.Logger) http.Handler {
+	mux := chi.NewRouter()
+	logger = logger.With("handler", "api")
+
+	mux.Get("/runs", func(w http.ResponseWriter, r *http.Request) {
+		filter, err := apiFilter(r.URL.Query())
+
+		if err != nil {
+			apiRender(w, http.StatusBadRequest, apiError{Message: err.Error()})
+			return
+		}
+
+		records, err := db.QueryWorkflowRuns(filter)
+
+		if err != nil {
+			logger.Error("Failed to query workflow runs",
+				"err", err,
+			)
+
+			apiRender(w, http.StatusInternalServerError, apiError{Message: http.StatusText(http.StatusInternalServerError)})
+			return
+		}
+
+		apiRender(w, http.StatusOK, apiPage[*store.WorkflowRun]{
+			Offset:  filter.Offset,
+			Limit:   filter.Limit,
+			Records: records,
+		})
+	})
+
+	mux.Get("/jobs", func(w http.ResponseWriter, r *http.Request) {
+		filter, err := apiFilter(r.URL.Query())
+
+		if err != nil {
+			apiRender(w, http.StatusBadRequest, apiError{Message: err.Error()})
+			return
+		}
+
+		records, err := db.QueryWorkflowJobs(filter)
+
+		if err != nil {
+			logger.Error("Failed to query workflow jobs",
+				"err", err,
+			)
+
+			apiRender(w, http.StatusInternalServerError, apiError{Message: http.StatusText(http.StatusInternalServerError)})
+			return
+		}
+
+		apiRender(w, http.StatusOK, apiPage[*store.WorkflowJob]{
+			Offset:  filter.Offset,
+			Limit:   filter.Limit,
+			Records: records,
+		})
+	})
+
+	return mux
+}
+
+// apiFilter parses the filter and the page from the query parameters.
+func apiFilter(params url.Values) (*store.Filter, error) {
+	filter := &store.Filter{
+		Owner:    params.Get("owner"),
+		Repo:     params.Get("repo"),
+		Workflow: params.Get("workflow"),
+		Branch:   params.Get("branch"),
+		Status:   params.Get("status"),
+		Limit:    apiDefaultLimit,
+	}
+
+	if val := params.Get("since"); val != "" {
+		since, err := store.ParseTimestamp(val)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid since: %w", err)
+		}
+
+		filter.Since = since
+	}
+
+	if val := params.Get("until"); val != "" {
+		until, err := store.ParseTimestamp(val)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid until: %w", err)
+		}
+
+		filter.Until = until
+	}
+
+	if val := params.Get("offset"); val != "" {
+		offset, err := strconv.Atoi(val)
+
+		if err != nil || offset < 0 {
+			return nil, fmt.Errorf("invalid offset: %s", val)
+		}
+
+		filter.Offset = offset
+	}
+
+	if val := params.Get("limit"); val != "" {
+		limit, err := strconv.Atoi(val)
+
+		if err != nil || limit < 1 || limit > apiMaxLimit {
+			return nil, fmt.Errorf("invalid limit, must be between 1 and %d: %s", apiMaxLimit, val)
+		}
+
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
+
+// apiRender writes the response as JSON.
+func apiRender(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,58 @@
+package action
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/promhippie/github_exporter/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIRuns(t *testing.T) {
+	db, err := store.New("memory://", slog.Default())
+	require.NoError(t, err)
+
+	now := time.Now().Unix()
+
+	require.NoError(t, db.ImportWorkflowRuns([]*store.WorkflowRun{
+		{Owner: "promhippie", Repo: "github_exporter", WorkflowID: 1, Number: 1, Name: "ci", Branch: "main", Status: "failure", UpdatedAt: now - 60},
+		{Owner: "promhippie", Repo: "github_exporter", WorkflowID: 1, Number: 2, Name: "ci", Branch: "main", Status: "failure", UpdatedAt: now},
+		{Owner: "promhippie", Repo: "github_exporter", WorkflowID: 1, Number: 3, Name: "ci", Branch: "feature", Status: "failure", UpdatedAt: now},
+		{Owner: "promhippie", Repo: "github_exporter", WorkflowID: 1, Number: 4, Name: "ci", Branch: "main", Status: "success", UpdatedAt: now},
+	}))
+
+	rec := httptest.NewRecorder()
+	api(db, slog.Default()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/runs?branch=main&status=failure&workflow=ci&limit=1", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	page := apiPage[*store.WorkflowRun]{}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+	require.Len(t, page.Records, 1)
+	assert.Equal(t, 2, page.Records[0].Number)
+
+	rec = httptest.NewRecorder()
+	api(db, slog.Default()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/runs?limit=5000", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAPIFilter(t *testing.T) {
+	filter, err := apiFilter(map[string][]string{
+		"since":  {"2024-01-01T00:00:00Z"},
+		"until":  {"1704153600"},
+		"offset": {"20"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(1704067200), filter.Since)
+	assert.Equal(t, int64(1704153600), filter.Until)
+	assert.Equal(t, 20, filter.Offset)
+	assert.Equal(t, apiDefaultLimit, filter.Limit)
+
+	_, err = apiFilter(map[string][]string{"since": {"yesterday"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,115 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/promhippie/github_exporter/pkg/config"
+	"github.com/promhippie/github_exporter/pkg/store"
+)
+
+const (
+	// leaderLease defines the name of the lease shared by all replicas.
+	leaderLease = "leader"
+)
+
+// elector periodically acquires or renews the leader lease within the store,
+// singleton work like pruning only runs on the current leader.
+type elector struct {
+	logger   *slog.Logger
+	db       store.Store
+	holder   string
+	ttl      time.Duration
+	interval time.Duration
+	leader   atomic.Bool
+}
+
+// newElector prepares the elector for the identity of the replica.
+func newElector(cfg *config.Config, db store.Store, logger *slog.Logger) *elector {
+	holder := leaderIdentity(cfg)
+	leaderGauge.Set(0)
+
+	return &elector{
+		logger:   logger.With("actor", "elector", "holder", holder),
+		db:       db,
+		holder:   holder,
+		ttl:      cfg.Leader.TTL,
+		interval: cfg.Leader.TTL / 3,
+	}
+}
+
+// Leader returns if this replica currently holds the lease.
+func (e *elector) Leader() bool {
+	return e.leader.Load()
+}
+
+// Run executes the elector until the context gets canceled, afterwards the
+// lease gets released so another replica can take over immediately.
+func (e *elector) Run(ctx context.Context) error {
+	e.logger.Info("Starting leader election",
+		"ttl", e.ttl,
+	)
+
+	for {
+		e.elect()
+
+		select {
+		case <-ctx.Done():
+			if e.leader.Swap(false) {
+				leaderGauge.Set(0)
+
+				if err := e.db.ReleaseLease(leaderLease, e.holder); err != nil {
+					e.logger.Error("Failed to release leader lease",
+						"err", err,
+					)
+				}
+			}
+
+			return nil
+		case <-time.After(e.interval):
+		}
+	}
+}
+
+// elect acquires or renews the lease once, if the store is not reachable the
+// replica steps down as the lease could expire in the meantime.
+func (e *elector) elect() {
+	acquired, err := e.db.AcquireLease(leaderLease, e.holder, e.ttl)
+
+	if err != nil {
+		e.logger.Error("Failed to acquire leader lease",
+			"err", err,
+		)
+
+		acquired = false
+	}
+
+	if previous := e.leader.Swap(acquired); previous != acquired {
+		if acquired {
+			e.logger.Info("Became leader")
+		} else {
+			e.logger.Info("Lost leadership")
+		}
+	}
+
+	if acquired {
+		leaderGauge.Set(1)
+	} else {
+		leaderGauge.Set(0)
+	}
+}
+
+// leaderIdentity identifies the replica by the hostname and the process ID if
+// no identity has been configured.
+func leaderIdentity(cfg *config.Config) string {
+	if cfg.Leader.Identity != "" {
+		return cfg.Leader.Identity
+	}
+
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
@@ -0,0 +1,30 @@
+package action
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/promhippie/github_exporter/pkg/config"
+	"github.com/promhippie/github_exporter/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElector(t *testing.T) {
+	db, err := store.New("memory://", slog.Default())
+	require.NoError(t, err)
+
+	cfg := config.Load()
+	cfg.Leader.TTL = 30 * time.Second
+
+	e := newElector(cfg, db, slog.Default())
+	assert.False(t, e.Leader())
+	assert.NotEmpty(t, e.holder)
+
+	e.elect()
+	assert.True(t, e.Leader())
+	assert.Equal(t, 1.0, testutil.ToFloat64(leaderGauge))
+	assert.Equal(t, 10*time.Second, e.interval)
+}
@@ -3,7 +3,9 @@ package action
 import (
 	"fmt"
 	"log/slog"
+	"time"
 
+	"github.com/google/go-github/v90/github"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/promhippie/github_exporter/pkg/version"
@@ -33,6 +35,201 @@ var (
 		},
 		[]string{"collector"},
 	)
+
+	pruneRows = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "prune_rows_deleted_total",
+			Help:      "Total number of rows deleted by the pruner per table.",
+		},
+		[]string{"table"},
+	)
+
+	pruneRuleRows = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "prune_rule_rows_deleted_total",
+			Help:      "Total number of rows deleted by the pruner per table and retention rule.",
+		},
+		[]string{"table", "rule"},
+	)
+
+	pruneFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "prune_failures_total",
+			Help:      "Total number of failed prune runs per table.",
+		},
+		[]string{"table"},
+	)
+
+	pruneDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "prune_duration_seconds",
+			Help:      "Histogram of latencies for prune runs per table.",
+			Buckets:   []float64{0.01, 0.1, 0.5, 1.0, 5.0, 10.0, 30.0, 60.0},
+		},
+		[]string{"table"},
+	)
+
+	pruneLastSuccess = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "prune_last_success_timestamp_seconds",
+			Help:      "Timestamp of the last successful prune run per table.",
+		},
+		[]string{"table"},
+	)
+
+	rollupFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rollup_failures_total",
+			Help:      "Total number of failed workflow run rollups.",
+		},
+	)
+
+	rollupLastSuccess = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "rollup_last_success_timestamp_seconds",
+			Help:      "Timestamp of the last successful workflow run rollup.",
+		},
+	)
+
+	leaderGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "leader",
+			Help:      "Whether this replica is the leader running singleton work like pruning.",
+		},
+	)
+
+	storeDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "store_operation_duration_seconds",
+			Help:      "Histogram of latencies for database operations per operation and table.",
+			Buckets:   []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1.0, 5.0},
+		},
+		[]string{"operation", "table"},
+	)
+
+	rateRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rate_limit_requests_total",
+			Help:      "Total number of rate limited requests to the api per collector and resource.",
+		},
+		[]string{"collector", "resource"},
+	)
+
+	rateRemaining = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "rate_limit_response_remaining",
+			Help:      "Remaining requests reported by the last response per collector and resource.",
+		},
+		[]string{"collector", "resource"},
+	)
+
+	cacheRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_requests_total",
+			Help:      "Total number of cacheable requests to the api per collector and result.",
+		},
+		[]string{"collector", "result"},
+	)
+
+	cacheDropped = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_dropped_total",
+			Help:      "Total number of cached responses not persisted as the queue has been full.",
+		},
+	)
+
+	credentialRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "credential_requests_total",
+			Help:      "Total number of requests to the api per pooled credential and resource.",
+		},
+		[]string{"credential", "resource"},
+	)
+
+	credentialRemaining = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "credential_remaining",
+			Help:      "Remaining requests reported by the last response per pooled credential and resource.",
+		},
+		[]string{"credential", "resource"},
+	)
+
+	credentialLimited = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "credential_rate_limited_total",
+			Help:      "Total number of rate limited responses per pooled credential and resource.",
+		},
+		[]string{"credential", "resource"},
+	)
+
+	requestRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "request_retries_total",
+			Help:      "Total number of retried requests per collector and reason.",
+		},
+		[]string{"collector", "reason"},
+	)
+
+	snapshotDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "snapshot_refresh_duration_seconds",
+			Help:      "Histogram of latencies for snapshot refreshes per collector.",
+			Buckets:   []float64{0.1, 0.5, 1.0, 5.0, 10.0, 30.0, 60.0, 120.0},
+		},
+		[]string{"collector"},
+	)
+
+	snapshotFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "snapshot_refresh_failures_total",
+			Help:      "Total number of failed snapshot refreshes per collector.",
+		},
+		[]string{"collector"},
+	)
+
+	appInstallations = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "app_installations",
+			Help:      "Number of discovered installations of the GitHub app.",
+		},
+	)
+
+	appRefreshFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "app_refresh_failures_total",
+			Help:      "Total number of failed refreshes of the GitHub app installations.",
+		},
+	)
+
+	webhookRegressions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "webhook_regressions_total",
+			Help:      "Total number of webhook events delivered out of order per type.",
+		},
+		[]string{"type"},
+	)
 )
 
 func init() {
@@ -45,6 +242,89 @@ func init() {
 
 	registry.MustRegister(requestDuration)
 	registry.MustRegister(requestFailures)
+	registry.MustRegister(webhookRegressions)
+	registry.MustRegister(pruneRows)
+	registry.MustRegister(pruneRuleRows)
+	registry.MustRegister(pruneFailures)
+	registry.MustRegister(pruneDuration)
+	registry.MustRegister(pruneLastSuccess)
+	registry.MustRegister(rollupFailures)
+	registry.MustRegister(rollupLastSuccess)
+	registry.MustRegister(storeDuration)
+	registry.MustRegister(leaderGauge)
+	registry.MustRegister(rateRequests)
+	registry.MustRegister(rateRemaining)
+	registry.MustRegister(cacheRequests)
+	registry.MustRegister(cacheDropped)
+	registry.MustRegister(credentialRequests)
+	registry.MustRegister(credentialRemaining)
+	registry.MustRegister(credentialLimited)
+	registry.MustRegister(requestRetries)
+	registry.MustRegister(snapshotDuration)
+	registry.MustRegister(snapshotFailures)
+	registry.MustRegister(appInstallations)
+	registry.MustRegister(appRefreshFailures)
+}
+
+// observeStore records the latency of instrumented store operations.
+func observeStore(operation, table string, duration time.Duration) {
+	storeDuration.WithLabelValues(operation, table).Observe(duration.Seconds())
+}
+
+// observeRate records the rate limit headers of responses per collector.
+func observeRate(collector string, rate github.Rate) {
+	rateRequests.WithLabelValues(collector, rate.Resource).Inc()
+	rateRemaining.WithLabelValues(collector, rate.Resource).Set(float64(rate.Remaining))
+}
+
+// observeCredential records the usage and the budget of pooled credentials.
+func observeCredential(credential, resource string, rate *github.Rate, limited bool) {
+	credentialRequests.WithLabelValues(credential, resource).Inc()
+
+	if rate != nil {
+		credentialRemaining.WithLabelValues(credential, resource).Set(float64(rate.Remaining))
+	}
+
+	if limited {
+		credentialLimited.WithLabelValues(credential, resource).Inc()
+	}
+}
+
+// observeRetry records retried requests per collector.
+func observeRetry(collector, reason string) {
+	requestRetries.WithLabelValues(collector, reason).Inc()
+}
+
+// observeSnapshot records the result of snapshot refreshes per collector.
+func observeSnapshot(collector string, duration time.Duration, success bool) {
+	snapshotDuration.WithLabelValues(collector).Observe(duration.Seconds())
+
+	if !success {
+		snapshotFailures.WithLabelValues(collector).Inc()
+	}
+}
+
+// observeInstallations records the result of installation refreshes.
+func observeInstallations(count int, err error) {
+	appInstallations.Set(float64(count))
+
+	if err != nil {
+		appRefreshFailures.Inc()
+	}
+}
+
+// observeCache records hits and misses of the response cache per collector.
+func observeCache(collector string, hit bool) {
+	if hit {
+		cacheRequests.WithLabelValues(collector, "hit").Inc()
+	} else {
+		cacheRequests.WithLabelValues(collector, "miss").Inc()
+	}
+}
+
+// observeCacheDrop records responses not persisted by the response cache.
+func observeCacheDrop() {
+	cacheDropped.Inc()
 }
 
 type promLogger struct {
@@ -0,0 +1,198 @@
+package action
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/promhippie/github_exporter/pkg/config"
+	"github.com/promhippie/github_exporter/pkg/store"
+)
+
+// pruneTask defines a single table which gets pruned by the pruner, the
+// deleted rows get reported per retention rule.
+type pruneTask struct {
+	table string
+	rules []string
+	prune func(int) (map[string]int64, error)
+}
+
+// pruner periodically deletes outdated records of the enabled collectors.
+type pruner struct {
+	logger   *slog.Logger
+	interval time.Duration
+	limit    int
+	tasks    []pruneTask
+	leader   func() bool
+}
+
+// newPruner prepares the pruner based on the enabled collectors, it only
+// works while the leader function reports this replica as the leader.
+func newPruner(cfg *config.Config, db store.Store, leader func() bool, logger *slog.Logger) *pruner {
+	tasks := make([]pruneTask, 0)
+
+	if cfg.Collector.WorkflowRuns || cfg.Collector.WorkflowCosts || cfg.Collector.WorkflowRollups {
+		retention := pruneRetention(cfg.Target.WorkflowRuns.PurgeWindow, cfg.Target.WorkflowRuns.Retention, logger)
+
+		tasks = append(tasks, pruneTask{
+			table: "workflow_runs",
+			rules: pruneRules(retention),
+			prune: func(limit int) (map[string]int64, error) {
+				return db.PruneWorkflowRuns(retention, limit)
+			},
+		})
+	}
+
+	if cfg.Collector.WorkflowJobs || cfg.Collector.WorkflowCosts {
+		retention := pruneRetention(cfg.Target.WorkflowJobs.PurgeWindow, cfg.Target.WorkflowJobs.Retention, logger)
+
+		tasks = append(tasks, pruneTask{
+			table: "workflow_jobs",
+			rules: pruneRules(retention),
+			prune: func(limit int) (map[string]int64, error) {
+				return db.PruneWorkflowJobs(retention, limit)
+			},
+		})
+	}
+
+	if cfg.Collector.Deployments {
+		tasks = append(tasks, pruneTask{
+			table: "deployment_reviews",
+			rules: []string{store.RetentionDefault},
+			prune: pruneWindow(cfg.Target.Deployments.PurgeWindow, db.PruneDeploymentReviews),
+		})
+	}
+
+	if cfg.Collector.WorkflowRollups {
+		tasks = append(tasks, pruneTask{
+			table: "workflow_run_rollups",
+			rules: []string{store.RetentionDefault},
+			prune: pruneWindow(cfg.Target.WorkflowRollups.PurgeWindow, db.PruneWorkflowRunRollups),
+		})
+	}
+
+	for _, task := range tasks {
+		pruneRows.WithLabelValues(task.table).Add(0)
+		pruneFailures.WithLabelValues(task.table).Add(0)
+
+		for _, rule := range task.rules {
+			pruneRuleRows.WithLabelValues(task.table, rule).Add(0)
+		}
+	}
+
+	return &pruner{
+		logger:   logger.With("actor", "pruner"),
+		interval: cfg.Pruner.Interval,
+		limit:    cfg.Pruner.BatchSize,
+		tasks:    tasks,
+		leader:   leader,
+	}
+}
+
+// Run executes the pruner until the context gets canceled. Every run gets
+// delayed by a random jitter, that way multiple exporters sharing the same
+// database are not pruning at the same time.
+func (p *pruner) Run(ctx context.Context) error {
+	p.logger.Info("Starting pruner",
+		"interval", p.interval,
+		"batch_size", p.limit,
+	)
+
+	for {
+		jitter := time.Duration(rand.Int64N(int64(p.interval/10) + 1))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(jitter):
+			if p.leader() {
+				p.prune()
+			} else {
+				p.logger.Debug("Skipping pruner, replica is not the leader")
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(p.interval - jitter):
+		}
+	}
+}
+
+// prune executes all prune tasks once.
+func (p *pruner) prune() {
+	for _, task := range p.tasks {
+		now := time.Now()
+		result, err := task.prune(p.limit)
+		pruneDuration.WithLabelValues(task.table).Observe(time.Since(now).Seconds())
+
+		deleted := int64(0)
+
+		for rule, rows := range result {
+			pruneRuleRows.WithLabelValues(task.table, rule).Add(float64(rows))
+			deleted += rows
+		}
+
+		pruneRows.WithLabelValues(task.table).Add(float64(deleted))
+
+		if err != nil {
+			p.logger.Error("Failed to prune records",
+				"table", task.table,
+				"err", err,
+			)
+
+			pruneFailures.WithLabelValues(task.table).Inc()
+			continue
+		}
+
+		p.logger.Debug("Pruned records",
+			"table", task.table,
+			"deleted", deleted,
+			"duration", time.Since(now),
+		)
+
+		pruneLastSuccess.WithLabelValues(task.table).SetToCurrentTime()
+	}
+}
+
+// pruneRetention builds the retention for the purge window and the rules,
+// invalid rules have already been rejected on startup and get skipped.
+func pruneRetention(window time.Duration, values []string, logger *slog.Logger) store.Retention {
+	rules, err := store.ParseRetentionRules(values)
+
+	if err != nil {
+		logger.Error("Failed to parse retention rules",
+			"err", err,
+		)
+	}
+
+	return store.Retention{
+		Window: window,
+		Rules:  rules,
+	}
+}
+
+// pruneRules lists the rule names of the retention including the default.
+func pruneRules(retention store.Retention) []string {
+	result := make([]string, 0, len(retention.Rules)+1)
+
+	for _, rule := range retention.Rules {
+		result = append(result, rule.Pattern)
+	}
+
+	return append(result, store.RetentionDefault)
+}
+
+// pruneWindow adapts prune functions without retention rules, all rows get
+// reported for the default rule.
+func pruneWindow(window time.Duration, prune func(time.Duration, int) (int64, error)) func(int) (map[string]int64, error) {
+	return func(limit int) (map[string]int64, error) {
+		deleted, err := prune(window, limit)
+
+		return map[string]int64{
+			store.RetentionDefault: deleted,
+		}, err
+	}
+}
@@ -0,0 +1,118 @@
+package action
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/promhippie/github_exporter/pkg/config"
+	"github.com/promhippie/github_exporter/pkg/exporter"
+)
+
+// scheduler refreshes the registered collectors in the background, scrapes
+// are served from the latest snapshot of every collector.
+type scheduler struct {
+	logger    *slog.Logger
+	enabled   bool
+	interval  time.Duration
+	intervals map[string]time.Duration
+	staleness time.Duration
+	snapshots []*exporter.Snapshot
+}
+
+// newScheduler prepares the scheduler, the intervals have already been
+// validated on startup.
+func newScheduler(cfg *config.Config, logger *slog.Logger) *scheduler {
+	intervals, _ := exporter.ParseSnapshotIntervals(cfg.Scheduler.Intervals)
+
+	return &scheduler{
+		logger:    logger.With("actor", "scheduler"),
+		enabled:   cfg.Scheduler.Enabled,
+		interval:  cfg.Scheduler.Interval,
+		intervals: intervals,
+		staleness: cfg.Scheduler.Staleness,
+		snapshots: make([]*exporter.Snapshot, 0),
+	}
+}
+
+// Register wraps the collector within a snapshot if the scheduler is
+// enabled, otherwise the collector is returned unchanged.
+func (s *scheduler) Register(name string, collector prometheus.Collector) prometheus.Collector {
+	if !s.enabled {
+		return collector
+	}
+
+	interval, ok := s.intervals[name]
+
+	if !ok {
+		interval = s.interval
+	}
+
+	snapshot := exporter.NewSnapshot(
+		s.logger,
+		name,
+		collector,
+		requestFailures,
+		interval,
+		s.staleness,
+	)
+
+	registry.MustRegister(prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Name:        "snapshot_last_success_timestamp_seconds",
+			Help:        "Timestamp of the last successful snapshot refresh per collector.",
+			ConstLabels: prometheus.Labels{"collector": name},
+		},
+		func() float64 {
+			if success := snapshot.LastSuccess(); !success.IsZero() {
+				return float64(success.Unix())
+			}
+
+			return 0
+		},
+	))
+
+	registry.MustRegister(prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Name:        "snapshot_stale",
+			Help:        "Whether the snapshot of the collector exceeds the staleness limit and is not served.",
+			ConstLabels: prometheus.Labels{"collector": name},
+		},
+		func() float64 {
+			if snapshot.Stale() {
+				return 1
+			}
+
+			return 0
+		},
+	))
+
+	snapshotFailures.WithLabelValues(name).Add(0)
+	s.snapshots = append(s.snapshots, snapshot)
+
+	return snapshot
+}
+
+// Run refreshes all snapshots on their own interval until the context gets
+// canceled.
+func (s *scheduler) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+
+	for _, snapshot := range s.snapshots {
+		s.logger.Info("Starting snapshot refresh",
+			"collector", snapshot.Name(),
+			"interval", snapshot.Interval(),
+		)
+
+		wg.Go(func() {
+			snapshot.Run(ctx, observeSnapshot)
+		})
+	}
+
+	wg.Wait()
+	return nil
+}
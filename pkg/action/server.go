@@ -3,11 +3,16 @@ package action
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,6 +26,7 @@ import (
 	"github.com/promhippie/github_exporter/pkg/exporter"
 	"github.com/promhippie/github_exporter/pkg/middleware"
 	"github.com/promhippie/github_exporter/pkg/store"
+	"github.com/promhippie/github_exporter/pkg/transport"
 	"github.com/promhippie/github_exporter/pkg/version"
 )
 
@@ -33,7 +39,9 @@ func Server(cfg *config.Config, db store.Store, logger *slog.Logger) error {
 		"go", version.Go,
 	)
 
-	client, err := getClient(cfg, logger)
+	db = store.Instrument(db, observeStore)
+
+	client, credentials, workers, err := getClient(cfg, db, logger)
 
 	if err != nil {
 		return err
@@ -41,10 +49,12 @@ func Server(cfg *config.Config, db store.Store, logger *slog.Logger) error {
 
 	var gr run.Group
 
+	sched := newScheduler(cfg, logger)
+
 	{
 		server := &http.Server{
 			Addr:         cfg.Server.Addr,
-			Handler:      handler(cfg, db, logger, client),
+			Handler:      handler(cfg, db, logger, client, credentials, sched),
 			ReadTimeout:  5 * time.Second,
 			WriteTimeout: cfg.Server.Timeout,
 		}
@@ -81,6 +91,77 @@ func Server(cfg *config.Config, db store.Store, logger *slog.Logger) error {
 		})
 	}
 
+	leader := func() bool { return true }
+
+	if cfg.Leader.Election {
+		e := newElector(cfg, db, logger)
+		leader = e.Leader
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		gr.Add(func() error {
+			return e.Run(ctx)
+		}, func(_ error) {
+			cancel()
+		})
+	} else {
+		leaderGauge.Set(1)
+	}
+
+	if cfg.Pruner.Interval > 0 {
+		p := newPruner(cfg, db, leader, logger)
+
+		if len(p.tasks) > 0 {
+			ctx, cancel := context.WithCancel(context.Background())
+
+			gr.Add(func() error {
+				return p.Run(ctx)
+			}, func(_ error) {
+				cancel()
+			})
+		}
+	}
+
+	if cfg.Collector.WorkflowRollups {
+		a := newAggregator(cfg, db, logger)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		gr.Add(func() error {
+			return a.Run(ctx)
+		}, func(_ error) {
+			cancel()
+		})
+	}
+
+	if len(sched.snapshots) > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		gr.Add(func() error {
+			return sched.Run(ctx)
+		}, func(_ error) {
+			cancel()
+		})
+	}
+
+	for _, w := range workers {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		gr.Add(func() error {
+			if err := w.run(ctx); err != nil {
+				logger.Error("Failed to run background worker",
+					"worker", w.name,
+					"err", err,
+				)
+
+				return err
+			}
+
+			return nil
+		}, func(_ error) {
+			cancel()
+		})
+	}
+
 	{
 		stop := make(chan os.Signal, 1)
 
@@ -98,7 +179,7 @@ func Server(cfg *config.Config, db store.Store, logger *slog.Logger) error {
 	return gr.Run()
 }
 
-func handler(cfg *config.Config, db store.Store, logger *slog.Logger, client *github.Client) *chi.Mux {
+func handler(cfg *config.Config, db store.Store, logger *slog.Logger, client *github.Client, credentials []string, sched *scheduler) *chi.Mux {
 	mux := chi.NewRouter()
 	mux.Use(middleware.Recoverer(logger))
 	mux.Use(middleware.RealIP)
@@ -112,92 +193,158 @@ func handler(cfg *config.Config, db store.Store, logger *slog.Logger, client *gi
 	if cfg.Collector.Admin {
 		logger.Debug("Admin collector registered")
 
-		registry.MustRegister(exporter.NewAdminCollector(
+		registry.MustRegister(sched.Register("admin", exporter.NewAdminCollector(
 			logger,
 			client,
 			db,
 			requestFailures,
 			requestDuration,
 			cfg.Target,
-		))
+		)))
 	}
 
 	if cfg.Collector.Orgs {
 		logger.Debug("Org collector registered")
 
-		registry.MustRegister(exporter.NewOrgCollector(
+		registry.MustRegister(sched.Register("org", exporter.NewOrgCollector(
 			logger,
 			client,
 			db,
 			requestFailures,
 			requestDuration,
 			cfg.Target,
-		))
+		)))
 	}
 
 	if cfg.Collector.Repos {
 		logger.Debug("Repo collector registered")
 
-		registry.MustRegister(exporter.NewRepoCollector(
+		registry.MustRegister(sched.Register("repo", exporter.NewRepoCollector(
 			logger,
 			client,
 			db,
 			requestFailures,
 			requestDuration,
 			cfg.Target,
-		))
+		)))
 	}
 
 	if cfg.Collector.Billing {
 		logger.Debug("Billing collector registered")
 
-		registry.MustRegister(exporter.NewBillingCollector(
+		registry.MustRegister(sched.Register("billing", exporter.NewBillingCollector(
 			logger,
 			client,
 			db,
 			requestFailures,
 			requestDuration,
 			cfg.Target,
-		))
+		)))
 	}
 
 	if cfg.Collector.Runners {
 		logger.Debug("Runner collector registered")
 
-		registry.MustRegister(exporter.NewRunnerCollector(
+		registry.MustRegister(sched.Register("runner", exporter.NewRunnerCollector(
 			logger,
 			client,
 			db,
 			requestFailures,
 			requestDuration,
 			cfg.Target,
-		))
+		)))
 	}
 
 	if cfg.Collector.WorkflowRuns {
 		logger.Debug("WorkflowRun collector registered")
 
-		registry.MustRegister(exporter.NewWorkflowRunCollector(
+		registry.MustRegister(sched.Register("workflow_run", exporter.NewWorkflowRunCollector(
 			logger,
 			client,
 			db,
 			requestFailures,
 			requestDuration,
 			cfg.Target,
-		))
+		)))
 	}
 
 	if cfg.Collector.WorkflowJobs {
 		logger.Debug("WorkflowJob collector registered")
 
-		registry.MustRegister(exporter.NewWorkflowJobCollector(
+		registry.MustRegister(sched.Register("workflow_job", exporter.NewWorkflowJobCollector(
 			logger,
 			client,
 			db,
 			requestFailures,
 			requestDuration,
 			cfg.Target,
-		))
+		)))
+	}
+
+	if cfg.Collector.WorkflowCosts {
+		logger.Debug("WorkflowCost collector registered")
+
+		registry.MustRegister(sched.Register("workflow_cost", exporter.NewWorkflowCostCollector(
+			logger,
+			client,
+			db,
+			requestFailures,
+			requestDuration,
+			cfg.Target,
+		)))
+	}
+
+	if cfg.Collector.WorkflowRollups {
+		logger.Debug("WorkflowRollup collector registered")
+
+		registry.MustRegister(sched.Register("workflow_rollup", exporter.NewWorkflowRollupCollector(
+			logger,
+			client,
+			db,
+			requestFailures,
+			requestDuration,
+			cfg.Target,
+		)))
+	}
+
+	if cfg.Collector.Deployments {
+		logger.Debug("Deployment collector registered")
+
+		registry.MustRegister(sched.Register("deployment", exporter.NewDeploymentCollector(
+			logger,
+			client,
+			db,
+			requestFailures,
+			requestDuration,
+			cfg.Target,
+		)))
+	}
+
+	if cfg.Collector.RateLimit {
+		logger.Debug("RateLimit collector registered")
+
+		registry.MustRegister(sched.Register("rate_limit", exporter.NewRateLimitCollector(
+			logger,
+			client,
+			db,
+			requestFailures,
+			requestDuration,
+			cfg.Target,
+			credentials,
+		)))
+	}
+
+	if cfg.Collector.Store {
+		logger.Debug("Store collector registered")
+
+		registry.MustRegister(sched.Register("store", exporter.NewStoreCollector(
+			logger,
+			client,
+			db,
+			requestFailures,
+			requestDuration,
+			cfg.Target,
+		)))
 	}
 
 	reg := promhttp.HandlerFor(
@@ -214,7 +361,11 @@ func handler(cfg *config.Config, db store.Store, logger *slog.Logger, client *gi
 	mux.Route("/", func(root chi.Router) {
 		root.Handle(cfg.Server.Path, reg)
 
-		if cfg.Collector.WorkflowRuns || cfg.Collector.WorkflowJobs {
+		if cfg.Server.API {
+			root.Mount("/api/v1", api(db, logger))
+		}
+
+		if cfg.Collector.WorkflowRuns || cfg.Collector.WorkflowJobs || cfg.Collector.WorkflowCosts || cfg.Collector.WorkflowRollups || cfg.Collector.Deployments {
 			root.HandleFunc(cfg.Webhook.Path, func(w http.ResponseWriter, r *http.Request) {
 				secret, err := config.Value(cfg.Webhook.Secret)
 
@@ -301,7 +452,7 @@ func handler(cfg *config.Config, db store.Store, logger *slog.Logger, client *gi
 					}
 				case *github.WorkflowJobEvent:
 					wfJob := event.GetWorkflowJob()
-					logger.Debug("received webhook request",
+					logger.Debug("Received webhook request",
 						"type", "workflow_job",
 						"owner", event.GetRepo().GetOwner().GetLogin(),
 						"repo", event.GetRepo().GetName(),
@@ -316,9 +467,21 @@ func handler(cfg *config.Config, db store.Store, logger *slog.Logger, client *gi
 						"labels", strings.Join(wfJob.Labels, ", "),
 					)
 
-					if err := db.StoreWorkflowJobEvent(event); err != nil {
+					if err := db.StoreWorkflowJobEvent(event); errors.Is(err, store.ErrOutdated) {
+						logger.Warn(
+							"Ignored outdated github event",
+							"type", "workflow_job",
+							"owner", event.GetRepo().GetOwner().GetLogin(),
+							"repo", event.GetRepo().GetName(),
+							"name", wfJob.GetName(),
+							"id", wfJob.GetID(),
+							"error", err,
+						)
+
+						webhookRegressions.WithLabelValues("workflow_job").Inc()
+					} else if err != nil {
 						logger.Error(
-							"failed to store github event",
+							"Failed to store github event",
 							"type", "workflow_job",
 							"owner", event.GetRepo().GetOwner().GetLogin(),
 							"repo", event.GetRepo().GetName(),
@@ -330,6 +493,57 @@ func handler(cfg *config.Config, db store.Store, logger *slog.Logger, client *gi
 						w.Header().Set("Content-Type", "text/plain")
 						w.WriteHeader(http.StatusInternalServerError)
 
+						_, _ = io.WriteString(w, http.StatusText(http.StatusInternalServerError))
+						return
+					}
+				case *github.DeploymentProtectionRuleEvent:
+					logger.Debug("Received webhook request",
+						"type", "deployment_protection_rule",
+						"owner", event.GetRepo().GetOwner().GetLogin(),
+						"repo", event.GetRepo().GetName(),
+						"action", event.GetAction(),
+						"environment", event.GetEnvironment(),
+						"event", event.GetEvent(),
+					)
+
+					if err := db.StoreDeploymentProtectionRuleEvent(event); err != nil {
+						logger.Error("Failed to store github event",
+							"type", "deployment_protection_rule",
+							"owner", event.GetRepo().GetOwner().GetLogin(),
+							"repo", event.GetRepo().GetName(),
+							"environment", event.GetEnvironment(),
+							"error", err,
+						)
+
+						w.Header().Set("Content-Type", "text/plain")
+						w.WriteHeader(http.StatusInternalServerError)
+
+						_, _ = io.WriteString(w, http.StatusText(http.StatusInternalServerError))
+						return
+					}
+				case *github.DeploymentReviewEvent:
+					logger.Debug("Received webhook request",
+						"type", "deployment_review",
+						"owner", event.GetRepo().GetOwner().GetLogin(),
+						"repo", event.GetRepo().GetName(),
+						"action", event.GetAction(),
+						"environment", event.GetEnvironment(),
+						"run", event.GetWorkflowRun().GetID(),
+						"approver", event.GetApprover().GetLogin(),
+					)
+
+					if err := db.StoreDeploymentReviewEvent(event); err != nil {
+						logger.Error("Failed to store github event",
+							"type", "deployment_review",
+							"owner", event.GetRepo().GetOwner().GetLogin(),
+							"repo", event.GetRepo().GetName(),
+							"run", event.GetWorkflowRun().GetID(),
+							"error", err,
+						)
+
+						w.Header().Set("Content-Type", "text/plain")
+						w.WriteHeader(http.StatusInternalServerError)
+
 						_, _ = io.WriteString(w, http.StatusText(http.StatusInternalServerError))
 						return
 					}
@@ -365,128 +579,256 @@ func useEnterprise(cfg *config.Config, _ *slog.Logger) bool {
 }
 
 func useApplication(cfg *config.Config, _ *slog.Logger) bool {
-	return cfg.Target.PrivateKey != "" && cfg.Target.AppID != 0 && cfg.Target.InstallID != 0
+	return cfg.Target.PrivateKey != "" && cfg.Target.AppID != 0 && (cfg.Target.InstallID != 0 || cfg.Target.AppDiscovery)
 }
 
-func githubTransport(cfg *config.Config) *http.Transport {
-	transport := http.DefaultTransport.(*http.Transport).Clone()
-	transport.TLSClientConfig = &tls.Config{
-		InsecureSkipVerify: cfg.Target.Insecure,
+func githubTransport(cfg *config.Config, logger *slog.Logger) (http.RoundTripper, error) {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+
+	tlsConfig, err := githubTLS(cfg, logger)
+
+	if err != nil {
+		return nil, err
 	}
 
-	return transport
-}
+	base.TLSClientConfig = tlsConfig
 
-func getClient(cfg *config.Config, logger *slog.Logger) (*github.Client, error) {
-	if useEnterprise(cfg, logger) {
-		return getEnterprise(cfg, logger)
+	if cfg.Target.Proxy != "" {
+		proxy, err := githubProxy(cfg, logger)
+
+		if err != nil {
+			return nil, err
+		}
+
+		base.Proxy = http.ProxyURL(proxy)
 	}
 
-	opts := make([]github.ClientOptionsFunc, 0)
+	if cfg.Collector.RateLimit {
+		return transport.RateLimit(base, observeRate), nil
+	}
+
+	return base, nil
+}
 
-	if useApplication(cfg, logger) {
-		privateKey, err := config.Value(cfg.Target.PrivateKey)
+// githubTLS builds the TLS configuration for GitHub connections, optionally
+// trusting a custom CA bundle and presenting a client certificate.
+func githubTLS(cfg *config.Config, logger *slog.Logger) (*tls.Config, error) {
+	result := &tls.Config{
+		InsecureSkipVerify: cfg.Target.Insecure,
+	}
+
+	if cfg.Target.CACert != "" {
+		bundle, err := config.Value(cfg.Target.CACert)
 
 		if err != nil {
-			logger.Error("Failed to read GitHub key",
+			logger.Error("Failed to read GitHub CA bundle",
 				"err", err,
 			)
 
 			return nil, err
 		}
 
-		transport, err := ghinstallation.New(
-			githubTransport(cfg),
-			cfg.Target.AppID,
-			cfg.Target.InstallID,
-			[]byte(privateKey),
-		)
+		pool, err := x509.SystemCertPool()
+
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		if !pool.AppendCertsFromPEM([]byte(bundle)) {
+			logger.Error("Failed to parse GitHub CA bundle")
+			return nil, errors.New("no valid certificates within CA bundle")
+		}
+
+		result.RootCAs = pool
+	}
+
+	if cfg.Target.ClientCert != "" || cfg.Target.ClientKey != "" {
+		if cfg.Target.ClientCert == "" || cfg.Target.ClientKey == "" {
+			logger.Error("GitHub client certificate requires a certificate and a key")
+			return nil, errors.New("client certificate and key have to be defined together")
+		}
+
+		cert, err := config.Value(cfg.Target.ClientCert)
 
 		if err != nil {
-			logger.Error("Failed to create GitHub transport",
+			logger.Error("Failed to read GitHub client certificate",
 				"err", err,
 			)
 
 			return nil, err
 		}
 
-		opts = append(opts, github.WithTransport(
-			transport,
-		))
-	} else {
-		accessToken, err := config.Value(cfg.Target.Token)
+		key, err := config.Value(cfg.Target.ClientKey)
 
 		if err != nil {
-			logger.Error("Failed to read GitHub token",
+			logger.Error("Failed to read GitHub client key",
 				"err", err,
 			)
 
 			return nil, err
 		}
 
-		if accessToken != "" {
-			opts = append(opts, github.WithTransport(
-				githubTransport(cfg),
-			), github.WithAuthToken(
-				accessToken,
-			))
+		pair, err := tls.X509KeyPair([]byte(cert), []byte(key))
+
+		if err != nil {
+			logger.Error("Failed to parse GitHub client certificate",
+				"err", err,
+			)
+
+			return nil, err
 		}
+
+		result.Certificates = []tls.Certificate{pair}
 	}
 
-	client, err := github.NewClient(
-		opts...,
-	)
+	return result, nil
+}
+
+// githubProxy parses the proxy URL, credentials are sent to the proxy as
+// basic authentication.
+func githubProxy(cfg *config.Config, logger *slog.Logger) (*url.URL, error) {
+	value, err := config.Value(cfg.Target.Proxy)
 
 	if err != nil {
-		logger.Error("Failed to create GitHub client",
+		logger.Error("Failed to read GitHub proxy",
+			"err", err,
+		)
+
+		return nil, err
+	}
+
+	proxy, err := url.Parse(strings.TrimSpace(value))
+
+	if err != nil {
+		logger.Error("Failed to parse GitHub proxy",
 			"err", err,
 		)
 
 		return nil, err
 	}
 
-	return client, err
+	switch proxy.Scheme {
+	case "http", "https", "socks5":
+	default:
+		logger.Error("Unsupported GitHub proxy scheme",
+			"scheme", proxy.Scheme,
+		)
+
+		return nil, fmt.Errorf("unsupported proxy scheme %q", proxy.Scheme)
+	}
+
+	logger.Info("Using proxy for GitHub requests",
+		"proxy", proxy.Redacted(),
+	)
+
+	return proxy, nil
 }
 
-func getEnterprise(cfg *config.Config, logger *slog.Logger) (*github.Client, error) {
-	opts := make([]github.ClientOptionsFunc, 0)
+// worker defines a background loop of the GitHub transport, it gets added to
+// the run group of the server.
+type worker struct {
+	name string
+	run  func(ctx context.Context) error
+}
 
-	if useApplication(cfg, logger) {
-		privateKey, err := config.Value(cfg.Target.PrivateKey)
+// githubClientTransport builds the transport for all configured credentials,
+// multiple credentials get combined to a pool. Without any credentials the
+// client uses unauthenticated requests. The names of the credentials and the
+// background workers of the transport get returned as well.
+func githubClientTransport(cfg *config.Config, db store.Store, logger *slog.Logger) (http.RoundTripper, []string, []worker, error) {
+	base, err := githubTransport(cfg, logger)
 
-		if err != nil {
-			logger.Error("Failed to read GitHub key",
-				"err", err,
-			)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 
-			return nil, err
+	credentials, workers, err := githubCredentials(cfg, base, logger)
+
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var result http.RoundTripper
+	names := []string{"anonymous"}
+
+	if len(credentials) > 0 {
+		names = make([]string, 0, len(credentials))
+
+		for _, credential := range credentials {
+			names = append(names, credential.Name)
 		}
+	}
 
-		transport, err := ghinstallation.New(
-			githubTransport(cfg),
-			cfg.Target.AppID,
-			cfg.Target.InstallID,
-			[]byte(privateKey),
+	switch len(credentials) {
+	case 0:
+		result = base
+	case 1:
+		result = credentials[0].Transport
+	default:
+		logger.Info("Using credential pool",
+			"credentials", len(credentials),
 		)
 
-		if err != nil {
-			logger.Error("Failed to create GitHub transport",
-				"err", err,
+		result = transport.Pool(credentials, observeCredential)
+	}
+
+	if cfg.Target.Retries > 0 {
+		result = transport.Retry(result, cfg.Target.Retries, cfg.Target.Backoff, observeRetry)
+	}
+
+	if cfg.Cache.Enabled {
+		cache := transport.NewMemoryCache(cfg.Cache.Size)
+
+		if cfg.Cache.Persist {
+			persisted := transport.NewStoreCache(
+				cache,
+				db,
+				cfg.Cache.PurgeWindow,
+				cfg.Cache.QueueSize,
+				observeCacheDrop,
+				logger,
 			)
 
-			return nil, err
+			cache = persisted
+			workers = append(workers, worker{
+				name: "cache",
+				run:  persisted.Run,
+			})
+		}
+
+		result = transport.Caching(result, cache, observeCache)
+	}
+
+	return result, names, workers, nil
+}
+
+// githubCredentials builds an authenticated transport for the token, the
+// GitHub app and all additional tokens and apps. The discovery of the app
+// installations gets returned as background worker.
+func githubCredentials(cfg *config.Config, base http.RoundTripper, logger *slog.Logger) ([]*transport.Credential, []worker, error) {
+	result := make([]*transport.Credential, 0)
+	workers := make([]worker, 0)
+
+	if useApplication(cfg, logger) && cfg.Target.AppDiscovery {
+		credential, installations, err := githubInstallations(cfg, base, logger)
+
+		if err != nil {
+			return nil, nil, err
 		}
 
-		if !strings.HasSuffix(cfg.Target.BaseURL, "/api/v3") &&
-			!strings.HasSuffix(cfg.Target.BaseURL, "/api/v3/") {
-			transport.BaseURL = cfg.Target.BaseURL + "/api/v3"
-		} else {
-			transport.BaseURL = cfg.Target.BaseURL
+		result = append(result, credential)
+		workers = append(workers, worker{
+			name: "installations",
+			run:  installations.Run,
+		})
+	} else if useApplication(cfg, logger) {
+		credential, err := githubApp(cfg, base, cfg.Target.AppID, cfg.Target.InstallID, cfg.Target.PrivateKey, logger)
+
+		if err != nil {
+			return nil, nil, err
 		}
 
-		opts = append(opts, github.WithTransport(
-			transport,
-		))
+		result = append(result, credential)
 	} else {
 		accessToken, err := config.Value(cfg.Target.Token)
 
@@ -495,18 +837,231 @@ func getEnterprise(cfg *config.Config, logger *slog.Logger) (*github.Client, err
 				"err", err,
 			)
 
-			return nil, err
+			return nil, nil, err
 		}
 
 		if accessToken != "" {
-			opts = append(opts, github.WithTransport(
-				githubTransport(cfg),
-			), github.WithAuthToken(
-				accessToken,
-			))
+			result = append(result, &transport.Credential{
+				Name:      "token-0",
+				Transport: transport.Token(base, accessToken),
+			})
+		}
+	}
+
+	for i, val := range cfg.Target.Tokens {
+		accessToken, err := config.Value(val)
+
+		if err != nil {
+			logger.Error("Failed to read GitHub token",
+				"err", err,
+			)
+
+			return nil, nil, err
+		}
+
+		result = append(result, &transport.Credential{
+			Name:      fmt.Sprintf("token-%d", i+1),
+			Transport: transport.Token(base, accessToken),
+		})
+	}
+
+	for _, val := range cfg.Target.Apps {
+		parts := strings.SplitN(val, ":", 3)
+
+		if len(parts) != 3 {
+			return nil, nil, fmt.Errorf("invalid GitHub app %q, expected app_id:installation_id:private_key", val)
+		}
+
+		appID, err := strconv.ParseInt(parts[0], 10, 64)
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid GitHub app id: %w", err)
 		}
+
+		installID, err := strconv.ParseInt(parts[1], 10, 64)
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid GitHub installation id: %w", err)
+		}
+
+		credential, err := githubApp(cfg, base, appID, installID, parts[2], logger)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		result = append(result, credential)
 	}
 
+	return result, workers, nil
+}
+
+// githubInstallations builds the transport routing requests to all
+// discovered installations of the GitHub app. The installations have to run
+// within the run group, otherwise they never get refreshed.
+func githubInstallations(cfg *config.Config, base http.RoundTripper, logger *slog.Logger) (*transport.Credential, *transport.Installations, error) {
+	privateKey, err := config.Value(cfg.Target.PrivateKey)
+
+	if err != nil {
+		logger.Error("Failed to read GitHub key",
+			"err", err,
+		)
+
+		return nil, nil, err
+	}
+
+	apps, err := ghinstallation.NewAppsTransport(
+		base,
+		cfg.Target.AppID,
+		[]byte(privateKey),
+	)
+
+	if err != nil {
+		logger.Error("Failed to create GitHub app transport",
+			"err", err,
+		)
+
+		return nil, nil, err
+	}
+
+	opts := []github.ClientOptionsFunc{
+		github.WithTransport(apps),
+	}
+
+	if useEnterprise(cfg, logger) {
+		apps.BaseURL = enterpriseAPI(cfg)
+
+		opts = append(opts, github.WithEnterpriseURLs(
+			cfg.Target.BaseURL,
+			cfg.Target.BaseURL,
+		))
+	}
+
+	client, err := github.NewClient(
+		opts...,
+	)
+
+	if err != nil {
+		logger.Error("Failed to create GitHub app client",
+			"err", err,
+		)
+
+		return nil, nil, err
+	}
+
+	installations := transport.NewInstallations(
+		apps,
+		client,
+		cfg.Target.InstallID,
+		cfg.Target.AppRefresh,
+		logger,
+		observeInstallations,
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Target.Timeout)
+	defer cancel()
+
+	installations.Refresh(ctx, true)
+
+	return &transport.Credential{
+		Name:      fmt.Sprintf("app-%d", cfg.Target.AppID),
+		Transport: installations,
+	}, installations, nil
+}
+
+// githubApp builds the transport for a GitHub app installation.
+func githubApp(cfg *config.Config, base http.RoundTripper, appID, installID int64, key string, logger *slog.Logger) (*transport.Credential, error) {
+	privateKey, err := config.Value(key)
+
+	if err != nil {
+		logger.Error("Failed to read GitHub key",
+			"err", err,
+		)
+
+		return nil, err
+	}
+
+	installation, err := ghinstallation.New(
+		base,
+		appID,
+		installID,
+		[]byte(privateKey),
+	)
+
+	if err != nil {
+		logger.Error("Failed to create GitHub transport",
+			"err", err,
+		)
+
+		return nil, err
+	}
+
+	if useEnterprise(cfg, logger) {
+		installation.BaseURL = enterpriseAPI(cfg)
+	}
+
+	return &transport.Credential{
+		Name:      fmt.Sprintf("app-%d-%d", appID, installID),
+		Transport: installation,
+	}, nil
+}
+
+// enterpriseAPI returns the API endpoint of GitHub Enterprise used by the
+// app transports.
+func enterpriseAPI(cfg *config.Config) string {
+	if !strings.HasSuffix(cfg.Target.BaseURL, "/api/v3") &&
+		!strings.HasSuffix(cfg.Target.BaseURL, "/api/v3/") {
+		return cfg.Target.BaseURL + "/api/v3"
+	}
+
+	return cfg.Target.BaseURL
+}
+
+func getClient(cfg *config.Config, db store.Store, logger *slog.Logger) (*github.Client, []string, []worker, error) {
+	if useEnterprise(cfg, logger) {
+		return getEnterprise(cfg, db, logger)
+	}
+
+	opts := make([]github.ClientOptionsFunc, 0)
+
+	transport, credentials, workers, err := githubClientTransport(cfg, db, logger)
+
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	opts = append(opts, github.WithTransport(
+		transport,
+	))
+
+	client, err := github.NewClient(
+		opts...,
+	)
+
+	if err != nil {
+		logger.Error("Failed to create GitHub client",
+			"err", err,
+		)
+
+		return nil, nil, nil, err
+	}
+
+	return client, credentials, workers, err
+}
+
+func getEnterprise(cfg *config.Config, db store.Store, logger *slog.Logger) (*github.Client, []string, []worker, error) {
+	opts := make([]github.ClientOptionsFunc, 0)
+
+	transport, credentials, workers, err := githubClientTransport(cfg, db, logger)
+
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	opts = append(opts, github.WithTransport(
+		transport,
+	))
+
 	opts = append(opts, github.WithEnterpriseURLs(
 		cfg.Target.BaseURL,
 		cfg.Target.BaseURL,
@@ -521,8 +1076,8 @@ func getEnterprise(cfg *config.Config, logger *slog.Logger) (*github.Client, err
 			"err", err,
 		)
 
-		return nil, err
+		return nil, nil, nil, err
 	}
 
-	return client, err
+	return client, credentials, workers, err
 }
@@ -0,0 +1,173 @@
+package action
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/promhippie/github_exporter/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGithubTransportCACert(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	defer server.Close()
+
+	cfg := config.Load()
+
+	untrusted, err := githubTransport(cfg, slog.Default())
+	require.NoError(t, err)
+
+	_, err = (&http.Client{Transport: untrusted}).Get(server.URL)
+	assert.Error(t, err)
+
+	cfg.Target.CACert = string(pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: server.Certificate().Raw,
+	}))
+
+	trusted, err := githubTransport(cfg, slog.Default())
+	require.NoError(t, err)
+
+	resp, err := (&http.Client{Transport: trusted}).Get(server.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestGithubTransportClientCert(t *testing.T) {
+	cert, key, parsed := testCertificate(t)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(parsed)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "exporter", r.TLS.PeerCertificates[0].Subject.CommonName)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  pool,
+	}
+
+	server.StartTLS()
+	defer server.Close()
+
+	cfg := config.Load()
+	cfg.Target.CACert = "base64://" + base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: server.Certificate().Raw,
+	}))
+
+	anonymous, err := githubTransport(cfg, slog.Default())
+	require.NoError(t, err)
+
+	_, err = (&http.Client{Transport: anonymous}).Get(server.URL)
+	assert.Error(t, err)
+
+	cfg.Target.ClientCert = string(cert)
+	cfg.Target.ClientKey = string(key)
+
+	authenticated, err := githubTransport(cfg, slog.Default())
+	require.NoError(t, err)
+
+	resp, err := (&http.Client{Transport: authenticated}).Get(server.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestGithubTransportProxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "github.example.com", r.URL.Host)
+		assert.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte("user:secret")), r.Header.Get("Proxy-Authorization"))
+
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	defer proxy.Close()
+
+	cfg := config.Load()
+	cfg.Target.Proxy = "http://user:secret@" + proxy.Listener.Addr().String()
+
+	tr, err := githubTransport(cfg, slog.Default())
+	require.NoError(t, err)
+
+	resp, err := (&http.Client{Transport: tr}).Get("http://github.example.com/api/v3/meta")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestGithubTransportInvalid(t *testing.T) {
+	cert, _, _ := testCertificate(t)
+
+	tests := []struct {
+		name   string
+		modify func(*config.Config)
+	}{
+		{"invalid bundle", func(cfg *config.Config) { cfg.Target.CACert = "invalid" }},
+		{"missing key", func(cfg *config.Config) { cfg.Target.ClientCert = string(cert) }},
+		{"invalid pair", func(cfg *config.Config) { cfg.Target.ClientCert, cfg.Target.ClientKey = string(cert), "invalid" }},
+		{"invalid proxy scheme", func(cfg *config.Config) { cfg.Target.Proxy = "ftp://proxy.example.com" }},
+		{"missing proxy file", func(cfg *config.Config) { cfg.Target.Proxy = "file:///nonexistent/proxy" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Load()
+			tt.modify(cfg)
+
+			_, err := githubTransport(cfg, slog.Default())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func testCertificate(t *testing.T) ([]byte, []byte, *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "exporter"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	parsed, err := x509.ParseCertificate(raw)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: raw}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}),
+		parsed
+}
@@ -2,12 +2,14 @@ package command
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"time"
 
 	"github.com/cenkalti/backoff/v7"
 	"github.com/promhippie/github_exporter/pkg/action"
 	"github.com/promhippie/github_exporter/pkg/config"
+	"github.com/promhippie/github_exporter/pkg/exporter"
 	"github.com/promhippie/github_exporter/pkg/store"
 	"github.com/promhippie/github_exporter/pkg/version"
 	"github.com/urfave/cli/v3"
@@ -39,6 +41,9 @@ func Run() error {
 		Flags: RootFlags(cfg),
 		Commands: []*cli.Command{
 			Health(cfg),
+			Store(cfg),
+			Export(cfg),
+			Migrate(cfg),
 		},
 		Action: func(ctx context.Context, _ *cli.Command) error {
 			logger := setupLogger(cfg)
@@ -92,10 +97,30 @@ func Run() error {
 				return err
 			}
 
-			if err := db.Migrate(); err != nil {
-				logger.Error("Failed to migrate database",
-					"error", err,
-				)
+			if cfg.Database.AutoMigrate {
+				if err := db.Migrate(); err != nil {
+					logger.Error("Failed to migrate database",
+						"error", err,
+					)
+				}
+			} else {
+				migrations, err := db.Migrations()
+
+				if err != nil {
+					logger.Error("Failed to fetch migrations",
+						"error", err,
+					)
+
+					return err
+				}
+
+				if pending := pendingMigrations(migrations); len(pending) > 0 {
+					logger.Error("Database has pending migrations and auto migration is disabled, apply them with migrate up",
+						"pending", len(pending),
+					)
+
+					return fmt.Errorf("database has %d pending migrations", len(pending))
+				}
 			}
 
 			if cfg.Target.WorkflowRuns.PurgeWindow < cfg.Target.WorkflowRuns.Window {
@@ -104,6 +129,92 @@ func Run() error {
 			if cfg.Target.WorkflowJobs.PurgeWindow < cfg.Target.WorkflowJobs.Window {
 				logger.Warn("Workflow Run purge window cannot be smaller than query window or data loss will occur", "config", cfg.Target.WorkflowJobs)
 			}
+			if cfg.Target.Deployments.PurgeWindow < cfg.Target.Deployments.Window {
+				logger.Warn("Deployment purge window cannot be smaller than query window or data loss will occur", "config", cfg.Target.Deployments)
+			}
+
+			runRules, err := store.ParseRetentionRules(cfg.Target.WorkflowRuns.Retention)
+
+			if err != nil {
+				logger.Error("Invalid workflow run retention rules",
+					"error", err,
+				)
+
+				return err
+			}
+
+			if _, err := store.ParseRetentionRules(cfg.Target.WorkflowJobs.Retention); err != nil {
+				logger.Error("Invalid workflow job retention rules",
+					"error", err,
+				)
+
+				return err
+			}
+
+			if _, err := exporter.ParseSnapshotIntervals(cfg.Scheduler.Intervals); err != nil {
+				logger.Error("Invalid scheduler intervals",
+					"error", err,
+				)
+
+				return err
+			}
+
+			if cfg.Scheduler.Enabled && cfg.Scheduler.Interval <= 0 {
+				logger.Warn("Scheduler interval must be positive, falling back to default", "config", cfg.Scheduler)
+				cfg.Scheduler.Interval = time.Minute
+			}
+
+			if cfg.Target.RepoBackend != exporter.RepoBackendREST && cfg.Target.RepoBackend != exporter.RepoBackendGraphQL {
+				logger.Error("Invalid repo backend",
+					"backend", cfg.Target.RepoBackend,
+				)
+
+				return fmt.Errorf("invalid repo backend %q", cfg.Target.RepoBackend)
+			}
+
+			if cfg.Target.Concurrency < 1 {
+				logger.Warn("Request concurrency must be positive, falling back to default", "concurrency", cfg.Target.Concurrency)
+				cfg.Target.Concurrency = 1
+			}
+
+			if !cfg.Scheduler.Enabled {
+				cfg.Target.ScrapeTimeout = cfg.Server.Timeout
+			}
+
+			if cfg.Collector.WorkflowRollups {
+				shortest := store.Retention{
+					Window: cfg.Target.WorkflowRuns.PurgeWindow,
+					Rules:  runRules,
+				}.Shortest()
+
+				if cfg.Target.WorkflowRollups.Interval <= 0 || cfg.Target.WorkflowRollups.Interval >= cfg.Target.WorkflowRollups.Lookback {
+					logger.Error("Workflow rollup interval has to be positive and smaller than the lookback or rollups will miss runs",
+						"interval", cfg.Target.WorkflowRollups.Interval,
+						"lookback", cfg.Target.WorkflowRollups.Lookback,
+					)
+
+					return fmt.Errorf("invalid workflow rollup interval %s", cfg.Target.WorkflowRollups.Interval)
+				}
+
+				if cfg.Target.WorkflowRollups.Lookback+time.Hour > shortest {
+					logger.Error("Workflow rollup lookback plus an hour has to be smaller than the shortest workflow run retention or aggregated hours get overwritten",
+						"lookback", cfg.Target.WorkflowRollups.Lookback,
+						"retention", shortest,
+					)
+
+					return fmt.Errorf("workflow rollup lookback %s exceeds shortest workflow run retention %s", cfg.Target.WorkflowRollups.Lookback, shortest)
+				}
+			}
+
+			if cfg.Pruner.BatchSize < 1 {
+				logger.Warn("Pruner batch size must be positive, falling back to default", "config", cfg.Pruner)
+				cfg.Pruner.BatchSize = 1000
+			}
+
+			if cfg.Leader.Election && cfg.Leader.TTL < 3*time.Second {
+				logger.Warn("Leader lease TTL must be at least 3 seconds, falling back to default", "config", cfg.Leader)
+				cfg.Leader.TTL = 30 * time.Second
+			}
 
 			return action.Server(cfg, db, logger)
 		},
@@ -162,6 +273,13 @@ func RootFlags(cfg *config.Config) []cli.Flag {
 			Sources:     cli.EnvVars("GITHUB_EXPORTER_WEB_PPROF"),
 			Destination: &cfg.Server.Pprof,
 		},
+		&cli.BoolFlag{
+			Name:        "web.api",
+			Value:       false,
+			Usage:       "Enable the read-only JSON API for stored workflow runs and jobs",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_WEB_API"),
+			Destination: &cfg.Server.API,
+		},
 		&cli.DurationFlag{
 			Name:        "web.timeout",
 			Value:       10 * time.Second,
@@ -197,6 +315,111 @@ func RootFlags(cfg *config.Config) []cli.Flag {
 			Sources:     cli.EnvVars("GITHUB_EXPORTER_DATABASE_DSN"),
 			Destination: &cfg.Database.DSN,
 		},
+		&cli.BoolFlag{
+			Name:        "database.auto_migrate",
+			Value:       true,
+			Usage:       "Apply pending database migrations on startup, otherwise refuse to start with pending migrations",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_DATABASE_AUTO_MIGRATE"),
+			Destination: &cfg.Database.AutoMigrate,
+		},
+		&cli.BoolFlag{
+			Name:        "cache.enabled",
+			Value:       false,
+			Usage:       "Cache API responses and revalidate them with conditional requests",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_CACHE_ENABLED"),
+			Destination: &cfg.Cache.Enabled,
+		},
+		&cli.IntFlag{
+			Name:        "cache.size",
+			Value:       1000,
+			Usage:       "Maximum number of API responses cached in memory",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_CACHE_SIZE"),
+			Destination: &cfg.Cache.Size,
+		},
+		&cli.BoolFlag{
+			Name:        "cache.persist",
+			Value:       false,
+			Usage:       "Persist cached API responses within the database",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_CACHE_PERSIST"),
+			Destination: &cfg.Cache.Persist,
+		},
+		&cli.DurationFlag{
+			Name:        "cache.purge_window",
+			Value:       24 * time.Hour,
+			Usage:       "History window for keeping persisted API responses in the database, older responses get expired by the cache",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_CACHE_PURGE_WINDOW"),
+			Destination: &cfg.Cache.PurgeWindow,
+		},
+		&cli.IntFlag{
+			Name:        "cache.queue_size",
+			Value:       100,
+			Usage:       "Maximum number of API responses queued for persisting, further responses only get cached in memory",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_CACHE_QUEUE_SIZE"),
+			Destination: &cfg.Cache.QueueSize,
+		},
+		&cli.BoolFlag{
+			Name:        "scheduler.enabled",
+			Value:       false,
+			Usage:       "Refresh collectors in the background and serve scrapes from snapshots",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_SCHEDULER_ENABLED"),
+			Destination: &cfg.Scheduler.Enabled,
+		},
+		&cli.DurationFlag{
+			Name:        "scheduler.interval",
+			Value:       time.Minute,
+			Usage:       "Default interval to refresh the snapshots of collectors",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_SCHEDULER_INTERVAL"),
+			Destination: &cfg.Scheduler.Interval,
+		},
+		&cli.StringSliceFlag{
+			Name:        "scheduler.intervals",
+			Value:       []string{},
+			Usage:       "Refresh intervals per collector defined as collector=duration, e.g. billing=1h",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_SCHEDULER_INTERVALS"),
+			Destination: &cfg.Scheduler.Intervals,
+		},
+		&cli.DurationFlag{
+			Name:        "scheduler.staleness",
+			Value:       10 * time.Minute,
+			Usage:       "Maximum age of the last successful refresh before a snapshot is not served anymore, 0 disables the limit",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_SCHEDULER_STALENESS"),
+			Destination: &cfg.Scheduler.Staleness,
+		},
+		&cli.BoolFlag{
+			Name:        "leader.election",
+			Value:       false,
+			Usage:       "Elect a leader via the database, singleton work like pruning only runs on the leader",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_LEADER_ELECTION"),
+			Destination: &cfg.Leader.Election,
+		},
+		&cli.DurationFlag{
+			Name:        "leader.ttl",
+			Value:       30 * time.Second,
+			Usage:       "Duration until the lease of a leader expires without renewal",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_LEADER_TTL"),
+			Destination: &cfg.Leader.TTL,
+		},
+		&cli.StringFlag{
+			Name:        "leader.identity",
+			Value:       "",
+			Usage:       "Identity of the replica for the leader election, defaults to hostname and process ID",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_LEADER_IDENTITY"),
+			Destination: &cfg.Leader.Identity,
+		},
+		&cli.DurationFlag{
+			Name:        "pruner.interval",
+			Value:       5 * time.Minute,
+			Usage:       "Interval to prune outdated records from the database, 0 disables pruning",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_PRUNER_INTERVAL"),
+			Destination: &cfg.Pruner.Interval,
+		},
+		&cli.IntFlag{
+			Name:        "pruner.batch_size",
+			Value:       1000,
+			Usage:       "Maximum number of records deleted by a single statement while pruning",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_PRUNER_BATCH_SIZE"),
+			Destination: &cfg.Pruner.BatchSize,
+		},
 		&cli.DurationFlag{
 			Name:        "request.timeout",
 			Value:       5 * time.Second,
@@ -204,6 +427,27 @@ func RootFlags(cfg *config.Config) []cli.Flag {
 			Sources:     cli.EnvVars("GITHUB_EXPORTER_REQUEST_TIMEOUT"),
 			Destination: &cfg.Target.Timeout,
 		},
+		&cli.IntFlag{
+			Name:        "request.retries",
+			Value:       3,
+			Usage:       "Retries for server errors and rate limited requests, 0 disables retries",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_REQUEST_RETRIES"),
+			Destination: &cfg.Target.Retries,
+		},
+		&cli.DurationFlag{
+			Name:        "request.backoff",
+			Value:       time.Second,
+			Usage:       "Initial backoff between retries, doubled with every attempt",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_REQUEST_BACKOFF"),
+			Destination: &cfg.Target.Backoff,
+		},
+		&cli.IntFlag{
+			Name:        "request.concurrency",
+			Value:       1,
+			Usage:       "Maximum number of orgs, repos or runners fetched in parallel by a collector",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_REQUEST_CONCURRENCY"),
+			Destination: &cfg.Target.Concurrency,
+		},
 		&cli.StringFlag{
 			Name:        "github.token",
 			Value:       "",
@@ -223,6 +467,20 @@ func RootFlags(cfg *config.Config) []cli.Flag {
 			Sources:     cli.EnvVars("GITHUB_EXPORTER_INSTALLATION_ID"),
 			Destination: &cfg.Target.InstallID,
 		},
+		&cli.BoolFlag{
+			Name:        "github.app_discovery",
+			Value:       false,
+			Usage:       "Discover all installations of the GitHub app and route requests by owner",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_APP_DISCOVERY"),
+			Destination: &cfg.Target.AppDiscovery,
+		},
+		&cli.DurationFlag{
+			Name:        "github.app_refresh",
+			Value:       time.Hour,
+			Usage:       "Interval to refresh the discovered installations of the GitHub app",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_APP_REFRESH"),
+			Destination: &cfg.Target.AppRefresh,
+		},
 		&cli.StringFlag{
 			Name:        "github.private_key",
 			Value:       "",
@@ -230,6 +488,20 @@ func RootFlags(cfg *config.Config) []cli.Flag {
 			Sources:     cli.EnvVars("GITHUB_EXPORTER_PRIVATE_KEY"),
 			Destination: &cfg.Target.PrivateKey,
 		},
+		&cli.StringSliceFlag{
+			Name:        "github.tokens",
+			Value:       []string{},
+			Usage:       "Additional access tokens pooled with the token, also supports file:// and base64://",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_TOKENS"),
+			Destination: &cfg.Target.Tokens,
+		},
+		&cli.StringSliceFlag{
+			Name:        "github.apps",
+			Value:       []string{},
+			Usage:       "Additional GitHub apps pooled with the credentials as app_id:installation_id:private_key",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_APPS"),
+			Destination: &cfg.Target.Apps,
+		},
 		&cli.StringFlag{
 			Name:        "github.baseurl",
 			Value:       "",
@@ -244,6 +516,34 @@ func RootFlags(cfg *config.Config) []cli.Flag {
 			Sources:     cli.EnvVars("GITHUB_EXPORTER_INSECURE"),
 			Destination: &cfg.Target.Insecure,
 		},
+		&cli.StringFlag{
+			Name:        "github.ca_cert",
+			Value:       "",
+			Usage:       "CA bundle to verify the GitHub certificate, also supports file:// and base64://",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_CA_CERT"),
+			Destination: &cfg.Target.CACert,
+		},
+		&cli.StringFlag{
+			Name:        "github.client_cert",
+			Value:       "",
+			Usage:       "Client certificate for mutual TLS with GitHub, also supports file:// and base64://",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_CLIENT_CERT"),
+			Destination: &cfg.Target.ClientCert,
+		},
+		&cli.StringFlag{
+			Name:        "github.client_key",
+			Value:       "",
+			Usage:       "Client key for mutual TLS with GitHub, also supports file:// and base64://",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_CLIENT_KEY"),
+			Destination: &cfg.Target.ClientKey,
+		},
+		&cli.StringFlag{
+			Name:        "github.proxy",
+			Value:       "",
+			Usage:       "Proxy URL for GitHub requests including optional credentials, also supports file:// and base64://",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_PROXY"),
+			Destination: &cfg.Target.Proxy,
+		},
 		&cli.StringSliceFlag{
 			Name:        "github.enterprise",
 			Value:       []string{},
@@ -293,6 +593,13 @@ func RootFlags(cfg *config.Config) []cli.Flag {
 			Sources:     cli.EnvVars("GITHUB_EXPORTER_COLLECTOR_REPOS"),
 			Destination: &cfg.Collector.Repos,
 		},
+		&cli.StringFlag{
+			Name:        "collector.repos.backend",
+			Value:       exporter.RepoBackendREST,
+			Usage:       "Backend used by the repo collector, rest or graphql",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_REPOS_BACKEND"),
+			Destination: &cfg.Target.RepoBackend,
+		},
 		&cli.BoolFlag{
 			Name:        "collector.billing",
 			Value:       false,
@@ -321,6 +628,13 @@ func RootFlags(cfg *config.Config) []cli.Flag {
 			Sources:     cli.EnvVars("GITHUB_EXPORTER_WORKFLOW_RUNS_PURGE_WINDOW"),
 			Destination: &cfg.Target.WorkflowRuns.PurgeWindow,
 		},
+		&cli.StringSliceFlag{
+			Name:        "collector.workflow_runs.retention",
+			Value:       []string{},
+			Usage:       "Purge windows per owner or owner/repo glob as pattern=duration, the first matching rule wins",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_WORKFLOW_RUNS_RETENTION"),
+			Destination: &cfg.Target.WorkflowRuns.Retention,
+		},
 		&cli.StringSliceFlag{
 			Name:        "collector.workflow_runs.labels",
 			Value:       config.RunLabels(),
@@ -328,6 +642,13 @@ func RootFlags(cfg *config.Config) []cli.Flag {
 			Sources:     cli.EnvVars("GITHUB_EXPORTER_WORKFLOW_RUNS_LABELS"),
 			Destination: &cfg.Target.WorkflowRuns.Labels,
 		},
+		&cli.IntFlag{
+			Name:        "collector.workflow_runs.max_rows",
+			Value:       0,
+			Usage:       "Maximum number of workflows exported per scrape, newest first, 0 disables the limit",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_WORKFLOW_RUNS_MAX_ROWS"),
+			Destination: &cfg.Target.WorkflowRuns.MaxRows,
+		},
 		&cli.BoolFlag{
 			Name:        "collector.workflow_jobs",
 			Value:       false,
@@ -349,6 +670,13 @@ func RootFlags(cfg *config.Config) []cli.Flag {
 			Sources:     cli.EnvVars("GITHUB_EXPORTER_WORKFLOW_JOBS_PURGE_WINDOW"),
 			Destination: &cfg.Target.WorkflowJobs.PurgeWindow,
 		},
+		&cli.StringSliceFlag{
+			Name:        "collector.workflow_jobs.retention",
+			Value:       []string{},
+			Usage:       "Purge windows per owner or owner/repo glob as pattern=duration, the first matching rule wins",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_WORKFLOW_JOBS_RETENTION"),
+			Destination: &cfg.Target.WorkflowJobs.Retention,
+		},
 		&cli.StringSliceFlag{
 			Name:        "collector.workflow_jobs.labels",
 			Value:       config.JobLabels(),
@@ -356,6 +684,97 @@ func RootFlags(cfg *config.Config) []cli.Flag {
 			Sources:     cli.EnvVars("GITHUB_EXPORTER_WORKFLOW_JOBS_LABELS"),
 			Destination: &cfg.Target.WorkflowJobs.Labels,
 		},
+		&cli.IntFlag{
+			Name:        "collector.workflow_jobs.max_rows",
+			Value:       0,
+			Usage:       "Maximum number of workflow jobs exported per scrape, newest first, 0 disables the limit",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_WORKFLOW_JOBS_MAX_ROWS"),
+			Destination: &cfg.Target.WorkflowJobs.MaxRows,
+		},
+		&cli.BoolFlag{
+			Name:        "collector.workflow_costs",
+			Value:       false,
+			Usage:       "Enable collector for workflow costs",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_COLLECTOR_WORKFLOW_COSTS"),
+			Destination: &cfg.Collector.WorkflowCosts,
+		},
+		&cli.StringSliceFlag{
+			Name:        "collector.workflow_costs.rates",
+			Value:       config.CostRates(),
+			Usage:       "List of SKU=price rates per minute used for cost estimation",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_WORKFLOW_COSTS_RATES"),
+			Destination: &cfg.Target.WorkflowCosts.Rates,
+		},
+		&cli.StringSliceFlag{
+			Name:        "collector.workflow_costs.teams",
+			Value:       []string{},
+			Usage:       "List of owner/repo=team mappings used for cost attribution, supports globs, other repositories are attributed to the unassigned team",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_WORKFLOW_COSTS_TEAMS"),
+			Destination: &cfg.Target.WorkflowCosts.Teams,
+		},
+		&cli.IntFlag{
+			Name:        "collector.workflow_costs.max_lookups",
+			Value:       100,
+			Usage:       "Maximum number of workflow run usage lookups per scrape, further runs get looked up on later scrapes, 0 disables the limit",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_WORKFLOW_COSTS_MAX_LOOKUPS"),
+			Destination: &cfg.Target.WorkflowCosts.MaxLookups,
+		},
+		&cli.DurationFlag{
+			Name:        "collector.workflow_costs.failure_ttl",
+			Value:       15 * time.Minute,
+			Usage:       "Duration until a failed workflow run usage lookup gets retried",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_WORKFLOW_COSTS_FAILURE_TTL"),
+			Destination: &cfg.Target.WorkflowCosts.FailureTTL,
+		},
+		&cli.BoolFlag{
+			Name:        "collector.workflow_rollups",
+			Value:       false,
+			Usage:       "Enable collector for long-window workflow run statistics based on hourly rollups",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_COLLECTOR_WORKFLOW_ROLLUPS"),
+			Destination: &cfg.Collector.WorkflowRollups,
+		},
+		&cli.DurationFlag{
+			Name:        "collector.workflow_rollups.interval",
+			Value:       15 * time.Minute,
+			Usage:       "Interval to aggregate workflow runs into rollups, has to be smaller than the lookback",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_WORKFLOW_ROLLUPS_INTERVAL"),
+			Destination: &cfg.Target.WorkflowRollups.Interval,
+		},
+		&cli.DurationFlag{
+			Name:        "collector.workflow_rollups.lookback",
+			Value:       3 * time.Hour,
+			Usage:       "Timeframe of workflow runs aggregated again on every rollup, has to be smaller than the workflow run purge window",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_WORKFLOW_ROLLUPS_LOOKBACK"),
+			Destination: &cfg.Target.WorkflowRollups.Lookback,
+		},
+		&cli.DurationFlag{
+			Name:        "collector.workflow_rollups.purge_window",
+			Value:       30 * 24 * time.Hour,
+			Usage:       "History window for keeping rollups in database",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_WORKFLOW_ROLLUPS_PURGE_WINDOW"),
+			Destination: &cfg.Target.WorkflowRollups.PurgeWindow,
+		},
+		&cli.BoolFlag{
+			Name:        "collector.deployments",
+			Value:       false,
+			Usage:       "Enable collector for deployment reviews",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_COLLECTOR_DEPLOYMENTS"),
+			Destination: &cfg.Collector.Deployments,
+		},
+		&cli.DurationFlag{
+			Name:        "collector.deployments.window",
+			Value:       24 * time.Hour,
+			Usage:       "History window for querying deployment reviews",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_DEPLOYMENTS_WINDOW"),
+			Destination: &cfg.Target.Deployments.Window,
+		},
+		&cli.DurationFlag{
+			Name:        "collector.deployments.purge_window",
+			Value:       24 * time.Hour,
+			Usage:       "History window for keeping data in database. Defaults to the query window",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_DEPLOYMENTS_PURGE_WINDOW"),
+			Destination: &cfg.Target.Deployments.PurgeWindow,
+		},
 		&cli.BoolFlag{
 			Name:        "collector.runners",
 			Value:       false,
@@ -370,5 +789,26 @@ func RootFlags(cfg *config.Config) []cli.Flag {
 			Sources:     cli.EnvVars("GITHUB_EXPORTER_RUNNERS_LABELS"),
 			Destination: &cfg.Target.Runners.Labels,
 		},
+		&cli.BoolFlag{
+			Name:        "collector.store",
+			Value:       false,
+			Usage:       "Enable collector for database store health",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_COLLECTOR_STORE"),
+			Destination: &cfg.Collector.Store,
+		},
+		&cli.DurationFlag{
+			Name:        "collector.store.stats_interval",
+			Value:       15 * time.Minute,
+			Usage:       "Interval to refresh the table stats, counting the records scans the tables",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_STORE_STATS_INTERVAL"),
+			Destination: &cfg.Target.StoreStats.Interval,
+		},
+		&cli.BoolFlag{
+			Name:        "collector.rate_limit",
+			Value:       false,
+			Usage:       "Enable collector for API rate limits",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_COLLECTOR_RATE_LIMIT"),
+			Destination: &cfg.Collector.RateLimit,
+		},
 	}
 }
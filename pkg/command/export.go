@@ -0,0 +1,379 @@
+package command
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+
+	"github.com/promhippie/github_exporter/pkg/config"
+	"github.com/promhippie/github_exporter/pkg/store"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	// exportPageSize defines the number of records fetched per query, that way
+	// the export never keeps the whole time range in memory.
+	exportPageSize = 1000
+)
+
+// exportWorkflowRun extends the workflow run by the computed durations.
+type exportWorkflowRun struct {
+	*store.WorkflowRun
+
+	DurationSeconds int64 `json:"duration_seconds"`
+}
+
+// exportWorkflowJob extends the workflow job by the computed durations.
+type exportWorkflowJob struct {
+	*store.WorkflowJob
+
+	QueuedSeconds   int64 `json:"queued_seconds"`
+	DurationSeconds int64 `json:"duration_seconds"`
+}
+
+// Export provides the sub-command to export stored records.
+func Export(cfg *config.Config) *cli.Command {
+	return &cli.Command{
+		Name:  "export",
+		Usage: "Export stored workflow runs or jobs as CSV or JSON Lines",
+		Flags: ExportFlags(cfg),
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			logger := setupLogger(cfg)
+
+			filter := &store.Filter{
+				Owner:    cmd.String("owner"),
+				Repo:     cmd.String("repo"),
+				Workflow: cmd.String("workflow"),
+				Branch:   cmd.String("branch"),
+				Status:   cmd.String("status"),
+			}
+
+			if val := cmd.String("since"); val != "" {
+				since, err := store.ParseTimestamp(val)
+
+				if err != nil {
+					return fmt.Errorf("invalid since: %w", err)
+				}
+
+				filter.Since = since
+			}
+
+			if val := cmd.String("until"); val != "" {
+				until, err := store.ParseTimestamp(val)
+
+				if err != nil {
+					return fmt.Errorf("invalid until: %w", err)
+				}
+
+				filter.Until = until
+			}
+
+			write := newExportCSV
+
+			switch cmd.String("format") {
+			case "csv":
+			case "jsonl":
+				write = newExportJSONL
+			default:
+				return fmt.Errorf("unknown format %s, available formats are csv, jsonl", cmd.String("format"))
+			}
+
+			switch cmd.String("type") {
+			case "runs", "jobs":
+			default:
+				return fmt.Errorf("unknown type %s, available types are runs, jobs", cmd.String("type"))
+			}
+
+			db, err := openStore(cfg.Database.DSN, logger)
+
+			if err != nil {
+				logger.Error("Failed to open database",
+					"err", err,
+				)
+
+				return err
+			}
+
+			defer func() { _ = db.Close() }()
+
+			output := cmd.String("output")
+			f := os.Stdout
+
+			if output != "-" {
+				f, err = os.Create(output)
+
+				if err != nil {
+					logger.Error("Failed to create output file",
+						"output", output,
+						"err", err,
+					)
+
+					return err
+				}
+
+				defer func() { _ = f.Close() }()
+			}
+
+			w := write(f)
+			count := 0
+
+			switch cmd.String("type") {
+			case "runs":
+				err = exportPages(filter, db.QueryWorkflowRuns, func(record *store.WorkflowRun) error {
+					count++
+					return w.Write(exportWorkflowRunFrom(record))
+				})
+			case "jobs":
+				err = exportPages(filter, db.QueryWorkflowJobs, func(record *store.WorkflowJob) error {
+					count++
+					return w.Write(exportWorkflowJobFrom(record))
+				})
+			}
+
+			if err == nil {
+				err = w.Flush()
+			}
+
+			if err != nil {
+				logger.Error("Failed to export records",
+					"type", cmd.String("type"),
+					"err", err,
+				)
+
+				return err
+			}
+
+			logger.Info("Exported records",
+				"type", cmd.String("type"),
+				"count", count,
+				"output", output,
+			)
+
+			if output == "-" {
+				return nil
+			}
+
+			return f.Close()
+		},
+	}
+}
+
+// ExportFlags defines the available export flags.
+func ExportFlags(cfg *config.Config) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "database.dsn",
+			Value:       defaultDatabaseDSN,
+			Usage:       "DSN for the database connection",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_DATABASE_DSN"),
+			Destination: &cfg.Database.DSN,
+		},
+		&cli.StringFlag{
+			Name:  "type",
+			Value: "runs",
+			Usage: "Type of records to export, runs or jobs",
+		},
+		&cli.StringFlag{
+			Name:  "format",
+			Value: "csv",
+			Usage: "Format of the export, csv or jsonl",
+		},
+		&cli.StringFlag{
+			Name:  "output",
+			Value: "-",
+			Usage: "Path to the output file, defaults to stdout",
+		},
+		&cli.StringFlag{
+			Name:  "since",
+			Usage: "Export records since the date, RFC3339, YYYY-MM-DD or unix seconds",
+		},
+		&cli.StringFlag{
+			Name:  "until",
+			Usage: "Export records before the date, RFC3339, YYYY-MM-DD or unix seconds",
+		},
+		&cli.StringFlag{
+			Name:  "owner",
+			Usage: "Only export records of the owner",
+		},
+		&cli.StringFlag{
+			Name:  "repo",
+			Usage: "Only export records of the repository",
+		},
+		&cli.StringFlag{
+			Name:  "workflow",
+			Usage: "Only export records of the workflow",
+		},
+		&cli.StringFlag{
+			Name:  "branch",
+			Usage: "Only export records of the branch",
+		},
+		&cli.StringFlag{
+			Name:  "status",
+			Usage: "Only export records with the status or conclusion",
+		},
+	}
+}
+
+// exportWorkflowRunFrom computes the duration of the workflow run.
+func exportWorkflowRunFrom(record *store.WorkflowRun) *exportWorkflowRun {
+	result := &exportWorkflowRun{
+		WorkflowRun: record,
+	}
+
+	if record.StartedAt > 0 && record.UpdatedAt > record.StartedAt {
+		result.DurationSeconds = record.UpdatedAt - record.StartedAt
+	}
+
+	return result
+}
+
+// exportWorkflowJobFrom computes the queue time and the duration of the
+// workflow job.
+func exportWorkflowJobFrom(record *store.WorkflowJob) *exportWorkflowJob {
+	result := &exportWorkflowJob{
+		WorkflowJob: record,
+	}
+
+	if record.CreatedAt > 0 && record.StartedAt > record.CreatedAt {
+		result.QueuedSeconds = record.StartedAt - record.CreatedAt
+	}
+
+	if record.StartedAt > 0 && record.CompletedAt > record.StartedAt {
+		result.DurationSeconds = record.CompletedAt - record.StartedAt
+	}
+
+	return result
+}
+
+// exportPages queries the records page by page and passes every record to
+// the callback, the filter is used to track the current page.
+func exportPages[T any](filter *store.Filter, query func(*store.Filter) ([]*T, error), fn func(*T) error) error {
+	filter.Limit = exportPageSize
+
+	for filter.Offset = 0; ; filter.Offset += exportPageSize {
+		records, err := query(filter)
+
+		if err != nil {
+			return fmt.Errorf("failed to query records: %w", err)
+		}
+
+		for _, record := range records {
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+
+		if len(records) < exportPageSize {
+			return nil
+		}
+	}
+}
+
+// exportWriter writes the records one by one to the output.
+type exportWriter interface {
+	Write(record any) error
+	Flush() error
+}
+
+// exportJSONL writes every record as a single JSON line.
+type exportJSONL struct {
+	encoder *json.Encoder
+}
+
+// newExportJSONL prepares the JSON Lines writer for the output.
+func newExportJSONL(w io.Writer) exportWriter {
+	return &exportJSONL{
+		encoder: json.NewEncoder(w),
+	}
+}
+
+// Write implements the exportWriter interface.
+func (e *exportJSONL) Write(record any) error {
+	if err := e.encoder.Encode(record); err != nil {
+		return fmt.Errorf("failed to encode record: %w", err)
+	}
+
+	return nil
+}
+
+// Flush implements the exportWriter interface.
+func (e *exportJSONL) Flush() error {
+	return nil
+}
+
+// exportCSV writes the records as CSV, the header is based on the JSON tags
+// of the first record.
+type exportCSV struct {
+	writer *csv.Writer
+	header bool
+}
+
+// newExportCSV prepares the CSV writer for the output.
+func newExportCSV(w io.Writer) exportWriter {
+	return &exportCSV{
+		writer: csv.NewWriter(w),
+	}
+}
+
+// Write implements the exportWriter interface.
+func (e *exportCSV) Write(record any) error {
+	header, values := exportColumns(reflect.ValueOf(record))
+
+	if !e.header {
+		if err := e.writer.Write(header); err != nil {
+			return fmt.Errorf("failed to write header: %w", err)
+		}
+
+		e.header = true
+	}
+
+	if err := e.writer.Write(values); err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+
+	return nil
+}
+
+// Flush implements the exportWriter interface.
+func (e *exportCSV) Flush() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// exportColumns resolves the JSON tags and the values of the struct fields,
+// embedded structs get flattened like the JSON encoder does.
+func exportColumns(value reflect.Value) ([]string, []string) {
+	header := make([]string, 0)
+	values := make([]string, 0)
+
+	for value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+
+		if field.Anonymous {
+			h, v := exportColumns(value.Field(i))
+			header = append(header, h...)
+			values = append(values, v...)
+
+			continue
+		}
+
+		name := field.Tag.Get("json")
+
+		if name == "" || name == "-" {
+			continue
+		}
+
+		header = append(header, name)
+		values = append(values, fmt.Sprint(value.Field(i).Interface()))
+	}
+
+	return header, values
+}
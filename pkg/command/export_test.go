@@ -0,0 +1,75 @@
+package command
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/promhippie/github_exporter/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportCSV(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := newExportCSV(buf)
+
+	require.NoError(t, w.Write(exportWorkflowJobFrom(&store.WorkflowJob{
+		Owner:       "promhippie",
+		Repo:        "github_exporter",
+		Name:        "build",
+		Identifier:  1,
+		CreatedAt:   100,
+		StartedAt:   130,
+		CompletedAt: 190,
+	})))
+
+	require.NoError(t, w.Flush())
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	assert.True(t, bytes.HasPrefix(lines[0], []byte("owner,repo,name,")))
+	assert.True(t, bytes.HasSuffix(lines[0], []byte(",queued_seconds,duration_seconds")))
+	assert.True(t, bytes.HasSuffix(lines[1], []byte(",30,60")))
+}
+
+func TestExportJSONL(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := newExportJSONL(buf)
+
+	require.NoError(t, w.Write(exportWorkflowRunFrom(&store.WorkflowRun{Owner: "promhippie", Number: 1, StartedAt: 100, UpdatedAt: 160})))
+	require.NoError(t, w.Write(exportWorkflowRunFrom(&store.WorkflowRun{Owner: "promhippie", Number: 2})))
+	require.NoError(t, w.Flush())
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	assert.Contains(t, string(lines[0]), `"owner":"promhippie"`)
+	assert.Contains(t, string(lines[0]), `"duration_seconds":60`)
+	assert.Contains(t, string(lines[1]), `"duration_seconds":0`)
+}
+
+func TestExportPages(t *testing.T) {
+	records := make([]*store.WorkflowRun, exportPageSize+1)
+
+	for i := range records {
+		records[i] = &store.WorkflowRun{Number: i}
+	}
+
+	queries := 0
+	exported := make([]int, 0, len(records))
+
+	require.NoError(t, exportPages(&store.Filter{}, func(filter *store.Filter) ([]*store.WorkflowRun, error) {
+		queries++
+		assert.Equal(t, exportPageSize, filter.Limit)
+
+		return records[filter.Offset:min(filter.Offset+filter.Limit, len(records))], nil
+	}, func(record *store.WorkflowRun) error {
+		exported = append(exported, record.Number)
+		return nil
+	}))
+
+	assert.Equal(t, 2, queries)
+	assert.Len(t, exported, len(records))
+	assert.Equal(t, exportPageSize, exported[len(exported)-1])
+}
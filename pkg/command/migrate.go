@@ -0,0 +1,197 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/promhippie/github_exporter/pkg/config"
+	"github.com/promhippie/github_exporter/pkg/store"
+	"github.com/urfave/cli/v3"
+)
+
+// Migrate provides the sub-command to manage the database migrations.
+func Migrate(cfg *config.Config) *cli.Command {
+	return &cli.Command{
+		Name:  "migrate",
+		Usage: "Manage the database migrations",
+		Commands: []*cli.Command{
+			MigrateStatus(cfg),
+			MigrateUp(cfg),
+		},
+	}
+}
+
+// MigrateStatus provides the sub-command to show the migration status.
+func MigrateStatus(cfg *config.Config) *cli.Command {
+	return &cli.Command{
+		Name:  "status",
+		Usage: "Show applied and pending database migrations",
+		Flags: MigrateFlags(cfg),
+		Action: func(_ context.Context, _ *cli.Command) error {
+			logger := setupLogger(cfg)
+
+			db, err := openStore(cfg.Database.DSN, logger)
+
+			if err != nil {
+				logger.Error("Failed to open database",
+					"err", err,
+				)
+
+				return err
+			}
+
+			defer func() { _ = db.Close() }()
+
+			migrations, err := db.Migrations()
+
+			if err != nil {
+				logger.Error("Failed to fetch migrations",
+					"err", err,
+				)
+
+				return err
+			}
+
+			return writeMigrationStatus(os.Stdout, migrations)
+		},
+	}
+}
+
+// MigrateUp provides the sub-command to apply pending migrations.
+func MigrateUp(cfg *config.Config) *cli.Command {
+	return &cli.Command{
+		Name:  "up",
+		Usage: "Apply all pending database migrations",
+		Flags: append(
+			MigrateFlags(cfg),
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Value: false,
+				Usage: "Print the SQL of pending migrations without applying them",
+			},
+		),
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			logger := setupLogger(cfg)
+
+			db, err := openStore(cfg.Database.DSN, logger)
+
+			if err != nil {
+				logger.Error("Failed to open database",
+					"err", err,
+				)
+
+				return err
+			}
+
+			defer func() { _ = db.Close() }()
+
+			migrations, err := db.Migrations()
+
+			if err != nil {
+				logger.Error("Failed to fetch migrations",
+					"err", err,
+				)
+
+				return err
+			}
+
+			pending := pendingMigrations(migrations)
+
+			if cmd.Bool("dry-run") {
+				return writeMigrationScripts(os.Stdout, pending)
+			}
+
+			if len(pending) == 0 {
+				logger.Info("Database is up to date")
+				return nil
+			}
+
+			if err := db.Migrate(); err != nil {
+				logger.Error("Failed to migrate database",
+					"err", err,
+				)
+
+				return err
+			}
+
+			logger.Info("Applied database migrations",
+				"count", len(pending),
+			)
+
+			return nil
+		},
+	}
+}
+
+// MigrateFlags defines the available migrate flags.
+func MigrateFlags(cfg *config.Config) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "database.dsn",
+			Value:       defaultDatabaseDSN,
+			Usage:       "DSN for the database connection",
+			Sources:     cli.EnvVars("GITHUB_EXPORTER_DATABASE_DSN"),
+			Destination: &cfg.Database.DSN,
+		},
+	}
+}
+
+// pendingMigrations filters the migrations which have not been applied.
+func pendingMigrations(migrations []*store.Migration) []*store.Migration {
+	result := make([]*store.Migration, 0)
+
+	for _, migration := range migrations {
+		if migration.Status == store.MigrationPending {
+			result = append(result, migration)
+		}
+	}
+
+	return result
+}
+
+// writeMigrationStatus writes the status of all migrations as a table.
+func writeMigrationStatus(w io.Writer, migrations []*store.Migration) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(writer, "VERSION\tSTATUS\tAPPLIED\tDESCRIPTION")
+
+	for _, migration := range migrations {
+		applied := "-"
+
+		if migration.AppliedAt > 0 {
+			applied = time.Unix(migration.AppliedAt, 0).UTC().Format(time.RFC3339)
+		}
+
+		_, _ = fmt.Fprintf(
+			writer,
+			"%v\t%s\t%s\t%s\n",
+			migration.Version,
+			migration.Status,
+			applied,
+			migration.Description,
+		)
+	}
+
+	return writer.Flush()
+}
+
+// writeMigrationScripts writes the SQL of the migrations.
+func writeMigrationScripts(w io.Writer, migrations []*store.Migration) error {
+	for _, migration := range migrations {
+		if _, err := fmt.Fprintf(
+			w,
+			"-- %v: %s\n%s\n\n",
+			migration.Version,
+			migration.Description,
+			migration.Script,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package command
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/promhippie/github_exporter/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateScripts(t *testing.T) {
+	migrations := []*store.Migration{
+		{Version: 1, Description: "Creating table workflow_runs", Script: "CREATE TABLE workflow_runs ();", Status: store.MigrationApplied, AppliedAt: 1704067200},
+		{Version: 2, Description: "Adding actor column", Script: "ALTER TABLE workflow_runs ADD COLUMN actor TEXT;", Status: store.MigrationPending},
+	}
+
+	pending := pendingMigrations(migrations)
+	require.Len(t, pending, 1)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeMigrationScripts(buf, pending))
+	assert.Equal(t, "-- 2: Adding actor column\nALTER TABLE workflow_runs ADD COLUMN actor TEXT;\n\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, writeMigrationStatus(buf, migrations))
+	assert.Contains(t, buf.String(), "2024-01-01T00:00:00Z")
+	assert.Contains(t, buf.String(), "pending")
+}
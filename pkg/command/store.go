@@ -0,0 +1,247 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/promhippie/github_exporter/pkg/config"
+	"github.com/promhippie/github_exporter/pkg/store"
+	"github.com/urfave/cli/v3"
+)
+
+// Store provides the sub-command to manage the database store.
+func Store(cfg *config.Config) *cli.Command {
+	return &cli.Command{
+		Name:  "store",
+		Usage: "Manage the database store",
+		Commands: []*cli.Command{
+			StoreCopy(cfg),
+		},
+	}
+}
+
+// StoreCopy provides the sub-command to copy records between stores.
+func StoreCopy(cfg *config.Config) *cli.Command {
+	return &cli.Command{
+		Name:  "copy",
+		Usage: "Copy all records between database drivers",
+		Flags: StoreCopyFlags(cfg),
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			logger := setupLogger(cfg)
+
+			source, err := openStore(cmd.String("from"), logger)
+
+			if err != nil {
+				logger.Error("Failed to open source database",
+					"err", err,
+				)
+
+				return err
+			}
+
+			defer func() { _ = source.Close() }()
+
+			target, err := openStore(cmd.String("to"), logger)
+
+			if err != nil {
+				logger.Error("Failed to open target database",
+					"err", err,
+				)
+
+				return err
+			}
+
+			defer func() { _ = target.Close() }()
+
+			if err := target.Migrate(); err != nil {
+				logger.Error("Failed to migrate target database",
+					"err", err,
+				)
+
+				return err
+			}
+
+			batch := int(cmd.Int("batch-size"))
+
+			if batch < 1 {
+				return fmt.Errorf("batch size must be positive")
+			}
+
+			if err := copyRecords(
+				logger.With("type", "workflow_runs"),
+				batch,
+				source.CountWorkflowRuns,
+				source.ListWorkflowRuns,
+				target.ImportWorkflowRuns,
+				target.CountWorkflowRuns,
+			); err != nil {
+				return err
+			}
+
+			if err := copyRecords(
+				logger.With("type", "workflow_jobs"),
+				batch,
+				source.CountWorkflowJobs,
+				source.ListWorkflowJobs,
+				target.ImportWorkflowJobs,
+				target.CountWorkflowJobs,
+			); err != nil {
+				return err
+			}
+
+			if err := copyRecords(
+				logger.With("type", "workflow_run_rollups"),
+				batch,
+				source.CountWorkflowRunRollups,
+				source.ListWorkflowRunRollups,
+				target.ImportWorkflowRunRollups,
+				target.CountWorkflowRunRollups,
+			); err != nil {
+				return err
+			}
+
+			return copyRecords(
+				logger.With("type", "deployment_reviews"),
+				batch,
+				source.CountDeploymentReviews,
+				source.ListDeploymentReviews,
+				target.ImportDeploymentReviews,
+				target.CountDeploymentReviews,
+			)
+		},
+	}
+}
+
+// StoreCopyFlags defines the available store copy flags.
+func StoreCopyFlags(_ *config.Config) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "from",
+			Usage:    "DSN of the source database, also supports file:// and base64://",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "to",
+			Usage:    "DSN of the target database, also supports file:// and base64://",
+			Required: true,
+		},
+		&cli.IntFlag{
+			Name:  "batch-size",
+			Value: 1000,
+			Usage: "Number of records copied within a single batch",
+		},
+	}
+}
+
+// openStore initializes and opens the store for the DSN.
+func openStore(value string, logger *slog.Logger) (store.Store, error) {
+	dsn, err := config.Value(value)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dsn: %w", err)
+	}
+
+	db, err := store.New(dsn, logger)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Open(); err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// copyRecords streams all records in batches and verifies the record count
+// of the target afterwards.
+func copyRecords[T any](
+	logger *slog.Logger,
+	batch int,
+	count func() (int64, error),
+	list func(int, int) ([]T, error),
+	write func([]T) error,
+	verify func() (int64, error),
+) error {
+	total, err := count()
+
+	if err != nil {
+		logger.Error("Failed to count source records",
+			"err", err,
+		)
+
+		return err
+	}
+
+	copied := 0
+
+	for offset := 0; ; offset += batch {
+		records, err := list(offset, batch)
+
+		if err != nil {
+			logger.Error("Failed to list source records",
+				"offset", offset,
+				"err", err,
+			)
+
+			return err
+		}
+
+		if len(records) == 0 {
+			break
+		}
+
+		if err := write(records); err != nil {
+			logger.Error("Failed to write target records",
+				"offset", offset,
+				"err", err,
+			)
+
+			return err
+		}
+
+		copied += len(records)
+
+		logger.Info("Copied records",
+			"copied", copied,
+			"total", total,
+		)
+
+		if len(records) < batch {
+			break
+		}
+	}
+
+	result, err := verify()
+
+	if err != nil {
+		logger.Error("Failed to count target records",
+			"err", err,
+		)
+
+		return err
+	}
+
+	if result < total {
+		logger.Error("Target contains less records than source",
+			"source", total,
+			"target", result,
+		)
+
+		return fmt.Errorf("verification failed, expected at least %d records but got %d", total, result)
+	}
+
+	logger.Info("Verified records",
+		"source", total,
+		"target", result,
+	)
+
+	return nil
+}
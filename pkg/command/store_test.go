@@ -0,0 +1,44 @@
+package command
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/promhippie/github_exporter/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyRecords(t *testing.T) {
+	source, err := store.New("memory://", slog.Default())
+	require.NoError(t, err)
+
+	target, err := store.New("memory://", slog.Default())
+	require.NoError(t, err)
+
+	records := make([]*store.WorkflowJob, 0)
+
+	for i := range 25 {
+		records = append(records, &store.WorkflowJob{
+			Owner:      "promhippie",
+			Repo:       "github_exporter",
+			Identifier: int64(i + 1),
+			Status:     "completed",
+		})
+	}
+
+	require.NoError(t, source.ImportWorkflowJobs(records))
+
+	assert.NoError(t, copyRecords(
+		slog.Default(),
+		10,
+		source.CountWorkflowJobs,
+		source.ListWorkflowJobs,
+		target.ImportWorkflowJobs,
+		target.CountWorkflowJobs,
+	))
+
+	total, err := target.CountWorkflowJobs()
+	require.NoError(t, err)
+	assert.Equal(t, int64(25), total)
+}
@@ -15,6 +15,7 @@ type Server struct {
 	Timeout time.Duration
 	Web     string
 	Pprof   bool
+	API     bool
 }
 
 // Webhook defines the webhook specific configuration.
@@ -33,14 +34,44 @@ type Logs struct {
 type WorkflowRuns struct {
 	Window      time.Duration
 	PurgeWindow time.Duration
+	Retention   []string
 	Labels      []string
+	MaxRows     int
 }
 
 // WorkflowJobs defines the workflow job specific configuration.
 type WorkflowJobs struct {
 	Window      time.Duration
 	PurgeWindow time.Duration
+	Retention   []string
 	Labels      []string
+	MaxRows     int
+}
+
+// WorkflowRollups defines the workflow rollup specific configuration.
+type WorkflowRollups struct {
+	Interval    time.Duration
+	Lookback    time.Duration
+	PurgeWindow time.Duration
+}
+
+// Deployments defines the deployment specific configuration.
+type Deployments struct {
+	Window      time.Duration
+	PurgeWindow time.Duration
+}
+
+// WorkflowCosts defines the workflow cost specific configuration.
+type WorkflowCosts struct {
+	Rates      []string
+	Teams      []string
+	MaxLookups int
+	FailureTTL time.Duration
+}
+
+// StoreStats defines the store collector specific configuration.
+type StoreStats struct {
+	Interval time.Duration
 }
 
 // Runners defines the runner specific configuration.
@@ -50,36 +81,89 @@ type Runners struct {
 
 // Target defines the target specific configuration.
 type Target struct {
-	Token        string
-	PrivateKey   string
-	AppID        int64
-	InstallID    int64
-	BaseURL      string
-	Insecure     bool
-	Enterprises  []string
-	Orgs         []string
-	Repos        []string
-	Timeout      time.Duration
-	PerPage      int
-	WorkflowRuns WorkflowRuns
-	WorkflowJobs WorkflowJobs
-	Runners      Runners
+	Token           string
+	PrivateKey      string
+	AppID           int64
+	InstallID       int64
+	Tokens          []string
+	Apps            []string
+	AppDiscovery    bool
+	AppRefresh      time.Duration
+	BaseURL         string
+	Insecure        bool
+	CACert          string
+	ClientCert      string
+	ClientKey       string
+	Proxy           string
+	Enterprises     []string
+	Orgs            []string
+	Repos           []string
+	RepoBackend     string
+	Timeout         time.Duration
+	Retries         int
+	Backoff         time.Duration
+	Concurrency     int
+	ScrapeTimeout   time.Duration
+	PerPage         int
+	WorkflowRuns    WorkflowRuns
+	WorkflowJobs    WorkflowJobs
+	WorkflowCosts   WorkflowCosts
+	WorkflowRollups WorkflowRollups
+	Deployments     Deployments
+	Runners         Runners
+	StoreStats      StoreStats
 }
 
 // Collector defines the collector specific configuration.
 type Collector struct {
-	Admin        bool
-	Orgs         bool
-	Repos        bool
-	Billing      bool
-	WorkflowRuns bool
-	WorkflowJobs bool
-	Runners      bool
+	Admin           bool
+	Orgs            bool
+	Repos           bool
+	Billing         bool
+	WorkflowRuns    bool
+	WorkflowJobs    bool
+	WorkflowCosts   bool
+	WorkflowRollups bool
+	Deployments     bool
+	Runners         bool
+	Store           bool
+	RateLimit       bool
 }
 
 // Database defines the database specific configuration.
 type Database struct {
-	DSN string
+	DSN         string
+	AutoMigrate bool
+}
+
+// Pruner defines the pruner specific configuration.
+type Pruner struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+// Leader defines the leader election specific configuration.
+type Leader struct {
+	Election bool
+	TTL      time.Duration
+	Identity string
+}
+
+// Cache defines the response cache specific configuration.
+type Cache struct {
+	Enabled     bool
+	Size        int
+	Persist     bool
+	PurgeWindow time.Duration
+	QueueSize   int
+}
+
+// Scheduler defines the background collection specific configuration.
+type Scheduler struct {
+	Enabled   bool
+	Interval  time.Duration
+	Intervals []string
+	Staleness time.Duration
 }
 
 // Config is a combination of all available configurations.
@@ -90,6 +174,10 @@ type Config struct {
 	Target    Target
 	Collector Collector
 	Database  Database
+	Pruner    Pruner
+	Leader    Leader
+	Cache     Cache
+	Scheduler Scheduler
 }
 
 // Load initializes a default configuration struct.
@@ -134,6 +222,15 @@ func JobLabels() []string {
 	}
 }
 
+// CostRates defines the default rates per minute used by workflow cost collector.
+func CostRates() []string {
+	return []string{
+		"linux=0.008",
+		"windows=0.016",
+		"macos=0.08",
+	}
+}
+
 // RunnerLabels defines the default labels used by runner collector.
 func RunnerLabels() []string {
 	return []string{
@@ -1,7 +1,6 @@
 package exporter
 
 import (
-	"context"
 	"log/slog"
 	"time"
 
@@ -376,7 +375,7 @@ func (c *AdminCollector) Describe(ch chan<- *prometheus.Desc) {
 
 // Collect is called by the Prometheus registry when collecting metrics.
 func (c *AdminCollector) Collect(ch chan<- prometheus.Metric) {
-	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	ctx, cancel := collectorContext("admin", c.config.Timeout)
 	defer cancel()
 
 	now := time.Now()
@@ -210,7 +210,7 @@ type UsageResponse struct {
 
 // getUsage fetches billing usage data from GitHub Enhanced Billing Platform API.
 func (c *BillingCollector) getUsage() []UsageItem {
-	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	ctx, cancel := collectorContext("billing", c.config.Timeout)
 	defer cancel()
 
 	var result []UsageItem
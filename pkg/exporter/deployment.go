@@ -0,0 +1,186 @@
+package exporter
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/promhippie/github_exporter/pkg/config"
+	"github.com/promhippie/github_exporter/pkg/store"
+)
+
+var (
+	// deploymentWaitBuckets defines the buckets of the review wait histogram,
+	// reaching from a minute up to three days.
+	deploymentWaitBuckets = []float64{60, 300, 900, 1800, 3600, 10800, 21600, 43200, 86400, 259200}
+)
+
+// deploymentWait aggregates the wait times of finished reviews.
+type deploymentWait struct {
+	count   uint64
+	sum     float64
+	buckets map[float64]uint64
+}
+
+// DeploymentCollector collects metrics about the deployment reviews.
+type DeploymentCollector struct {
+	client   *github.Client
+	logger   *slog.Logger
+	db       store.Store
+	failures *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	config   config.Target
+
+	WaitTime  *prometheus.Desc
+	Pending   *prometheus.Desc
+	Approvals *prometheus.Desc
+}
+
+// NewDeploymentCollector returns a new DeploymentCollector.
+func NewDeploymentCollector(logger *slog.Logger, client *github.Client, db store.Store, failures *prometheus.CounterVec, duration *prometheus.HistogramVec, cfg config.Target) *DeploymentCollector {
+	if failures != nil {
+		failures.WithLabelValues("deployment").Add(0)
+	}
+
+	return &DeploymentCollector{
+		client:   client,
+		logger:   logger.With("collector", "deployment"),
+		db:       db,
+		failures: failures,
+		duration: duration,
+		config:   cfg,
+
+		WaitTime: prometheus.NewDesc(
+			"github_deployment_review_wait_seconds",
+			"Histogram of durations workflow runs waited for the approval or rejection of an environment within the window",
+			[]string{"owner", "repo", "environment"},
+			nil,
+		),
+		Pending: prometheus.NewDesc(
+			"github_deployment_review_pending",
+			"Number of workflow runs currently waiting for an approval",
+			[]string{"owner", "repo", "environment"},
+			nil,
+		),
+		Approvals: prometheus.NewDesc(
+			"github_deployment_review_approvals",
+			"Number of deployment reviews within the window per approver",
+			[]string{"owner", "repo", "environment", "approver", "status"},
+			nil,
+		),
+	}
+}
+
+// Metrics simply returns the list metric descriptors for generating a documentation.
+func (c *DeploymentCollector) Metrics() []*prometheus.Desc {
+	return []*prometheus.Desc{
+		c.WaitTime,
+		c.Pending,
+		c.Approvals,
+	}
+}
+
+// Describe sends the super-set of all possible descriptors of metrics collected by this Collector.
+func (c *DeploymentCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.WaitTime
+	ch <- c.Pending
+	ch <- c.Approvals
+}
+
+// Collect is called by the Prometheus registry when collecting metrics.
+func (c *DeploymentCollector) Collect(ch chan<- prometheus.Metric) {
+	now := time.Now()
+	records, err := c.db.GetDeploymentReviews(c.config.Deployments.Window)
+	c.duration.WithLabelValues("deployment").Observe(time.Since(now).Seconds())
+
+	if err != nil {
+		c.logger.Error("Failed to fetch deployment reviews",
+			"err", err,
+		)
+
+		c.failures.WithLabelValues("deployment").Inc()
+		return
+	}
+
+	c.logger.Debug("Fetched deployment reviews",
+		"count", len(records),
+		"duration", time.Since(now),
+	)
+
+	pending := make(map[[3]string]float64)
+	approvals := make(map[[5]string]float64)
+	waits := make(map[[3]string]*deploymentWait)
+
+	for _, record := range records {
+		c.logger.Debug("Collecting deployment review",
+			"owner", record.Owner,
+			"repo", record.Repo,
+			"environment", record.Environment,
+			"run_id", record.RunID,
+		)
+
+		key := [3]string{record.Owner, record.Repo, record.Environment}
+
+		if record.Status == "pending" {
+			pending[key]++
+			continue
+		}
+
+		if record.Status != "approved" && record.Status != "rejected" {
+			continue
+		}
+
+		if record.Approver != "" {
+			approvals[[5]string{record.Owner, record.Repo, record.Environment, record.Approver, record.Status}]++
+		}
+
+		wait, ok := waits[key]
+
+		if !ok {
+			wait = &deploymentWait{
+				buckets: make(map[float64]uint64, len(deploymentWaitBuckets)),
+			}
+
+			waits[key] = wait
+		}
+
+		waited := float64(max(record.ReviewedAt-record.RequestedAt, 0))
+		wait.count++
+		wait.sum += waited
+
+		for _, bucket := range deploymentWaitBuckets {
+			if waited <= bucket {
+				wait.buckets[bucket]++
+			}
+		}
+	}
+
+	for key, wait := range waits {
+		ch <- prometheus.MustNewConstHistogram(
+			c.WaitTime,
+			wait.count,
+			wait.sum,
+			wait.buckets,
+			key[:]...,
+		)
+	}
+
+	for key, count := range pending {
+		ch <- prometheus.MustNewConstMetric(
+			c.Pending,
+			prometheus.GaugeValue,
+			count,
+			key[:]...,
+		)
+	}
+
+	for key, count := range approvals {
+		ch <- prometheus.MustNewConstMetric(
+			c.Approvals,
+			prometheus.GaugeValue,
+			count,
+			key[:]...,
+		)
+	}
+}
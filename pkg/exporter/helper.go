@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/go-github/v90/github"
+	"github.com/promhippie/github_exporter/pkg/transport"
 )
 
 func closeBody(resp *github.Response) {
@@ -14,6 +17,59 @@ func closeBody(resp *github.Response) {
 	}
 }
 
+// collectorContext prepares the context for the requests of a collector, it
+// gets tagged with the collector name to attribute the rate limit usage.
+func collectorContext(name string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return requestContext(context.Background(), name, timeout)
+}
+
+// requestContext prepares the context for the requests of a single target,
+// derived from the context of the whole scrape.
+func requestContext(parent context.Context, name string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(transport.WithCollector(parent, name), timeout)
+}
+
+// scrapeContext prepares the context for a whole collection, outstanding
+// work gets canceled once the scrape deadline expires.
+func scrapeContext(deadline time.Duration) (context.Context, context.CancelFunc) {
+	if deadline <= 0 {
+		return context.WithCancel(context.Background())
+	}
+
+	return context.WithTimeout(context.Background(), deadline)
+}
+
+// parallel calls fn for every index of the items with up to limit concurrent
+// workers. Items not started before the context is done get skipped, the
+// number of skipped items gets returned.
+func parallel(ctx context.Context, limit, items int, fn func(int)) int {
+	var wg sync.WaitGroup
+
+	sem := make(chan struct{}, max(limit, 1))
+
+	for idx := range items {
+		if ctx.Err() != nil {
+			wg.Wait()
+			return items - idx
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return items - idx
+		case sem <- struct{}{}:
+		}
+
+		wg.Go(func() {
+			defer func() { <-sem }()
+			fn(idx)
+		})
+	}
+
+	wg.Wait()
+	return 0
+}
+
 func alreadyCollected(collected []string, needle string) bool {
 	for _, val := range collected {
 		if needle == val {
@@ -0,0 +1,51 @@
+package exporter
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParallel(t *testing.T) {
+	var running, peak atomic.Int32
+
+	results := make([]int, 10)
+
+	skipped := parallel(context.Background(), 3, len(results), func(idx int) {
+		current := running.Add(1)
+		defer running.Add(-1)
+
+		for {
+			old := peak.Load()
+
+			if current <= old || peak.CompareAndSwap(old, current) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		results[idx] = idx * 2
+	})
+
+	assert.Equal(t, 0, skipped)
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+	assert.Equal(t, []int{0, 2, 4, 6, 8, 10, 12, 14, 16, 18}, results)
+}
+
+func TestParallelDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var started atomic.Int32
+
+	skipped := parallel(ctx, 2, 10, func(_ int) {
+		started.Add(1)
+		<-ctx.Done()
+	})
+
+	assert.Equal(t, int32(2), started.Load())
+	assert.Equal(t, 8, skipped)
+}
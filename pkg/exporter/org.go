@@ -1,7 +1,6 @@
 package exporter
 
 import (
-	"context"
 	"log/slog"
 	"time"
 
@@ -182,8 +181,17 @@ func (c *OrgCollector) Collect(ch chan<- prometheus.Metric) {
 		}
 
 		collected = append(collected, name)
+	}
+
+	scrape, cancel := scrapeContext(c.config.ScrapeTimeout)
+	defer cancel()
+
+	records := make([]*github.Organization, len(collected))
 
-		ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	if skipped := parallel(scrape, c.config.Concurrency, len(collected), func(idx int) {
+		name := collected[idx]
+
+		ctx, cancel := requestContext(scrape, "org", c.config.Timeout)
 		defer cancel()
 
 		now := time.Now()
@@ -198,9 +206,25 @@ func (c *OrgCollector) Collect(ch chan<- prometheus.Metric) {
 			)
 
 			c.failures.WithLabelValues("org").Inc()
+			return
+		}
+
+		records[idx] = record
+	}); skipped > 0 {
+		c.logger.Error("Scrape deadline exceeded",
+			"skipped", skipped,
+		)
+
+		c.failures.WithLabelValues("org").Inc()
+	}
+
+	for idx, record := range records {
+		if record == nil {
 			continue
 		}
 
+		name := collected[idx]
+
 		c.logger.Debug("Collecting org",
 			"name", name,
 		)
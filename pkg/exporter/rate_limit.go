@@ -0,0 +1,168 @@
+package exporter
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/promhippie/github_exporter/pkg/config"
+	"github.com/promhippie/github_exporter/pkg/store"
+	"github.com/promhippie/github_exporter/pkg/transport"
+)
+
+// RateLimitCollector collects metrics about the API rate limits of all
+// configured credentials.
+type RateLimitCollector struct {
+	client      *github.Client
+	logger      *slog.Logger
+	db          store.Store
+	failures    *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	config      config.Target
+	credentials []string
+
+	Limit     *prometheus.Desc
+	Remaining *prometheus.Desc
+	Used      *prometheus.Desc
+	Reset     *prometheus.Desc
+}
+
+// NewRateLimitCollector returns a new RateLimitCollector, the rate limits get
+// requested with every credential of the client.
+func NewRateLimitCollector(logger *slog.Logger, client *github.Client, db store.Store, failures *prometheus.CounterVec, duration *prometheus.HistogramVec, cfg config.Target, credentials []string) *RateLimitCollector {
+	if failures != nil {
+		failures.WithLabelValues("rate_limit").Add(0)
+	}
+
+	labels := []string{"credential", "resource"}
+	return &RateLimitCollector{
+		client:      client,
+		logger:      logger.With("collector", "rate_limit"),
+		db:          db,
+		failures:    failures,
+		duration:    duration,
+		config:      cfg,
+		credentials: credentials,
+
+		Limit: prometheus.NewDesc(
+			"github_rate_limit_limit",
+			"Maximum number of requests within the rate limit window",
+			labels,
+			nil,
+		),
+		Remaining: prometheus.NewDesc(
+			"github_rate_limit_remaining",
+			"Number of requests remaining within the rate limit window",
+			labels,
+			nil,
+		),
+		Used: prometheus.NewDesc(
+			"github_rate_limit_used",
+			"Number of requests used within the rate limit window",
+			labels,
+			nil,
+		),
+		Reset: prometheus.NewDesc(
+			"github_rate_limit_reset_timestamp",
+			"Timestamp when the rate limit window gets reset",
+			labels,
+			nil,
+		),
+	}
+}
+
+// Metrics simply returns the list metric descriptors for generating a documentation.
+func (c *RateLimitCollector) Metrics() []*prometheus.Desc {
+	return []*prometheus.Desc{
+		c.Limit,
+		c.Remaining,
+		c.Used,
+		c.Reset,
+	}
+}
+
+// Describe sends the super-set of all possible descriptors of metrics collected by this Collector.
+func (c *RateLimitCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.Limit
+	ch <- c.Remaining
+	ch <- c.Used
+	ch <- c.Reset
+}
+
+// Collect is called by the Prometheus registry when collecting metrics.
+func (c *RateLimitCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, credential := range c.credentials {
+		c.collect(ch, credential)
+	}
+}
+
+// collect fetches the rate limits with the credential, the request is pinned
+// to the credential if the client uses a pool.
+func (c *RateLimitCollector) collect(ch chan<- prometheus.Metric, credential string) {
+	ctx, cancel := collectorContext("rate_limit", c.config.Timeout)
+	defer cancel()
+
+	now := time.Now()
+	record, resp, err := c.client.RateLimit.Get(transport.WithCredential(ctx, credential))
+	c.duration.WithLabelValues("rate_limit").Observe(time.Since(now).Seconds())
+	defer closeBody(resp)
+
+	if err != nil {
+		c.logger.Error("Failed to fetch rate limits",
+			"credential", credential,
+			"err", err,
+		)
+
+		c.failures.WithLabelValues("rate_limit").Inc()
+		return
+	}
+
+	c.logger.Debug("Fetched rate limits",
+		"credential", credential,
+		"duration", time.Since(now),
+	)
+
+	for resource, rate := range map[string]*github.Rate{
+		"core":                        record.Core,
+		"search":                      record.Search,
+		"graphql":                     record.GraphQL,
+		"actions_runner_registration": record.ActionsRunnerRegistration,
+	} {
+		if rate == nil {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.Limit,
+			prometheus.GaugeValue,
+			float64(rate.Limit),
+			credential,
+			resource,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.Remaining,
+			prometheus.GaugeValue,
+			float64(rate.Remaining),
+			credential,
+			resource,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.Used,
+			prometheus.GaugeValue,
+			float64(rate.Used),
+			credential,
+			resource,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.Reset,
+			prometheus.GaugeValue,
+			float64(rate.Reset.Unix()),
+			credential,
+			resource,
+		)
+	}
+}
@@ -0,0 +1,72 @@
+package exporter
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/promhippie/github_exporter/pkg/config"
+	"github.com/promhippie/github_exporter/pkg/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitCollector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		used := 1000
+
+		if r.Header.Get("Authorization") == "Bearer second" {
+			used = 10
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"resources": {
+			"core": {"limit": 5000, "remaining": %d, "used": %d, "reset": 1700000000},
+			"search": {"limit": 30, "remaining": 30, "used": 0, "reset": 1700000060}
+		}}`, 5000-used, used)
+	}))
+
+	defer server.Close()
+
+	url := server.URL + "/"
+	client, err := github.NewClient(
+		github.WithURLs(&url, &url),
+		github.WithTransport(transport.Pool([]*transport.Credential{
+			{Name: "first", Transport: transport.Token(http.DefaultTransport, "first")},
+			{Name: "second", Transport: transport.Token(http.DefaultTransport, "second")},
+		}, func(string, string, *github.Rate, bool) {})),
+	)
+
+	require.NoError(t, err)
+
+	collector := NewRateLimitCollector(
+		slog.Default(),
+		client,
+		nil,
+		prometheus.NewCounterVec(prometheus.CounterOpts{Name: "failures"}, []string{"collector"}),
+		prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "duration"}, []string{"collector"}),
+		config.Target{Timeout: time.Second},
+		[]string{"first", "second"},
+	)
+
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP github_rate_limit_remaining Number of requests remaining within the rate limit window
+# TYPE github_rate_limit_remaining gauge
+github_rate_limit_remaining{credential="first",resource="core"} 4000
+github_rate_limit_remaining{credential="first",resource="search"} 30
+github_rate_limit_remaining{credential="second",resource="core"} 4990
+github_rate_limit_remaining{credential="second",resource="search"} 30
+# HELP github_rate_limit_used Number of requests used within the rate limit window
+# TYPE github_rate_limit_used gauge
+github_rate_limit_used{credential="first",resource="core"} 1000
+github_rate_limit_used{credential="first",resource="search"} 0
+github_rate_limit_used{credential="second",resource="core"} 10
+github_rate_limit_used{credential="second",resource="search"} 0
+`), "github_rate_limit_remaining", "github_rate_limit_used"))
+}
@@ -43,6 +43,9 @@ type RepoCollector struct {
 	Pushed           *prometheus.Desc
 	Created          *prometheus.Desc
 	Updated          *prometheus.Desc
+	DefaultBranch    *prometheus.Desc
+	PullRequests     *prometheus.Desc
+	LatestRelease    *prometheus.Desc
 }
 
 // NewRepoCollector returns a new RepoCollector.
@@ -186,6 +189,24 @@ func NewRepoCollector(logger *slog.Logger, client *github.Client, db store.Store
 			labels,
 			nil,
 		),
+		DefaultBranch: prometheus.NewDesc(
+			"github_repo_default_branch",
+			"Default branch of this repository",
+			append(labels, "branch"),
+			nil,
+		),
+		PullRequests: prometheus.NewDesc(
+			"github_repo_pull_requests",
+			"Number of open pull requests, only provided by the GraphQL backend",
+			labels,
+			nil,
+		),
+		LatestRelease: prometheus.NewDesc(
+			"github_repo_latest_release_timestamp",
+			"Timestamp of the latest release, only provided by the GraphQL backend",
+			labels,
+			nil,
+		),
 	}
 }
 
@@ -213,6 +234,9 @@ func (c *RepoCollector) Metrics() []*prometheus.Desc {
 		c.Pushed,
 		c.Created,
 		c.Updated,
+		c.DefaultBranch,
+		c.PullRequests,
+		c.LatestRelease,
 	}
 }
 
@@ -239,47 +263,30 @@ func (c *RepoCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.Pushed
 	ch <- c.Created
 	ch <- c.Updated
+	ch <- c.DefaultBranch
+	ch <- c.PullRequests
+	ch <- c.LatestRelease
 }
 
 // Collect is called by the Prometheus registry when collecting metrics.
 func (c *RepoCollector) Collect(ch chan<- prometheus.Metric) {
 	collected := make([]string, 0)
 
-	for _, name := range c.config.Repos {
-		n := strings.Split(name, "/")
+	scrape, cancel := scrapeContext(c.config.ScrapeTimeout)
+	defer cancel()
 
-		if len(n) != 2 {
-			c.logger.Error("Invalid repo name",
-				"name", name,
-			)
-
-			c.failures.WithLabelValues("repo").Inc()
-			continue
-		}
-
-		owner, repo := n[0], n[1]
+	var results [][]*repoRecord
 
-		ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
-		defer cancel()
-
-		now := time.Now()
-		records, err := reposByOwnerAndName(ctx, c.client, owner, repo, c.config.PerPage)
-		c.duration.WithLabelValues("repo").Observe(time.Since(now).Seconds())
-
-		if err != nil {
-			c.logger.Error("Failed to fetch repos",
-				"name", name,
-				"err", err,
-			)
-
-			c.failures.WithLabelValues("repo").Inc()
-			continue
-		}
+	switch c.config.RepoBackend {
+	case RepoBackendGraphQL:
+		results = c.graphqlRepos(scrape)
+	default:
+		results = c.restRepos(scrape)
+	}
 
-		c.logger.Debug("Fetched repos",
-			"count", len(records),
-			"duration", time.Since(now),
-		)
+	for idx, records := range results {
+		name := c.config.Repos[idx]
+		owner, _, _ := strings.Cut(name, "/")
 
 		for _, record := range records {
 			if !glob.Glob(name, record.GetFullName()) {
@@ -451,6 +458,102 @@ func (c *RepoCollector) Collect(ch chan<- prometheus.Metric) {
 				float64(record.GetUpdatedAt().Unix()),
 				labels...,
 			)
+
+			if record.GetDefaultBranch() != "" {
+				ch <- prometheus.MustNewConstMetric(
+					c.DefaultBranch,
+					prometheus.GaugeValue,
+					1.0,
+					append(labels, record.GetDefaultBranch())...,
+				)
+			}
+
+			if record.PullRequests != nil {
+				ch <- prometheus.MustNewConstMetric(
+					c.PullRequests,
+					prometheus.GaugeValue,
+					float64(*record.PullRequests),
+					labels...,
+				)
+			}
+
+			if record.LatestRelease != nil {
+				ch <- prometheus.MustNewConstMetric(
+					c.LatestRelease,
+					prometheus.GaugeValue,
+					float64(record.LatestRelease.Unix()),
+					labels...,
+				)
+			}
+		}
+	}
+}
+
+// restRepos fetches the repositories of all patterns with the REST API, the
+// result contains the repositories per pattern.
+func (c *RepoCollector) restRepos(scrape context.Context) [][]*repoRecord {
+	results := make([][]*repoRecord, len(c.config.Repos))
+
+	if skipped := parallel(scrape, c.config.Concurrency, len(c.config.Repos), func(idx int) {
+		name := c.config.Repos[idx]
+		n := strings.Split(name, "/")
+
+		if len(n) != 2 {
+			c.logger.Error("Invalid repo name",
+				"name", name,
+			)
+
+			c.failures.WithLabelValues("repo").Inc()
+			return
 		}
+
+		owner, repo := n[0], n[1]
+
+		ctx, cancel := requestContext(scrape, "repo", c.config.Timeout)
+		defer cancel()
+
+		now := time.Now()
+		records, err := reposByOwnerAndName(ctx, c.client, owner, repo, c.config.PerPage)
+		c.duration.WithLabelValues("repo").Observe(time.Since(now).Seconds())
+
+		if err != nil {
+			c.logger.Error("Failed to fetch repos",
+				"name", name,
+				"err", err,
+			)
+
+			c.failures.WithLabelValues("repo").Inc()
+			return
+		}
+
+		c.logger.Debug("Fetched repos",
+			"count", len(records),
+			"duration", time.Since(now),
+		)
+
+		results[idx] = make([]*repoRecord, 0, len(records))
+
+		for _, record := range records {
+			results[idx] = append(results[idx], &repoRecord{
+				Repository: record,
+			})
+		}
+	}); skipped > 0 {
+		c.logger.Error("Scrape deadline exceeded",
+			"skipped", skipped,
+		)
+
+		c.failures.WithLabelValues("repo").Inc()
 	}
+
+	return results
+}
+
+// repoRecord defines a fetched repository, the open pull requests and the
+// latest release are only provided by the GraphQL backend. The REST API would
+// require two additional requests per repository on every scrape.
+type repoRecord struct {
+	*github.Repository
+	PullRequests  *int
+	LatestRelease *time.Time
 }
@@ -0,0 +1,435 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/promhippie/github_exporter/pkg/transport"
+)
+
+const (
+	// RepoBackendREST fetches the repositories with the REST API.
+	RepoBackendREST = "rest"
+
+	// RepoBackendGraphQL fetches the repositories with batched GraphQL queries.
+	RepoBackendGraphQL = "graphql"
+
+	// graphqlBatchSize defines the maximum number of repositories per query.
+	graphqlBatchSize = 50
+)
+
+var (
+	// ErrGraphQLEmpty gets returned if a GraphQL response does not contain data.
+	ErrGraphQLEmpty = errors.New("empty graphql response")
+)
+
+const graphqlRepoFragment = `
+fragment repo on Repository {
+  name
+  nameWithOwner
+  owner { login }
+  isFork
+  forkCount
+  stargazerCount
+  watchers { totalCount }
+  diskUsage
+  rebaseMergeAllowed
+  squashMergeAllowed
+  mergeCommitAllowed
+  isArchived
+  isPrivate
+  hasIssuesEnabled
+  hasWikiEnabled
+  hasProjectsEnabled
+  pushedAt
+  createdAt
+  updatedAt
+  defaultBranchRef { name }
+  issues(states: OPEN) { totalCount }
+  pullRequests(states: OPEN) { totalCount }
+  latestRelease { publishedAt }
+}`
+
+const graphqlSearchQuery = `
+query($query: String!, $first: Int!, $after: String) {
+  search(query: $query, type: REPOSITORY, first: $first, after: $after) {
+    pageInfo { hasNextPage endCursor }
+    nodes { ...repo }
+  }
+}` + graphqlRepoFragment
+
+type graphqlRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []graphqlError  `json:"errors"`
+}
+
+type graphqlError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	Path    []any  `json:"path"`
+}
+
+type graphqlCount struct {
+	TotalCount int `json:"totalCount"`
+}
+
+type graphqlRepository struct {
+	Name          string `json:"name"`
+	NameWithOwner string `json:"nameWithOwner"`
+	Owner         struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+	IsFork             bool         `json:"isFork"`
+	ForkCount          int          `json:"forkCount"`
+	StargazerCount     int          `json:"stargazerCount"`
+	Watchers           graphqlCount `json:"watchers"`
+	DiskUsage          int          `json:"diskUsage"`
+	RebaseMergeAllowed bool         `json:"rebaseMergeAllowed"`
+	SquashMergeAllowed bool         `json:"squashMergeAllowed"`
+	MergeCommitAllowed bool         `json:"mergeCommitAllowed"`
+	IsArchived         bool         `json:"isArchived"`
+	IsPrivate          bool         `json:"isPrivate"`
+	HasIssuesEnabled   bool         `json:"hasIssuesEnabled"`
+	HasWikiEnabled     bool         `json:"hasWikiEnabled"`
+	HasProjectsEnabled bool         `json:"hasProjectsEnabled"`
+	PushedAt           *time.Time   `json:"pushedAt"`
+	CreatedAt          *time.Time   `json:"createdAt"`
+	UpdatedAt          *time.Time   `json:"updatedAt"`
+	DefaultBranchRef   *struct {
+		Name string `json:"name"`
+	} `json:"defaultBranchRef"`
+	Issues        graphqlCount `json:"issues"`
+	PullRequests  graphqlCount `json:"pullRequests"`
+	LatestRelease *struct {
+		PublishedAt *time.Time `json:"publishedAt"`
+	} `json:"latestRelease"`
+}
+
+// record maps the GraphQL repository to the REST representation, that way
+// both backends expose the same metrics. The open issues include the pull
+// requests and the watchers match the stargazers like within the REST API.
+// The network count, pages and downloads are not available via GraphQL, they
+// report 0 like the search results of wildcard patterns do with REST.
+func (r *graphqlRepository) record() *repoRecord {
+	result := &repoRecord{
+		Repository: &github.Repository{
+			Name:             github.Ptr(r.Name),
+			FullName:         github.Ptr(r.NameWithOwner),
+			Owner:            &github.User{Login: github.Ptr(r.Owner.Login)},
+			Fork:             github.Ptr(r.IsFork),
+			ForksCount:       github.Ptr(r.ForkCount),
+			OpenIssuesCount:  github.Ptr(r.Issues.TotalCount + r.PullRequests.TotalCount),
+			StargazersCount:  github.Ptr(r.StargazerCount),
+			SubscribersCount: github.Ptr(r.Watchers.TotalCount),
+			WatchersCount:    github.Ptr(r.StargazerCount),
+			Size:             github.Ptr(r.DiskUsage),
+			AllowRebaseMerge: github.Ptr(r.RebaseMergeAllowed),
+			AllowSquashMerge: github.Ptr(r.SquashMergeAllowed),
+			AllowMergeCommit: github.Ptr(r.MergeCommitAllowed),
+			Archived:         github.Ptr(r.IsArchived),
+			Private:          github.Ptr(r.IsPrivate),
+			HasIssues:        github.Ptr(r.HasIssuesEnabled),
+			HasWiki:          github.Ptr(r.HasWikiEnabled),
+			HasProjects:      github.Ptr(r.HasProjectsEnabled),
+		},
+		PullRequests: github.Ptr(r.PullRequests.TotalCount),
+	}
+
+	if r.PushedAt != nil {
+		result.PushedAt = &github.Timestamp{Time: *r.PushedAt}
+	}
+
+	if r.CreatedAt != nil {
+		result.CreatedAt = &github.Timestamp{Time: *r.CreatedAt}
+	}
+
+	if r.UpdatedAt != nil {
+		result.UpdatedAt = &github.Timestamp{Time: *r.UpdatedAt}
+	}
+
+	if r.DefaultBranchRef != nil {
+		result.DefaultBranch = github.Ptr(r.DefaultBranchRef.Name)
+	}
+
+	if r.LatestRelease != nil {
+		result.LatestRelease = r.LatestRelease.PublishedAt
+	}
+
+	return result
+}
+
+// graphqlJob defines a single query, either a batch of repositories of the
+// same owner or a search for a wildcard pattern.
+type graphqlJob struct {
+	owner   string
+	search  bool
+	indexes []int
+}
+
+// graphqlRepos fetches the repositories of all patterns with batched GraphQL
+// queries, the result contains the repositories per pattern.
+func (c *RepoCollector) graphqlRepos(scrape context.Context) [][]*repoRecord {
+	results := make([][]*repoRecord, len(c.config.Repos))
+	jobs := make([]*graphqlJob, 0)
+	batches := make(map[string]*graphqlJob)
+
+	for idx, name := range c.config.Repos {
+		n := strings.Split(name, "/")
+
+		if len(n) != 2 {
+			c.logger.Error("Invalid repo name",
+				"name", name,
+			)
+
+			c.failures.WithLabelValues("repo").Inc()
+			continue
+		}
+
+		if strings.Contains(n[1], "*") {
+			jobs = append(jobs, &graphqlJob{
+				owner:   n[0],
+				search:  true,
+				indexes: []int{idx},
+			})
+
+			continue
+		}
+
+		job, ok := batches[n[0]]
+
+		if !ok || len(job.indexes) >= graphqlBatchSize {
+			job = &graphqlJob{
+				owner: n[0],
+			}
+
+			batches[n[0]] = job
+			jobs = append(jobs, job)
+		}
+
+		job.indexes = append(job.indexes, idx)
+	}
+
+	if skipped := parallel(scrape, c.config.Concurrency, len(jobs), func(idx int) {
+		job := jobs[idx]
+
+		ctx, cancel := requestContext(transport.WithOwner(scrape, job.owner), "repo", c.config.Timeout)
+		defer cancel()
+
+		var (
+			count int
+			err   error
+		)
+
+		now := time.Now()
+
+		if job.search {
+			count, err = c.graphqlSearch(ctx, job, results)
+		} else {
+			count, err = c.graphqlBatch(ctx, job, results)
+		}
+
+		c.duration.WithLabelValues("repo").Observe(time.Since(now).Seconds())
+
+		if err != nil {
+			c.logger.Error("Failed to fetch repos",
+				"owner", job.owner,
+				"count", len(job.indexes),
+				"err", err,
+			)
+
+			c.failures.WithLabelValues("repo").Add(float64(len(job.indexes)))
+			return
+		}
+
+		c.logger.Debug("Fetched repos",
+			"owner", job.owner,
+			"count", count,
+			"duration", time.Since(now),
+		)
+	}); skipped > 0 {
+		c.logger.Error("Scrape deadline exceeded",
+			"skipped", skipped,
+		)
+
+		c.failures.WithLabelValues("repo").Inc()
+	}
+
+	return results
+}
+
+// graphqlBatch fetches all repositories of the job within a single query,
+// every repository gets queried with its own alias.
+func (c *RepoCollector) graphqlBatch(ctx context.Context, job *graphqlJob, results [][]*repoRecord) (int, error) {
+	params := []string{"$owner: String!"}
+	fields := make([]string, 0, len(job.indexes))
+
+	variables := map[string]any{
+		"owner": job.owner,
+	}
+
+	for i, idx := range job.indexes {
+		_, name, _ := strings.Cut(c.config.Repos[idx], "/")
+
+		params = append(params, fmt.Sprintf("$name%d: String!", i))
+		fields = append(fields, fmt.Sprintf("  repo%d: repository(owner: $owner, name: $name%d) { ...repo }", i, i))
+		variables[fmt.Sprintf("name%d", i)] = name
+	}
+
+	query := fmt.Sprintf(
+		"query(%s) {\n%s\n}%s",
+		strings.Join(params, ", "),
+		strings.Join(fields, "\n"),
+		graphqlRepoFragment,
+	)
+
+	data := make(map[string]*graphqlRepository, len(job.indexes))
+	errs, err := c.graphql(ctx, query, variables, &data)
+
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+
+	for i, idx := range job.indexes {
+		alias := fmt.Sprintf("repo%d", i)
+		record, ok := data[alias]
+
+		if !ok || record == nil {
+			c.logger.Error("Failed to fetch repos",
+				"name", c.config.Repos[idx],
+				"err", graphqlMessage(errs, alias),
+			)
+
+			c.failures.WithLabelValues("repo").Inc()
+			continue
+		}
+
+		results[idx] = []*repoRecord{record.record()}
+		count++
+	}
+
+	return count, nil
+}
+
+// graphqlSearch fetches all repositories of the owner for a wildcard pattern,
+// the patterns get matched while collecting like for the REST backend.
+func (c *RepoCollector) graphqlSearch(ctx context.Context, job *graphqlJob, results [][]*repoRecord) (int, error) {
+	variables := map[string]any{
+		"query": fmt.Sprintf("user:%s", job.owner),
+		"first": min(max(c.config.PerPage, 1), 100),
+	}
+
+	records := make([]*repoRecord, 0)
+
+	for {
+		data := struct {
+			Search struct {
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+				Nodes []*graphqlRepository `json:"nodes"`
+			} `json:"search"`
+		}{}
+
+		errs, err := c.graphql(ctx, graphqlSearchQuery, variables, &data)
+
+		if err != nil {
+			return 0, err
+		}
+
+		if len(errs) > 0 {
+			return 0, errors.New(errs[0].Message)
+		}
+
+		for _, node := range data.Search.Nodes {
+			if node != nil {
+				records = append(records, node.record())
+			}
+		}
+
+		if !data.Search.PageInfo.HasNextPage {
+			break
+		}
+
+		variables["after"] = data.Search.PageInfo.EndCursor
+	}
+
+	results[job.indexes[0]] = records
+	return len(records), nil
+}
+
+// graphql executes the query and decodes the data, partial errors get
+// returned as long as the response contains any data.
+func (c *RepoCollector) graphql(ctx context.Context, query string, variables map[string]any, data any) ([]graphqlError, error) {
+	req, err := c.client.NewRequest(
+		ctx,
+		http.MethodPost,
+		graphqlURL(c.client),
+		&graphqlRequest{
+			Query:     query,
+			Variables: variables,
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &graphqlResponse{}
+	resp, err := c.client.Do(req, result)
+	closeBody(resp)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Data) == 0 || string(result.Data) == "null" {
+		if len(result.Errors) > 0 {
+			return nil, errors.New(result.Errors[0].Message)
+		}
+
+		return nil, ErrGraphQLEmpty
+	}
+
+	if err := json.Unmarshal(result.Data, data); err != nil {
+		return nil, err
+	}
+
+	return result.Errors, nil
+}
+
+// graphqlMessage returns the error message for the alias of a batched query.
+func graphqlMessage(errs []graphqlError, alias string) string {
+	for _, err := range errs {
+		if len(err.Path) > 0 && err.Path[0] == alias {
+			return err.Message
+		}
+	}
+
+	return "repository not found"
+}
+
+// graphqlURL builds the GraphQL endpoint based on the REST endpoint of the
+// client, GitHub Enterprise serves it at /api/graphql.
+func graphqlURL(client *github.Client) string {
+	base := client.BaseURL()
+
+	if strings.HasSuffix(base, "/api/v3/") {
+		return strings.TrimSuffix(base, "v3/") + "graphql"
+	}
+
+	return base + "graphql"
+}
@@ -0,0 +1,177 @@
+package exporter
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/promhippie/github_exporter/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepoCollectorGraphQL(t *testing.T) {
+	repo := func(owner, name string, forks, issues, pulls int) map[string]any {
+		return map[string]any{
+			"name":               name,
+			"nameWithOwner":      owner + "/" + name,
+			"owner":              map[string]any{"login": owner},
+			"forkCount":          forks,
+			"stargazerCount":     10,
+			"watchers":           map[string]any{"totalCount": 2},
+			"issues":             map[string]any{"totalCount": issues},
+			"pullRequests":       map[string]any{"totalCount": pulls},
+			"hasIssuesEnabled":   true,
+			"defaultBranchRef":   map[string]any{"name": "main"},
+			"createdAt":          "2020-01-01T00:00:00Z",
+			"updatedAt":          "2024-01-01T00:00:00Z",
+			"pushedAt":           "2024-01-01T00:00:00Z",
+			"latestRelease":      map[string]any{"publishedAt": "2023-11-14T22:13:20Z"},
+			"rebaseMergeAllowed": true,
+		}
+	}
+
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/graphql", r.URL.Path)
+
+		req := &graphqlRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(req))
+
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+
+		if strings.Contains(req.Query, "search(") {
+			assert.Equal(t, "user:webhippie", req.Variables["query"])
+
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"data": map[string]any{
+					"search": map[string]any{
+						"pageInfo": map[string]any{"hasNextPage": false},
+						"nodes": []any{
+							repo("webhippie", "example", 1, 0, 0),
+							repo("webhippie", "other", 2, 0, 0),
+						},
+					},
+				},
+			})
+
+			return
+		}
+
+		if req.Variables["owner"] == "webhippie" {
+			assert.Equal(t, "example", req.Variables["name0"])
+
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"data": map[string]any{
+					"repo0": repo("webhippie", "example", 1, 0, 0),
+				},
+			})
+
+			return
+		}
+
+		assert.Equal(t, "promhippie", req.Variables["owner"])
+		assert.Equal(t, "example", req.Variables["name0"])
+		assert.Equal(t, "missing", req.Variables["name1"])
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{
+				"repo0": repo("promhippie", "example", 3, 4, 2),
+				"repo1": nil,
+			},
+			"errors": []any{
+				map[string]any{
+					"type":    "NOT_FOUND",
+					"message": "Could not resolve to a Repository with the name 'promhippie/missing'.",
+					"path":    []any{"repo1"},
+				},
+			},
+		})
+	}))
+
+	defer server.Close()
+
+	url := server.URL + "/"
+	client, err := github.NewClient(github.WithURLs(&url, &url))
+	require.NoError(t, err)
+
+	failures := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "failures"}, []string{"collector"})
+
+	collector := NewRepoCollector(
+		slog.Default(),
+		client,
+		nil,
+		failures,
+		prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "duration"}, []string{"collector"}),
+		config.Target{
+			Repos: []string{
+				"promhippie/example",
+				"promhippie/missing",
+				"webhippie/*",
+				"webhippie/example",
+			},
+			RepoBackend: RepoBackendGraphQL,
+			Timeout:     time.Second,
+			Concurrency: 1,
+			PerPage:     100,
+		},
+	)
+
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP github_repo_forks How often has this repository been forked
+# TYPE github_repo_forks gauge
+github_repo_forks{name="example",owner="promhippie"} 3
+github_repo_forks{name="example",owner="webhippie"} 1
+github_repo_forks{name="other",owner="webhippie"} 2
+# HELP github_repo_issues Number of open issues on this repository
+# TYPE github_repo_issues gauge
+github_repo_issues{name="example",owner="promhippie"} 6
+github_repo_issues{name="example",owner="webhippie"} 0
+github_repo_issues{name="other",owner="webhippie"} 0
+# HELP github_repo_pull_requests Number of open pull requests, only provided by the GraphQL backend
+# TYPE github_repo_pull_requests gauge
+github_repo_pull_requests{name="example",owner="promhippie"} 2
+github_repo_pull_requests{name="example",owner="webhippie"} 0
+github_repo_pull_requests{name="other",owner="webhippie"} 0
+# HELP github_repo_latest_release_timestamp Timestamp of the latest release, only provided by the GraphQL backend
+# TYPE github_repo_latest_release_timestamp gauge
+github_repo_latest_release_timestamp{name="example",owner="promhippie"} 1.7e+09
+github_repo_latest_release_timestamp{name="example",owner="webhippie"} 1.7e+09
+github_repo_latest_release_timestamp{name="other",owner="webhippie"} 1.7e+09
+# HELP github_repo_default_branch Default branch of this repository
+# TYPE github_repo_default_branch gauge
+github_repo_default_branch{branch="main",name="example",owner="promhippie"} 1
+github_repo_default_branch{branch="main",name="example",owner="webhippie"} 1
+github_repo_default_branch{branch="main",name="other",owner="webhippie"} 1
+# HELP github_repo_network Number of repositories in the network
+# TYPE github_repo_network gauge
+github_repo_network{name="example",owner="promhippie"} 0
+github_repo_network{name="example",owner="webhippie"} 0
+github_repo_network{name="other",owner="webhippie"} 0
+`), "github_repo_forks", "github_repo_issues", "github_repo_pull_requests", "github_repo_latest_release_timestamp", "github_repo_default_branch", "github_repo_network"))
+
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, 1.0, testutil.ToFloat64(failures.WithLabelValues("repo")))
+}
+
+func TestGraphQLURL(t *testing.T) {
+	public := "https://api.github.com/"
+	client, err := github.NewClient(github.WithURLs(&public, &public))
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.github.com/graphql", graphqlURL(client))
+
+	enterprise := "https://github.example.com"
+	client, err = github.NewClient(github.WithEnterpriseURLs(enterprise, enterprise))
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.example.com/api/graphql", graphqlURL(client))
+}
@@ -0,0 +1,74 @@
+package exporter
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/promhippie/github_exporter/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepoCollectorParallel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/search/repositories":
+			_, _ = w.Write([]byte(`{"total_count": 2, "items": [
+				{"name": "example", "full_name": "promhippie/example", "forks_count": 3},
+				{"name": "other", "full_name": "promhippie/other", "forks_count": 5}
+			]}`))
+		case "/repos/promhippie/example":
+			_, _ = w.Write([]byte(`{"name": "example", "full_name": "promhippie/example", "forks_count": 3}`))
+		case "/repos/webhippie/example":
+			_, _ = w.Write([]byte(`{"name": "example", "full_name": "webhippie/example", "forks_count": 7}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	defer server.Close()
+
+	url := server.URL + "/"
+	client, err := github.NewClient(github.WithURLs(&url, &url))
+	require.NoError(t, err)
+
+	failures := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "failures"}, []string{"collector"})
+
+	collector := NewRepoCollector(
+		slog.Default(),
+		client,
+		nil,
+		failures,
+		prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "duration"}, []string{"collector"}),
+		config.Target{
+			Repos: []string{
+				"promhippie/*",
+				"promhippie/example",
+				"webhippie/example",
+				"webhippie/missing",
+			},
+			Timeout:       time.Second,
+			ScrapeTimeout: 5 * time.Second,
+			Concurrency:   4,
+			PerPage:       100,
+		},
+	)
+
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP github_repo_forks How often has this repository been forked
+# TYPE github_repo_forks gauge
+github_repo_forks{name="example",owner="promhippie"} 3
+github_repo_forks{name="other",owner="promhippie"} 5
+github_repo_forks{name="example",owner="webhippie"} 7
+`), "github_repo_forks"))
+
+	require.Equal(t, 1.0, testutil.ToFloat64(failures.WithLabelValues("repo")))
+}
@@ -295,7 +295,13 @@ func (c *RunnerCollector) repoRunners() []runner {
 	collected := make([]string, 0)
 	result := make([]runner, 0)
 
-	for _, name := range c.config.Repos {
+	scrape, cancel := scrapeContext(c.config.ScrapeTimeout)
+	defer cancel()
+
+	repos := make([][]*github.Repository, len(c.config.Repos))
+
+	if skipped := parallel(scrape, c.config.Concurrency, len(c.config.Repos), func(idx int) {
+		name := c.config.Repos[idx]
 		n := strings.Split(name, "/")
 
 		if len(n) != 2 {
@@ -304,15 +310,15 @@ func (c *RunnerCollector) repoRunners() []runner {
 			)
 
 			c.failures.WithLabelValues("runner").Inc()
-			continue
+			return
 		}
 
 		splitOwner, splitName := n[0], n[1]
 
-		ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+		ctx, cancel := requestContext(scrape, "runner", c.config.Timeout)
 		defer cancel()
 
-		repos, err := reposByOwnerAndName(ctx, c.client, splitOwner, splitName, c.config.PerPage)
+		records, err := reposByOwnerAndName(ctx, c.client, splitOwner, splitName, c.config.PerPage)
 
 		if err != nil {
 			c.logger.Error("Failed to fetch repos",
@@ -321,14 +327,30 @@ func (c *RunnerCollector) repoRunners() []runner {
 			)
 
 			c.failures.WithLabelValues("runner").Inc()
-			continue
+			return
 		}
 
 		c.logger.Debug("Fetched repos for runners",
-			"count", len(repos),
+			"count", len(records),
 		)
 
-		for _, repo := range repos {
+		repos[idx] = records
+	}); skipped > 0 {
+		c.logger.Error("Scrape deadline exceeded",
+			"skipped", skipped,
+		)
+
+		c.failures.WithLabelValues("runner").Inc()
+		return result
+	}
+
+	targets := make([]*github.Repository, 0)
+	owners := make([]string, 0)
+
+	for idx, records := range repos {
+		name := c.config.Repos[idx]
+
+		for _, repo := range records {
 			if !glob.Glob(name, *repo.FullName) {
 				continue
 			}
@@ -343,24 +365,44 @@ func (c *RunnerCollector) repoRunners() []runner {
 
 			collected = append(collected, repo.GetFullName())
 
-			records, err := c.pagedRepoRunners(ctx, *repo.Owner.Login, *repo.Name)
+			targets = append(targets, repo)
+			owners = append(owners, name)
+		}
+	}
 
-			if err != nil {
-				c.logger.Error("Failed to fetch repo runners",
-					"name", name,
-					"err", err,
-				)
+	records := make([][]*github.Runner, len(targets))
 
-				c.failures.WithLabelValues("runner").Inc()
-				continue
-			}
+	if skipped := parallel(scrape, c.config.Concurrency, len(targets), func(idx int) {
+		ctx, cancel := requestContext(scrape, "runner", c.config.Timeout)
+		defer cancel()
 
-			for _, row := range records {
-				result = append(result, runner{
-					Owner:  name,
-					Runner: row,
-				})
-			}
+		rows, err := c.pagedRepoRunners(ctx, *targets[idx].Owner.Login, *targets[idx].Name)
+
+		if err != nil {
+			c.logger.Error("Failed to fetch repo runners",
+				"name", owners[idx],
+				"err", err,
+			)
+
+			c.failures.WithLabelValues("runner").Inc()
+			return
+		}
+
+		records[idx] = rows
+	}); skipped > 0 {
+		c.logger.Error("Scrape deadline exceeded",
+			"skipped", skipped,
+		)
+
+		c.failures.WithLabelValues("runner").Inc()
+	}
+
+	for idx, rows := range records {
+		for _, row := range rows {
+			result = append(result, runner{
+				Owner:  owners[idx],
+				Runner: row,
+			})
 		}
 	}
 
@@ -412,7 +454,7 @@ func (c *RunnerCollector) enterpriseRunners() []runner {
 	result := make([]runner, 0)
 
 	for _, name := range c.config.Enterprises {
-		ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+		ctx, cancel := collectorContext("runner", c.config.Timeout)
 		defer cancel()
 
 		records, err := c.pagedEnterpriseRunners(ctx, name)
@@ -482,7 +524,7 @@ func (c *RunnerCollector) orgRunners() []runner {
 	result := make([]runner, 0)
 
 	for _, name := range c.config.Orgs {
-		ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+		ctx, cancel := collectorContext("runner", c.config.Timeout)
 		defer cancel()
 
 		records, err := c.pagedOrgRunners(ctx, name)
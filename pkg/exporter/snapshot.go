@@ -0,0 +1,211 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// SnapshotObserver gets called after every refresh of a snapshot.
+type SnapshotObserver func(collector string, duration time.Duration, success bool)
+
+// Snapshot refreshes the wrapped collector in the background and serves the
+// metrics of the latest successful refresh on scrapes. A refresh counts as
+// failed if the collector records a failure while collecting. Until the first
+// successful refresh the partial metrics of the latest failed refresh are
+// served instead.
+type Snapshot struct {
+	logger    *slog.Logger
+	name      string
+	collector prometheus.Collector
+	failures  *prometheus.CounterVec
+	interval  time.Duration
+	staleness time.Duration
+
+	mutex   sync.RWMutex
+	metrics []prometheus.Metric
+	success time.Time
+	updated time.Time
+}
+
+// NewSnapshot wraps the collector, the name has to match the failure label of
+// the collector. Snapshots older than the staleness limit are not served.
+func NewSnapshot(logger *slog.Logger, name string, collector prometheus.Collector, failures *prometheus.CounterVec, interval, staleness time.Duration) *Snapshot {
+	return &Snapshot{
+		logger:    logger.With("snapshot", name),
+		name:      name,
+		collector: collector,
+		failures:  failures,
+		interval:  interval,
+		staleness: staleness,
+	}
+}
+
+// Name returns the name of the wrapped collector.
+func (s *Snapshot) Name() string {
+	return s.name
+}
+
+// Interval returns the refresh interval of the snapshot.
+func (s *Snapshot) Interval() time.Duration {
+	return s.interval
+}
+
+// LastSuccess returns the time of the latest successful refresh.
+func (s *Snapshot) LastSuccess() time.Time {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.success
+}
+
+// Stale checks if the snapshot has never been refreshed or if the served
+// metrics are older than the staleness limit.
+func (s *Snapshot) Stale() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.stale()
+}
+
+func (s *Snapshot) stale() bool {
+	if s.updated.IsZero() {
+		return true
+	}
+
+	return s.staleness > 0 && time.Since(s.updated) > s.staleness
+}
+
+// Describe sends the super-set of all possible descriptors of metrics collected by this Collector.
+func (s *Snapshot) Describe(ch chan<- *prometheus.Desc) {
+	s.collector.Describe(ch)
+}
+
+// Collect is called by the Prometheus registry when collecting metrics.
+func (s *Snapshot) Collect(ch chan<- prometheus.Metric) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.stale() {
+		s.logger.Debug("Skipping stale snapshot",
+			"success", s.success,
+			"updated", s.updated,
+		)
+
+		return
+	}
+
+	for _, metric := range s.metrics {
+		ch <- metric
+	}
+}
+
+// Refresh collects the metrics of the wrapped collector. Failed refreshes
+// keep the previous successful snapshot, partial results are only used as
+// long as there has never been a successful refresh.
+func (s *Snapshot) Refresh() bool {
+	before := s.failureCount()
+
+	ch := make(chan prometheus.Metric)
+	metrics := make([]prometheus.Metric, 0)
+	done := make(chan struct{})
+
+	go func() {
+		for metric := range ch {
+			metrics = append(metrics, metric)
+		}
+
+		close(done)
+	}()
+
+	s.collector.Collect(ch)
+	close(ch)
+	<-done
+
+	success := s.failureCount() == before
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if success {
+		s.metrics = metrics
+		s.success = time.Now()
+		s.updated = s.success
+	} else if s.success.IsZero() {
+		s.metrics = metrics
+		s.updated = time.Now()
+	}
+
+	return success
+}
+
+// Run refreshes the snapshot on every interval until the context gets
+// canceled.
+func (s *Snapshot) Run(ctx context.Context, observe SnapshotObserver) {
+	for {
+		now := time.Now()
+		success := s.Refresh()
+
+		s.logger.Debug("Refreshed snapshot",
+			"success", success,
+			"duration", time.Since(now),
+		)
+
+		observe(s.name, time.Since(now), success)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.interval - min(time.Since(now), s.interval)):
+		}
+	}
+}
+
+// failureCount reads the current value of the failure counter.
+func (s *Snapshot) failureCount() float64 {
+	if s.failures == nil {
+		return 0
+	}
+
+	metric := &dto.Metric{}
+
+	if err := s.failures.WithLabelValues(s.name).Write(metric); err != nil {
+		return 0
+	}
+
+	return metric.GetCounter().GetValue()
+}
+
+// ParseSnapshotIntervals parses the refresh intervals of collectors defined
+// as collector=duration, like billing=1h or runner=1m.
+func ParseSnapshotIntervals(values []string) (map[string]time.Duration, error) {
+	result := make(map[string]time.Duration, len(values))
+
+	for _, value := range values {
+		name, raw, ok := strings.Cut(value, "=")
+
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid snapshot interval %q, expected collector=duration", value)
+		}
+
+		interval, err := time.ParseDuration(strings.TrimSpace(raw))
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid snapshot interval %q: %w", value, err)
+		}
+
+		if interval <= 0 {
+			return nil, fmt.Errorf("invalid snapshot interval %q: must be positive", value)
+		}
+
+		result[strings.TrimSpace(name)] = interval
+	}
+
+	return result, nil
+}
@@ -0,0 +1,151 @@
+package exporter
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/promhippie/github_exporter/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	var failing atomic.Bool
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"resources": {
+			"core": {"limit": 5000, "remaining": 4000, "used": 1000, "reset": 1700000000}
+		}}`))
+	}))
+
+	defer server.Close()
+
+	url := server.URL + "/"
+	client, err := github.NewClient(github.WithURLs(&url, &url))
+	require.NoError(t, err)
+
+	failures := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "failures"}, []string{"collector"})
+
+	snapshot := NewSnapshot(
+		slog.Default(),
+		"rate_limit",
+		NewRateLimitCollector(
+			slog.Default(),
+			client,
+			nil,
+			failures,
+			prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "duration"}, []string{"collector"}),
+			config.Target{Timeout: time.Second},
+			[]string{"anonymous"},
+		),
+		failures,
+		time.Minute,
+		time.Hour,
+	)
+
+	expected := `
+# HELP github_rate_limit_remaining Number of requests remaining within the rate limit window
+# TYPE github_rate_limit_remaining gauge
+github_rate_limit_remaining{credential="anonymous",resource="core"} 4000
+`
+
+	assert.True(t, snapshot.Stale())
+	assert.Equal(t, 0, testutil.CollectAndCount(snapshot))
+
+	assert.True(t, snapshot.Refresh())
+	assert.False(t, snapshot.Stale())
+	require.NoError(t, testutil.CollectAndCompare(snapshot, strings.NewReader(expected), "github_rate_limit_remaining"))
+
+	failing.Store(true)
+
+	assert.False(t, snapshot.Refresh())
+	require.NoError(t, testutil.CollectAndCompare(snapshot, strings.NewReader(expected), "github_rate_limit_remaining"))
+
+	current := requests.Load()
+	require.NoError(t, testutil.CollectAndCompare(snapshot, strings.NewReader(expected), "github_rate_limit_remaining"))
+	assert.Equal(t, current, requests.Load())
+
+	snapshot.updated = time.Now().Add(-2 * time.Hour)
+
+	assert.True(t, snapshot.Stale())
+	assert.Equal(t, 0, testutil.CollectAndCount(snapshot))
+}
+
+// partialCollector emits a single metric and records a failure on every
+// collect to simulate partial results.
+type partialCollector struct {
+	desc     *prometheus.Desc
+	failures *prometheus.CounterVec
+}
+
+func (c *partialCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *partialCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, 1)
+	c.failures.WithLabelValues("partial").Inc()
+}
+
+func TestSnapshotPartial(t *testing.T) {
+	failures := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "failures"}, []string{"collector"})
+
+	snapshot := NewSnapshot(
+		slog.Default(),
+		"partial",
+		&partialCollector{
+			desc:     prometheus.NewDesc("partial", "Partial metric", nil, nil),
+			failures: failures,
+		},
+		failures,
+		time.Minute,
+		time.Hour,
+	)
+
+	assert.False(t, snapshot.Refresh())
+	assert.False(t, snapshot.Stale())
+	assert.True(t, snapshot.LastSuccess().IsZero())
+
+	require.NoError(t, testutil.CollectAndCompare(snapshot, strings.NewReader(`
+# HELP partial Partial metric
+# TYPE partial gauge
+partial 1
+`), "partial"))
+
+	snapshot.updated = time.Now().Add(-2 * time.Hour)
+
+	assert.True(t, snapshot.Stale())
+	assert.Equal(t, 0, testutil.CollectAndCount(snapshot))
+}
+
+func TestParseSnapshotIntervals(t *testing.T) {
+	intervals, err := ParseSnapshotIntervals([]string{"billing=1h", " runner = 30s "})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]time.Duration{
+		"billing": time.Hour,
+		"runner":  30 * time.Second,
+	}, intervals)
+
+	for _, value := range []string{"billing", "=1h", "billing=foo", "billing=-1m"} {
+		_, err := ParseSnapshotIntervals([]string{value})
+		assert.Error(t, err, value)
+	}
+}
@@ -0,0 +1,209 @@
+package exporter
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/promhippie/github_exporter/pkg/config"
+	"github.com/promhippie/github_exporter/pkg/store"
+)
+
+// StoreCollector collects metrics about the database store. The table stats
+// scan the tables, therefore they only get refreshed on their own interval.
+type StoreCollector struct {
+	client    *github.Client
+	logger    *slog.Logger
+	db        store.Store
+	failures  *prometheus.CounterVec
+	duration  *prometheus.HistogramVec
+	config    config.Target
+	mutex     sync.Mutex
+	stats     []*store.TableStats
+	refreshed time.Time
+
+	Up     *prometheus.Desc
+	Rows   *prometheus.Desc
+	Oldest *prometheus.Desc
+	Newest *prometheus.Desc
+	Size   *prometheus.Desc
+}
+
+// NewStoreCollector returns a new StoreCollector.
+func NewStoreCollector(logger *slog.Logger, client *github.Client, db store.Store, failures *prometheus.CounterVec, duration *prometheus.HistogramVec, cfg config.Target) *StoreCollector {
+	if failures != nil {
+		failures.WithLabelValues("store").Add(0)
+	}
+
+	return &StoreCollector{
+		client:   client,
+		logger:   logger.With("collector", "store"),
+		db:       db,
+		failures: failures,
+		duration: duration,
+		config:   cfg,
+
+		Up: prometheus.NewDesc(
+			"github_store_up",
+			"Whether the database store is reachable",
+			nil,
+			nil,
+		),
+		Rows: prometheus.NewDesc(
+			"github_store_rows",
+			"Number of records stored per table",
+			[]string{"table"},
+			nil,
+		),
+		Oldest: prometheus.NewDesc(
+			"github_store_oldest_record_timestamp",
+			"Timestamp of the oldest record per table",
+			[]string{"table"},
+			nil,
+		),
+		Newest: prometheus.NewDesc(
+			"github_store_newest_record_timestamp",
+			"Timestamp of the newest record per table",
+			[]string{"table"},
+			nil,
+		),
+		Size: prometheus.NewDesc(
+			"github_store_size_bytes",
+			"On-disk size of the database if supported by the driver",
+			nil,
+			nil,
+		),
+	}
+}
+
+// Metrics simply returns the list metric descriptors for generating a documentation.
+func (c *StoreCollector) Metrics() []*prometheus.Desc {
+	return []*prometheus.Desc{
+		c.Up,
+		c.Rows,
+		c.Oldest,
+		c.Newest,
+		c.Size,
+	}
+}
+
+// Describe sends the super-set of all possible descriptors of metrics collected by this Collector.
+func (c *StoreCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.Up
+	ch <- c.Rows
+	ch <- c.Oldest
+	ch <- c.Newest
+	ch <- c.Size
+}
+
+// Collect is called by the Prometheus registry when collecting metrics.
+func (c *StoreCollector) Collect(ch chan<- prometheus.Metric) {
+	now := time.Now()
+	defer func() {
+		c.duration.WithLabelValues("store").Observe(time.Since(now).Seconds())
+	}()
+
+	if _, err := c.db.Ping(); err != nil {
+		c.logger.Error("Failed to ping database",
+			"err", err,
+		)
+
+		c.failures.WithLabelValues("store").Inc()
+
+		ch <- prometheus.MustNewConstMetric(
+			c.Up,
+			prometheus.GaugeValue,
+			0.0,
+		)
+
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.Up,
+		prometheus.GaugeValue,
+		1.0,
+	)
+
+	records, err := c.tableStats()
+
+	if err != nil {
+		c.logger.Error("Failed to fetch table stats",
+			"err", err,
+		)
+
+		c.failures.WithLabelValues("store").Inc()
+		return
+	}
+
+	for _, record := range records {
+		ch <- prometheus.MustNewConstMetric(
+			c.Rows,
+			prometheus.GaugeValue,
+			float64(record.Rows),
+			record.Table,
+		)
+
+		if record.Rows == 0 {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.Oldest,
+			prometheus.GaugeValue,
+			float64(record.Oldest),
+			record.Table,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.Newest,
+			prometheus.GaugeValue,
+			float64(record.Newest),
+			record.Table,
+		)
+	}
+
+	size, err := c.db.GetSize()
+
+	if errors.Is(err, store.ErrUnsupported) {
+		return
+	} else if err != nil {
+		c.logger.Error("Failed to fetch database size",
+			"err", err,
+		)
+
+		c.failures.WithLabelValues("store").Inc()
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.Size,
+		prometheus.GaugeValue,
+		float64(size),
+	)
+}
+
+// tableStats returns the cached table stats and refreshes them if the
+// interval has passed.
+func (c *StoreCollector) tableStats() ([]*store.TableStats, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.stats != nil && time.Since(c.refreshed) < c.config.StoreStats.Interval {
+		return c.stats, nil
+	}
+
+	records, err := c.db.GetTableStats()
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.stats = records
+	c.refreshed = time.Now()
+
+	return records, nil
+}
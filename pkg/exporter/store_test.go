@@ -0,0 +1,52 @@
+package exporter
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/promhippie/github_exporter/pkg/config"
+	"github.com/promhippie/github_exporter/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreCollectorTableStats(t *testing.T) {
+	db, err := store.New("memory://", slog.Default())
+	require.NoError(t, err)
+
+	mockConfig := config.Target{
+		StoreStats: config.StoreStats{
+			Interval: time.Hour,
+		},
+	}
+
+	collector := NewStoreCollector(
+		slog.Default(),
+		nil,
+		db,
+		prometheus.NewCounterVec(prometheus.CounterOpts{Name: "failures"}, []string{"collector"}),
+		prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "duration"}, []string{"collector"}),
+		mockConfig,
+	)
+
+	records, err := collector.tableStats()
+	require.NoError(t, err)
+	require.Len(t, records, 6)
+	assert.Equal(t, int64(0), records[1].Rows)
+
+	require.NoError(t, db.ImportWorkflowJobs([]*store.WorkflowJob{
+		{Owner: "promhippie", Repo: "github_exporter", Identifier: 1, CreatedAt: time.Now().Unix()},
+	}))
+
+	records, err = collector.tableStats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), records[1].Rows, "expected cached stats within the interval")
+
+	collector.refreshed = time.Now().Add(-2 * time.Hour)
+
+	records, err = collector.tableStats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), records[1].Rows)
+}
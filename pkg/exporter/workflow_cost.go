@@ -0,0 +1,644 @@
+package exporter
+
+import (
+	"errors"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/promhippie/github_exporter/pkg/config"
+	"github.com/promhippie/github_exporter/pkg/store"
+	"github.com/ryanuber/go-glob"
+)
+
+const (
+	// workflowCostUnassigned defines the team of repositories which are not
+	// matched by any team mapping.
+	workflowCostUnassigned = "unassigned"
+)
+
+// WorkflowCostCollector collects metrics about the workflow costs.
+type WorkflowCostCollector struct {
+	client   *github.Client
+	logger   *slog.Logger
+	db       store.Store
+	failures *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	config   config.Target
+	rates    map[string]float64
+	teams    []teamMapping
+	usages   map[usageKey]*github.WorkflowRunUsage
+	failed   map[usageKey]time.Time
+	warned   map[string]bool
+	mutex    sync.Mutex
+
+	RunMinutes      *prometheus.Desc
+	RunCost         *prometheus.Desc
+	RepoMinutes     *prometheus.Desc
+	RepoCost        *prometheus.Desc
+	WorkflowMinutes *prometheus.Desc
+	WorkflowCost    *prometheus.Desc
+	TeamMinutes     *prometheus.Desc
+	TeamCost        *prometheus.Desc
+	UnknownSKU      *prometheus.Desc
+}
+
+// NewWorkflowCostCollector returns a new WorkflowCostCollector.
+func NewWorkflowCostCollector(logger *slog.Logger, client *github.Client, db store.Store, failures *prometheus.CounterVec, duration *prometheus.HistogramVec, cfg config.Target) *WorkflowCostCollector {
+	if failures != nil {
+		failures.WithLabelValues("workflow_cost").Add(0)
+	}
+
+	labels := cfg.WorkflowRuns.Labels
+	return &WorkflowCostCollector{
+		client:   client,
+		logger:   logger.With("collector", "workflow_cost"),
+		db:       db,
+		failures: failures,
+		duration: duration,
+		config:   cfg,
+		rates:    parseRates(logger, cfg.WorkflowCosts.Rates),
+		teams:    parseTeams(logger, cfg.WorkflowCosts.Teams),
+		usages:   make(map[usageKey]*github.WorkflowRunUsage),
+		failed:   make(map[usageKey]time.Time),
+		warned:   make(map[string]bool),
+
+		RunMinutes: prometheus.NewDesc(
+			"github_workflow_cost_run_billable_minutes",
+			"Billable minutes of completed workflow runs",
+			labels,
+			nil,
+		),
+		RunCost: prometheus.NewDesc(
+			"github_workflow_cost_run_estimated_amount",
+			"Estimated cost of completed workflow runs",
+			labels,
+			nil,
+		),
+		RepoMinutes: prometheus.NewDesc(
+			"github_workflow_cost_repo_billable_minutes",
+			"Billable minutes of completed workflow runs per repo",
+			[]string{"owner", "repo", "sku"},
+			nil,
+		),
+		RepoCost: prometheus.NewDesc(
+			"github_workflow_cost_repo_estimated_amount",
+			"Estimated cost of completed workflow runs per repo",
+			[]string{"owner", "repo", "sku"},
+			nil,
+		),
+		WorkflowMinutes: prometheus.NewDesc(
+			"github_workflow_cost_workflow_billable_minutes",
+			"Billable minutes of completed workflow runs per workflow",
+			[]string{"owner", "repo", "workflow", "name", "sku"},
+			nil,
+		),
+		WorkflowCost: prometheus.NewDesc(
+			"github_workflow_cost_workflow_estimated_amount",
+			"Estimated cost of completed workflow runs per workflow",
+			[]string{"owner", "repo", "workflow", "name", "sku"},
+			nil,
+		),
+		TeamMinutes: prometheus.NewDesc(
+			"github_workflow_cost_team_billable_minutes",
+			"Billable minutes of completed workflow runs per team, repositories without team are unassigned",
+			[]string{"team", "sku"},
+			nil,
+		),
+		TeamCost: prometheus.NewDesc(
+			"github_workflow_cost_team_estimated_amount",
+			"Estimated cost of completed workflow runs per team, repositories without team are unassigned",
+			[]string{"team", "sku"},
+			nil,
+		),
+		UnknownSKU: prometheus.NewDesc(
+			"github_workflow_cost_unknown_sku_runs",
+			"Number of completed workflow runs with billable minutes on a SKU without rate",
+			[]string{"sku"},
+			nil,
+		),
+	}
+}
+
+// Metrics simply returns the list metric descriptors for generating a documentation.
+func (c *WorkflowCostCollector) Metrics() []*prometheus.Desc {
+	return []*prometheus.Desc{
+		c.RunMinutes,
+		c.RunCost,
+		c.RepoMinutes,
+		c.RepoCost,
+		c.WorkflowMinutes,
+		c.WorkflowCost,
+		c.TeamMinutes,
+		c.TeamCost,
+		c.UnknownSKU,
+	}
+}
+
+// Describe sends the super-set of all possible descriptors of metrics collected by this Collector.
+func (c *WorkflowCostCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.RunMinutes
+	ch <- c.RunCost
+	ch <- c.RepoMinutes
+	ch <- c.RepoCost
+	ch <- c.WorkflowMinutes
+	ch <- c.WorkflowCost
+	ch <- c.TeamMinutes
+	ch <- c.TeamCost
+	ch <- c.UnknownSKU
+}
+
+// Collect is called by the Prometheus registry when collecting metrics.
+func (c *WorkflowCostCollector) Collect(ch chan<- prometheus.Metric) {
+	now := time.Now()
+	records := make([]*store.WorkflowRun, 0)
+
+	err := c.db.WalkWorkflowRuns(c.config.WorkflowRuns.Window, c.config.WorkflowRuns.MaxRows, func(record *store.WorkflowRun) error {
+		if runCompleted(record.Status) {
+			records = append(records, record)
+		}
+
+		return nil
+	})
+
+	if errors.Is(err, store.ErrLimitReached) {
+		c.logger.Warn("Reached maximum number of workflow runs, skipping older records",
+			"max_rows", c.config.WorkflowRuns.MaxRows,
+		)
+	} else if err != nil {
+		c.logger.Error("Failed to fetch workflow runs",
+			"err", err,
+		)
+
+		c.failures.WithLabelValues("workflow_cost").Inc()
+		return
+	}
+
+	labelsByJob := make(map[int64]string)
+
+	err = c.db.WalkWorkflowJobs(c.config.WorkflowJobs.Window, c.config.WorkflowJobs.MaxRows, func(job *store.WorkflowJob) error {
+		labelsByJob[job.Identifier] = job.Labels
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, store.ErrLimitReached) {
+		c.logger.Warn("Failed to fetch workflow jobs, falling back to runner os",
+			"err", err,
+		)
+	}
+
+	c.logger.Debug("Fetched workflow runs",
+		"count", len(records),
+		"duration", time.Since(now),
+	)
+
+	repos := make(map[[3]string]*runCost)
+	workflows := make(map[[5]string]*runCost)
+	teams := make(map[[2]string]*runCost)
+	unknown := make(map[string]float64)
+	seen := make(map[usageKey]bool)
+	missing := make([]*store.WorkflowRun, 0)
+
+	for _, record := range records {
+		key := usageKeyOf(record)
+		seen[key] = true
+
+		if _, ok := c.cached(key); !ok {
+			missing = append(missing, record)
+		}
+	}
+
+	c.prune(seen)
+	c.fetch(missing)
+
+	for _, record := range records {
+		usage, ok := c.cached(usageKeyOf(record))
+
+		if !ok || usage == nil {
+			continue
+		}
+
+		c.logger.Debug("Collecting workflow cost",
+			"owner", record.Owner,
+			"repo", record.Repo,
+			"workflow", record.WorkflowID,
+			"number", record.Number,
+		)
+
+		costs := c.costs(usage, labelsByJob)
+		total := &runCost{}
+		team := c.team(record.Owner, record.Repo)
+
+		for sku, cost := range costs {
+			total.add(cost)
+
+			if _, ok := c.rates[sku]; !ok {
+				c.unknownSKU(sku)
+				unknown[sku]++
+			}
+
+			repoKey := [3]string{record.Owner, record.Repo, sku}
+
+			if _, ok := repos[repoKey]; !ok {
+				repos[repoKey] = &runCost{}
+			}
+
+			repos[repoKey].add(cost)
+
+			workflowKey := [5]string{record.Owner, record.Repo, strconv.FormatInt(record.WorkflowID, 10), record.Name, sku}
+
+			if _, ok := workflows[workflowKey]; !ok {
+				workflows[workflowKey] = &runCost{}
+			}
+
+			workflows[workflowKey].add(cost)
+
+			teamKey := [2]string{team, sku}
+
+			if _, ok := teams[teamKey]; !ok {
+				teams[teamKey] = &runCost{}
+			}
+
+			teams[teamKey].add(cost)
+		}
+
+		labels := []string{}
+
+		for _, label := range c.config.WorkflowRuns.Labels {
+			labels = append(
+				labels,
+				record.ByLabel(label),
+			)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.RunMinutes,
+			prometheus.GaugeValue,
+			total.Minutes,
+			labels...,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.RunCost,
+			prometheus.GaugeValue,
+			total.Amount,
+			labels...,
+		)
+	}
+
+	for key, cost := range repos {
+		ch <- prometheus.MustNewConstMetric(
+			c.RepoMinutes,
+			prometheus.GaugeValue,
+			cost.Minutes,
+			key[:]...,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.RepoCost,
+			prometheus.GaugeValue,
+			cost.Amount,
+			key[:]...,
+		)
+	}
+
+	for key, cost := range workflows {
+		ch <- prometheus.MustNewConstMetric(
+			c.WorkflowMinutes,
+			prometheus.GaugeValue,
+			cost.Minutes,
+			key[:]...,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.WorkflowCost,
+			prometheus.GaugeValue,
+			cost.Amount,
+			key[:]...,
+		)
+	}
+
+	for sku, count := range unknown {
+		ch <- prometheus.MustNewConstMetric(
+			c.UnknownSKU,
+			prometheus.GaugeValue,
+			count,
+			sku,
+		)
+	}
+
+	for key, cost := range teams {
+		ch <- prometheus.MustNewConstMetric(
+			c.TeamMinutes,
+			prometheus.GaugeValue,
+			cost.Minutes,
+			key[:]...,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.TeamCost,
+			prometheus.GaugeValue,
+			cost.Amount,
+			key[:]...,
+		)
+	}
+}
+
+// cached returns the usage of a run if it has been fetched before, failed
+// lookups are cached without usage until the failure TTL expires.
+func (c *WorkflowCostCollector) cached(key usageKey) (*github.WorkflowRunUsage, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if usage, ok := c.usages[key]; ok {
+		return usage, true
+	}
+
+	if failed, ok := c.failed[key]; ok && time.Since(failed) < c.config.WorkflowCosts.FailureTTL {
+		return nil, true
+	}
+
+	return nil, false
+}
+
+// fetch looks up the billable timing of the runs with a bounded number of
+// workers, lookups beyond the per scrape limit are deferred to the next
+// scrape. Completed runs never change so the result is kept until the run
+// leaves the query window.
+func (c *WorkflowCostCollector) fetch(records []*store.WorkflowRun) {
+	if limit := c.config.WorkflowCosts.MaxLookups; limit > 0 && len(records) > limit {
+		c.logger.Debug("Deferring workflow run usage lookups",
+			"deferred", len(records)-limit,
+		)
+
+		records = records[:limit]
+	}
+
+	scrape, cancel := scrapeContext(c.config.ScrapeTimeout)
+	defer cancel()
+
+	if skipped := parallel(scrape, c.config.Concurrency, len(records), func(idx int) {
+		record := records[idx]
+		key := usageKeyOf(record)
+
+		ctx, cancel := requestContext(scrape, "workflow_cost", c.config.Timeout)
+		defer cancel()
+
+		now := time.Now()
+		usage, resp, err := c.client.Actions.GetWorkflowRunUsageByID(ctx, record.Owner, record.Repo, record.Identifier)
+		c.duration.WithLabelValues("workflow_cost").Observe(time.Since(now).Seconds())
+		closeBody(resp)
+
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+
+		if err != nil {
+			c.logger.Error("Failed to fetch workflow run usage",
+				"owner", record.Owner,
+				"repo", record.Repo,
+				"workflow", record.WorkflowID,
+				"number", record.Number,
+				"err", err,
+			)
+
+			c.failures.WithLabelValues("workflow_cost").Inc()
+			c.failed[key] = time.Now()
+			return
+		}
+
+		delete(c.failed, key)
+		c.usages[key] = usage
+	}); skipped > 0 {
+		c.logger.Error("Scrape deadline exceeded",
+			"skipped", skipped,
+		)
+
+		c.failures.WithLabelValues("workflow_cost").Inc()
+	}
+}
+
+// prune drops cached usages and failures of runs which are not part of the
+// window anymore.
+func (c *WorkflowCostCollector) prune(seen map[usageKey]bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for key := range c.usages {
+		if !seen[key] {
+			delete(c.usages, key)
+		}
+	}
+
+	for key := range c.failed {
+		if !seen[key] {
+			delete(c.failed, key)
+		}
+	}
+}
+
+// unknownSKU warns once per SKU without a configured rate, the minutes of
+// these SKUs are estimated without any cost.
+func (c *WorkflowCostCollector) unknownSKU(sku string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.warned[sku] {
+		return
+	}
+
+	c.warned[sku] = true
+
+	c.logger.Warn("Missing workflow cost rate, estimating without cost",
+		"sku", sku,
+	)
+}
+
+// costs calculates the billable minutes and estimated cost per SKU. GitHub
+// rounds up the billable time of every job to the next full minute.
+func (c *WorkflowCostCollector) costs(usage *github.WorkflowRunUsage, labelsByJob map[int64]string) map[string]*runCost {
+	result := make(map[string]*runCost)
+
+	if usage.GetBillable() == nil {
+		return result
+	}
+
+	for environment, bill := range *usage.GetBillable() {
+		if bill == nil {
+			continue
+		}
+
+		if len(bill.JobRuns) == 0 {
+			sku := c.sku(environment, "")
+
+			if _, ok := result[sku]; !ok {
+				result[sku] = &runCost{}
+			}
+
+			minutes := billableMinutes(bill.GetTotalMS())
+
+			result[sku].add(&runCost{
+				Minutes: minutes,
+				Amount:  minutes * c.rates[sku],
+			})
+
+			continue
+		}
+
+		for _, job := range bill.JobRuns {
+			if job == nil {
+				continue
+			}
+
+			sku := c.sku(environment, labelsByJob[int64(job.GetJobID())])
+
+			if _, ok := result[sku]; !ok {
+				result[sku] = &runCost{}
+			}
+
+			minutes := billableMinutes(job.GetDurationMS())
+
+			result[sku].add(&runCost{
+				Minutes: minutes,
+				Amount:  minutes * c.rates[sku],
+			})
+		}
+	}
+
+	return result
+}
+
+// sku resolves the SKU of a job, runner labels with a configured rate take
+// precedence over the runner environment reported by GitHub.
+func (c *WorkflowCostCollector) sku(environment, labels string) string {
+	for _, label := range strings.Split(labels, ",") {
+		label = strings.TrimSpace(label)
+
+		if _, ok := c.rates[label]; ok && label != "" {
+			return label
+		}
+	}
+
+	switch environment {
+	case "UBUNTU":
+		return "linux"
+	case "MACOS":
+		return "macos"
+	case "WINDOWS":
+		return "windows"
+	}
+
+	return strings.ToLower(environment)
+}
+
+// team resolves the team for a repository based on the configured mappings,
+// repositories without matching mapping fall back to the unassigned team.
+func (c *WorkflowCostCollector) team(owner, repo string) string {
+	name := owner + "/" + repo
+
+	for _, mapping := range c.teams {
+		if glob.Glob(mapping.Pattern, name) {
+			return mapping.Team
+		}
+	}
+
+	return workflowCostUnassigned
+}
+
+type usageKey struct {
+	Identifier int64
+	Attempt    int
+}
+
+func usageKeyOf(record *store.WorkflowRun) usageKey {
+	return usageKey{
+		Identifier: record.Identifier,
+		Attempt:    record.Attempt,
+	}
+}
+
+type runCost struct {
+	Minutes float64
+	Amount  float64
+}
+
+func (r *runCost) add(cost *runCost) {
+	r.Minutes += cost.Minutes
+	r.Amount += cost.Amount
+}
+
+type teamMapping struct {
+	Pattern string
+	Team    string
+}
+
+func parseRates(logger *slog.Logger, rates []string) map[string]float64 {
+	result := make(map[string]float64, len(rates))
+
+	for _, rate := range rates {
+		sku, price, ok := strings.Cut(rate, "=")
+
+		if !ok {
+			logger.Warn("Invalid workflow cost rate",
+				"rate", rate,
+			)
+
+			continue
+		}
+
+		val, err := strconv.ParseFloat(strings.TrimSpace(price), 64)
+
+		if err != nil {
+			logger.Warn("Invalid workflow cost rate",
+				"rate", rate,
+				"err", err,
+			)
+
+			continue
+		}
+
+		result[strings.TrimSpace(sku)] = val
+	}
+
+	return result
+}
+
+func parseTeams(logger *slog.Logger, teams []string) []teamMapping {
+	result := make([]teamMapping, 0, len(teams))
+
+	for _, team := range teams {
+		pattern, name, ok := strings.Cut(team, "=")
+
+		if !ok {
+			logger.Warn("Invalid workflow cost team",
+				"team", team,
+			)
+
+			continue
+		}
+
+		result = append(result, teamMapping{
+			Pattern: strings.TrimSpace(pattern),
+			Team:    strings.TrimSpace(name),
+		})
+	}
+
+	return result
+}
+
+func billableMinutes(ms int64) float64 {
+	return math.Ceil(float64(ms) / float64(time.Minute/time.Millisecond))
+}
+
+func runCompleted(status string) bool {
+	switch status {
+	case "", "queued", "in_progress", "requested", "waiting", "pending":
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,178 @@
+package exporter
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/promhippie/github_exporter/pkg/config"
+	"github.com/promhippie/github_exporter/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowCostCollectorCosts(t *testing.T) {
+	mockLogger := slog.New(
+		slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+			Level: slog.LevelDebug,
+		}),
+	)
+
+	mockConfig := config.Target{
+		WorkflowCosts: config.WorkflowCosts{
+			Rates: append(config.CostRates(), "ubuntu-latest-8-core=0.032", "invalid"),
+			Teams: []string{"promhippie/github_*=platform"},
+		},
+	}
+
+	collector := NewWorkflowCostCollector(mockLogger, nil, nil, nil, nil, mockConfig)
+
+	usage := &github.WorkflowRunUsage{
+		Billable: &github.WorkflowRunBillMap{
+			"UBUNTU": &github.WorkflowRunBill{
+				TotalMS: github.Ptr(int64(150000)),
+				JobRuns: []*github.WorkflowRunJobRun{
+					{
+						JobID:      github.Ptr(1),
+						DurationMS: github.Ptr(int64(61000)),
+					},
+					{
+						JobID:      github.Ptr(2),
+						DurationMS: github.Ptr(int64(89000)),
+					},
+				},
+			},
+			"MACOS": &github.WorkflowRunBill{
+				TotalMS: github.Ptr(int64(30000)),
+			},
+		},
+	}
+
+	costs := collector.costs(usage, map[int64]string{
+		2: "ubuntu-latest-8-core",
+	})
+
+	expected := map[string]runCost{
+		"linux":                {Minutes: 2, Amount: 0.016},
+		"ubuntu-latest-8-core": {Minutes: 2, Amount: 0.064},
+		"macos":                {Minutes: 1, Amount: 0.08},
+	}
+
+	if len(costs) != len(expected) {
+		t.Fatalf("Expected %d skus, got %d", len(expected), len(costs))
+	}
+
+	for sku, want := range expected {
+		got, ok := costs[sku]
+
+		if !ok {
+			t.Fatalf("Expected sku %s to be present", sku)
+		}
+
+		if got.Minutes != want.Minutes {
+			t.Errorf("Expected %s minutes to be %v, got %v", sku, want.Minutes, got.Minutes)
+		}
+
+		if diff := got.Amount - want.Amount; diff > 0.000001 || diff < -0.000001 {
+			t.Errorf("Expected %s amount to be %v, got %v", sku, want.Amount, got.Amount)
+		}
+	}
+
+	if team := collector.team("promhippie", "github_exporter"); team != "platform" {
+		t.Errorf("Expected team to be platform, got %s", team)
+	}
+
+	if team := collector.team("promhippie", "prometheus-scw-sd"); team != workflowCostUnassigned {
+		t.Errorf("Expected team to be unassigned, got %s", team)
+	}
+}
+
+func TestWorkflowCostCollectorLookups(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/repos/promhippie/github_exporter/actions/runs/1/timing":
+			_, _ = w.Write([]byte(`{"billable":{"UBUNTU":{"total_ms":60000}}}`))
+		case "/repos/promhippie/github_exporter/actions/runs/3/timing":
+			_, _ = w.Write([]byte(`{"billable":{"FREEBSD":{"total_ms":60000}}}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+
+	defer server.Close()
+
+	url := server.URL + "/"
+	client, err := github.NewClient(github.WithURLs(&url, &url))
+	require.NoError(t, err)
+
+	db, err := store.New("memory://", slog.Default())
+	require.NoError(t, err)
+
+	runs := make([]*store.WorkflowRun, 0)
+
+	for id := range 3 {
+		runs = append(runs, &store.WorkflowRun{
+			Owner:      "promhippie",
+			Repo:       "github_exporter",
+			WorkflowID: 1,
+			Number:     id + 1,
+			Identifier: int64(id + 1),
+			Status:     "success",
+			UpdatedAt:  time.Now().Add(time.Duration(id) * time.Second).Unix(),
+		})
+	}
+
+	require.NoError(t, db.ImportWorkflowRuns(runs))
+
+	failures := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "failures"}, []string{"collector"})
+
+	collector := NewWorkflowCostCollector(
+		slog.Default(),
+		client,
+		db,
+		failures,
+		prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "duration"}, []string{"collector"}),
+		config.Target{
+			Timeout:     time.Second,
+			Concurrency: 2,
+			WorkflowRuns: config.WorkflowRuns{
+				Window: time.Hour,
+				Labels: []string{"number"},
+			},
+			WorkflowCosts: config.WorkflowCosts{
+				Rates:      config.CostRates(),
+				MaxLookups: 2,
+				FailureTTL: time.Hour,
+			},
+		},
+	)
+
+	testutil.CollectAndCount(collector)
+	assert.Equal(t, int32(2), requests.Load())
+	assert.Equal(t, 1.0, testutil.ToFloat64(failures.WithLabelValues("workflow_cost")))
+
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP github_workflow_cost_unknown_sku_runs Number of completed workflow runs with billable minutes on a SKU without rate
+# TYPE github_workflow_cost_unknown_sku_runs gauge
+github_workflow_cost_unknown_sku_runs{sku="freebsd"} 1
+`), "github_workflow_cost_unknown_sku_runs"))
+
+	assert.Equal(t, int32(3), requests.Load())
+	assert.Equal(t, 1.0, testutil.ToFloat64(failures.WithLabelValues("workflow_cost")))
+
+	testutil.CollectAndCount(collector)
+	assert.Equal(t, int32(3), requests.Load())
+}
@@ -1,6 +1,7 @@
 package exporter
 
 import (
+	"errors"
 	"log/slog"
 	"time"
 
@@ -24,6 +25,9 @@ type WorkflowJobCollector struct {
 	Creation *prometheus.Desc
 	Created  *prometheus.Desc
 	Started  *prometheus.Desc
+
+	SlowestLeg *prometheus.Desc
+	FailingLeg *prometheus.Desc
 }
 
 // NewWorkflowJobCollector returns a new WorkflowCollector.
@@ -71,6 +75,18 @@ func NewWorkflowJobCollector(logger *slog.Logger, client *github.Client, db stor
 			labels,
 			nil,
 		),
+		SlowestLeg: prometheus.NewDesc(
+			"github_workflow_job_slowest_leg_duration_ms",
+			"Average duration of the slowest matrix leg per logical job",
+			[]string{"owner", "repo", "workflow_name", "caller_job", "logical_job", "matrix"},
+			nil,
+		),
+		FailingLeg: prometheus.NewDesc(
+			"github_workflow_job_failing_leg_ratio",
+			"Failure ratio of the most failure-prone matrix leg per logical job",
+			[]string{"owner", "repo", "workflow_name", "caller_job", "logical_job", "matrix"},
+			nil,
+		),
 	}
 }
 
@@ -82,6 +98,8 @@ func (c *WorkflowJobCollector) Metrics() []*prometheus.Desc {
 		c.Creation,
 		c.Created,
 		c.Started,
+		c.SlowestLeg,
+		c.FailingLeg,
 	}
 }
 
@@ -92,23 +110,36 @@ func (c *WorkflowJobCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.Creation
 	ch <- c.Created
 	ch <- c.Started
+	ch <- c.SlowestLeg
+	ch <- c.FailingLeg
 }
 
 // Collect is called by the Prometheus registry when collecting metrics.
 func (c *WorkflowJobCollector) Collect(ch chan<- prometheus.Metric) {
-	if err := c.db.PruneWorkflowJobs(
-		c.config.WorkflowJobs.PurgeWindow,
-	); err != nil {
-		c.logger.Error("Failed to prune workflow jobs",
-			"err", err,
+	now := time.Now()
+	count := 0
+
+	err := c.db.WalkWorkflowJobs(c.config.WorkflowJobs.Window, c.config.WorkflowJobs.MaxRows, func(record *store.WorkflowJob) error {
+		count++
+
+		c.logger.Debug("Collecting workflow job",
+			"owner", record.Owner,
+			"repo", record.Repo,
+			"id", record.Identifier,
+			"run_id", record.RunID,
 		)
-	}
 
-	now := time.Now()
-	records, err := c.db.GetWorkflowJobs(c.config.WorkflowJobs.Window)
+		c.collect(ch, record)
+		return nil
+	})
+
 	c.duration.WithLabelValues("workflow_job").Observe(time.Since(now).Seconds())
 
-	if err != nil {
+	if errors.Is(err, store.ErrLimitReached) {
+		c.logger.Warn("Reached maximum number of workflow jobs, skipping older records",
+			"max_rows", c.config.WorkflowJobs.MaxRows,
+		)
+	} else if err != nil {
 		c.logger.Error("Failed to fetch workflow jobs",
 			"err", err,
 		)
@@ -118,64 +149,136 @@ func (c *WorkflowJobCollector) Collect(ch chan<- prometheus.Metric) {
 	}
 
 	c.logger.Debug("Fetched workflow jobs",
-		"count", len(records),
+		"count", count,
 		"duration", time.Since(now),
 	)
 
-	for _, record := range records {
-		c.logger.Debug("Collecting workflow job",
-			"owner", record.Owner,
-			"repo", record.Repo,
-			"id", record.Identifier,
-			"run_id", record.RunID,
+	c.collectLegs(ch)
+}
+
+// collect sends the metrics for a single workflow job.
+func (c *WorkflowJobCollector) collect(ch chan<- prometheus.Metric, record *store.WorkflowJob) {
+	labels := []string{}
+
+	for _, label := range c.config.WorkflowJobs.Labels {
+		labels = append(
+			labels,
+			record.ByLabel(label),
 		)
+	}
 
-		labels := []string{}
+	ch <- prometheus.MustNewConstMetric(
+		c.Status,
+		prometheus.GaugeValue,
+		jobStatusToGauge(record.Status),
+		labels...,
+	)
 
-		for _, label := range c.config.WorkflowJobs.Labels {
-			labels = append(
-				labels,
-				record.ByLabel(label),
-			)
-		}
+	ch <- prometheus.MustNewConstMetric(
+		c.Duration,
+		prometheus.GaugeValue,
+		float64((record.CompletedAt-record.StartedAt)*1000),
+		labels...,
+	)
 
-		ch <- prometheus.MustNewConstMetric(
-			c.Status,
-			prometheus.GaugeValue,
-			jobStatusToGauge(record.Status),
-			labels...,
-		)
+	ch <- prometheus.MustNewConstMetric(
+		c.Creation,
+		prometheus.GaugeValue,
+		time.Since(time.Unix(record.StartedAt, 0)).Minutes(),
+		labels...,
+	)
 
-		ch <- prometheus.MustNewConstMetric(
-			c.Duration,
-			prometheus.GaugeValue,
-			float64((record.CompletedAt-record.StartedAt)*1000),
-			labels...,
-		)
+	ch <- prometheus.MustNewConstMetric(
+		c.Created,
+		prometheus.GaugeValue,
+		float64(record.CreatedAt),
+		labels...,
+	)
 
-		ch <- prometheus.MustNewConstMetric(
-			c.Creation,
-			prometheus.GaugeValue,
-			time.Since(time.Unix(record.StartedAt, 0)).Minutes(),
-			labels...,
+	ch <- prometheus.MustNewConstMetric(
+		c.Started,
+		prometheus.GaugeValue,
+		float64(record.StartedAt),
+		labels...,
+	)
+}
+
+// collectLegs exposes the slowest and most failure-prone matrix leg per
+// logical job, jobs without a matrix are skipped.
+func (c *WorkflowJobCollector) collectLegs(ch chan<- prometheus.Metric) {
+	now := time.Now()
+	stats, err := c.db.GetWorkflowJobStats(c.config.WorkflowJobs.Window)
+	c.duration.WithLabelValues("workflow_job").Observe(time.Since(now).Seconds())
+
+	if err != nil {
+		c.logger.Error("Failed to fetch workflow job stats",
+			"err", err,
 		)
 
+		c.failures.WithLabelValues("workflow_job").Inc()
+		return
+	}
+
+	slowest, failing := matrixLegs(stats)
+
+	for key, leg := range slowest {
 		ch <- prometheus.MustNewConstMetric(
-			c.Created,
+			c.SlowestLeg,
 			prometheus.GaugeValue,
-			float64(record.CreatedAt),
-			labels...,
+			leg.value,
+			append(key[:], leg.matrix)...,
 		)
+	}
 
+	for key, leg := range failing {
 		ch <- prometheus.MustNewConstMetric(
-			c.Started,
+			c.FailingLeg,
 			prometheus.GaugeValue,
-			float64(record.StartedAt),
-			labels...,
+			leg.value,
+			append(key[:], leg.matrix)...,
 		)
 	}
 }
 
+type matrixLeg struct {
+	matrix string
+	value  float64
+}
+
+// matrixLegs groups the job stats by logical job and picks the slowest leg
+// based on the average duration in milliseconds and the leg with the highest
+// failure ratio.
+func matrixLegs(stats []*store.WorkflowJobStats) (map[[5]string]matrixLeg, map[[5]string]matrixLeg) {
+	slowest := make(map[[5]string]matrixLeg)
+	failing := make(map[[5]string]matrixLeg)
+
+	for _, stat := range stats {
+		if stat.Total == 0 {
+			continue
+		}
+
+		caller, logical, matrix := store.SplitJobName(stat.Name)
+
+		if matrix == "" {
+			continue
+		}
+
+		key := [5]string{stat.Owner, stat.Repo, stat.WorkflowName, caller, logical}
+		duration := float64(stat.Duration*1000) / float64(stat.Total)
+		ratio := float64(stat.Failures) / float64(stat.Total)
+
+		if leg, ok := slowest[key]; !ok || duration > leg.value || (duration == leg.value && matrix < leg.matrix) {
+			slowest[key] = matrixLeg{matrix: matrix, value: duration}
+		}
+
+		if leg, ok := failing[key]; !ok || ratio > leg.value || (ratio == leg.value && matrix < leg.matrix) {
+			failing[key] = matrixLeg{matrix: matrix, value: ratio}
+		}
+	}
+
+	return slowest, failing
+}
+
 func jobStatusToGauge(conclusion string) float64 {
 	switch conclusion {
 	case "queued":
@@ -1,12 +1,10 @@
 package exporter
 
 import (
-	"fmt"
 	"log/slog"
 	"os"
 	"reflect"
 	"testing"
-	"time"
 
 	"github.com/google/go-github/v90/github"
 	"github.com/prometheus/client_golang/prometheus"
@@ -14,60 +12,6 @@ import (
 	"github.com/promhippie/github_exporter/pkg/store"
 )
 
-type StaticStore struct{}
-
-func (s StaticStore) GetWorkflowJobRuns(owner, repo, workflow string) ([]*store.WorkflowRun, error) {
-	_, _ = fmt.Fprintf(
-		os.Stdout,
-		"GetWorkflowJobRuns for %s/%s %s \n",
-		owner,
-		repo,
-		workflow,
-	)
-
-	return nil, nil
-}
-
-func (s StaticStore) StoreWorkflowRunEvent(*github.WorkflowRunEvent) error {
-	return nil
-}
-
-func (s StaticStore) GetWorkflowRuns(time.Duration) ([]*store.WorkflowRun, error) {
-	return nil, nil
-}
-
-func (s StaticStore) PruneWorkflowRuns(time.Duration) error {
-	return nil
-}
-
-func (s StaticStore) StoreWorkflowJobEvent(*github.WorkflowJobEvent) error {
-	return nil
-}
-
-func (s StaticStore) GetWorkflowJobs(time.Duration) ([]*store.WorkflowJob, error) {
-	return nil, nil
-}
-
-func (s StaticStore) PruneWorkflowJobs(time.Duration) error {
-	return nil
-}
-
-func (s StaticStore) Open() (bool, error) {
-	return true, nil
-}
-
-func (s StaticStore) Close() error {
-	return nil
-}
-
-func (s StaticStore) Ping() (bool, error) {
-	return true, nil
-}
-
-func (s StaticStore) Migrate() error {
-	return nil
-}
-
 func TestWorkflowJobCollector(t *testing.T) {
 	mockClient := &github.Client{}
 
@@ -77,7 +21,11 @@ func TestWorkflowJobCollector(t *testing.T) {
 		}),
 	)
 
-	mockStore := StaticStore{}
+	mockStore, err := store.New("memory://", mockLogger)
+
+	if err != nil {
+		t.Fatalf("Failed to create store: %s", err)
+	}
 
 	mockFailures := prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: "test_failures_total",
@@ -139,3 +87,26 @@ func TestWorkflowJobCollector(t *testing.T) {
 		t.Errorf("Expected config to be %v, got %v", mockConfig, collector.config)
 	}
 }
+
+func TestWorkflowJobMatrixLegs(t *testing.T) {
+	stats := []*store.WorkflowJobStats{
+		{Owner: "promhippie", Repo: "github_exporter", WorkflowName: "ci", Name: "build / test (linux, 1.22)", Total: 4, Failures: 1, Duration: 400},
+		{Owner: "promhippie", Repo: "github_exporter", WorkflowName: "ci", Name: "build / test (macos, 1.22)", Total: 2, Failures: 0, Duration: 600},
+		{Owner: "promhippie", Repo: "github_exporter", WorkflowName: "ci", Name: "lint", Total: 3, Failures: 3, Duration: 900},
+	}
+
+	slowest, failing := matrixLegs(stats)
+	key := [5]string{"promhippie", "github_exporter", "ci", "build", "test"}
+
+	if len(slowest) != 1 || len(failing) != 1 {
+		t.Fatalf("Expected a single logical job, got %d and %d", len(slowest), len(failing))
+	}
+
+	if leg := slowest[key]; leg.matrix != "macos, 1.22" || leg.value != 300000 {
+		t.Errorf("Expected slowest leg to be macos with 300000ms, got %v", leg)
+	}
+
+	if leg := failing[key]; leg.matrix != "linux, 1.22" || leg.value != 0.25 {
+		t.Errorf("Expected failing leg to be linux with 0.25, got %v", leg)
+	}
+}
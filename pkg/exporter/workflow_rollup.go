@@ -0,0 +1,178 @@
+package exporter
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/promhippie/github_exporter/pkg/config"
+	"github.com/promhippie/github_exporter/pkg/store"
+)
+
+// rollupWindow defines a window exposed by the WorkflowRollupCollector.
+type rollupWindow struct {
+	name     string
+	duration time.Duration
+}
+
+var (
+	rollupWindows = []rollupWindow{
+		{"1d", 24 * time.Hour},
+		{"7d", 7 * 24 * time.Hour},
+		{"30d", 30 * 24 * time.Hour},
+	}
+)
+
+// rollupKey defines the labels of a workflow aggregated from rollups.
+type rollupKey struct {
+	owner      string
+	repo       string
+	workflowID int64
+	window     string
+}
+
+// WorkflowRollupCollector collects long-window metrics about workflow runs
+// based on the hourly rollups.
+type WorkflowRollupCollector struct {
+	client   *github.Client
+	logger   *slog.Logger
+	db       store.Store
+	failures *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	config   config.Target
+
+	SuccessRatio *prometheus.Desc
+	MeanDuration *prometheus.Desc
+}
+
+// NewWorkflowRollupCollector returns a new WorkflowRollupCollector.
+func NewWorkflowRollupCollector(logger *slog.Logger, client *github.Client, db store.Store, failures *prometheus.CounterVec, duration *prometheus.HistogramVec, cfg config.Target) *WorkflowRollupCollector {
+	if failures != nil {
+		failures.WithLabelValues("workflow_rollup").Add(0)
+	}
+
+	labels := []string{"owner", "repo", "workflow", "name", "window"}
+	return &WorkflowRollupCollector{
+		client:   client,
+		logger:   logger.With("collector", "workflow_rollup"),
+		db:       db,
+		failures: failures,
+		duration: duration,
+		config:   cfg,
+
+		SuccessRatio: prometheus.NewDesc(
+			"github_workflow_run_success_ratio",
+			"Ratio of successful to successful and failed workflow runs within the window",
+			labels,
+			nil,
+		),
+		MeanDuration: prometheus.NewDesc(
+			"github_workflow_run_mean_duration_seconds",
+			"Mean duration of finished workflow runs within the window",
+			labels,
+			nil,
+		),
+	}
+}
+
+// Metrics simply returns the list metric descriptors for generating a documentation.
+func (c *WorkflowRollupCollector) Metrics() []*prometheus.Desc {
+	return []*prometheus.Desc{
+		c.SuccessRatio,
+		c.MeanDuration,
+	}
+}
+
+// Describe sends the super-set of all possible descriptors of metrics collected by this Collector.
+func (c *WorkflowRollupCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.SuccessRatio
+	ch <- c.MeanDuration
+}
+
+// Collect is called by the Prometheus registry when collecting metrics.
+func (c *WorkflowRollupCollector) Collect(ch chan<- prometheus.Metric) {
+	now := time.Now()
+	records, err := c.db.GetWorkflowRunRollups(rollupWindows[len(rollupWindows)-1].duration)
+	c.duration.WithLabelValues("workflow_rollup").Observe(time.Since(now).Seconds())
+
+	if err != nil {
+		c.logger.Error("Failed to fetch workflow run rollups",
+			"err", err,
+		)
+
+		c.failures.WithLabelValues("workflow_rollup").Inc()
+		return
+	}
+
+	c.logger.Debug("Fetched workflow run rollups",
+		"count", len(records),
+		"duration", time.Since(now),
+	)
+
+	keys, sums := aggregateRollups(records, now)
+
+	for _, key := range keys {
+		sum := sums[key]
+		labels := []string{
+			key.owner,
+			key.repo,
+			strconv.FormatInt(key.workflowID, 10),
+			sum.Name,
+			key.window,
+		}
+
+		if sum.Success+sum.Failure > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				c.SuccessRatio,
+				prometheus.GaugeValue,
+				float64(sum.Success)/float64(sum.Success+sum.Failure),
+				labels...,
+			)
+		}
+
+		if sum.Total > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				c.MeanDuration,
+				prometheus.GaugeValue,
+				float64(sum.Duration)/float64(sum.Total),
+				labels...,
+			)
+		}
+	}
+}
+
+// aggregateRollups sums up the rollups per workflow for every window, the
+// name is taken from the latest rollup as records are ordered by the hour.
+func aggregateRollups(records []*store.WorkflowRunRollup, now time.Time) ([]rollupKey, map[rollupKey]*store.WorkflowRunRollup) {
+	keys := make([]rollupKey, 0)
+	sums := make(map[rollupKey]*store.WorkflowRunRollup)
+
+	for _, window := range rollupWindows {
+		border := now.Add(-window.duration).Unix()
+
+		for _, record := range records {
+			if record.Bucket <= border {
+				continue
+			}
+
+			key := rollupKey{record.Owner, record.Repo, record.WorkflowID, window.name}
+			sum, ok := sums[key]
+
+			if !ok {
+				sum = &store.WorkflowRunRollup{}
+				sums[key] = sum
+				keys = append(keys, key)
+			}
+
+			sum.Name = record.Name
+			sum.Total += record.Total
+			sum.Success += record.Success
+			sum.Failure += record.Failure
+			sum.Duration += record.Duration
+		}
+	}
+
+	return keys, sums
+}
@@ -0,0 +1,35 @@
+package exporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/promhippie/github_exporter/pkg/store"
+)
+
+func TestWorkflowRollupAggregate(t *testing.T) {
+	now := time.Now()
+
+	records := []*store.WorkflowRunRollup{
+		{Owner: "promhippie", Repo: "github_exporter", WorkflowID: 1, Name: "ci", Bucket: now.Add(-72 * time.Hour).Unix(), Total: 4, Success: 1, Failure: 3, Duration: 400},
+		{Owner: "promhippie", Repo: "github_exporter", WorkflowID: 1, Name: "build", Bucket: now.Add(-time.Hour).Unix(), Total: 2, Success: 2, Failure: 0, Duration: 200},
+	}
+
+	keys, sums := aggregateRollups(records, now)
+
+	if len(keys) != 3 {
+		t.Fatalf("Expected 3 windows, got %d", len(keys))
+	}
+
+	daily := sums[rollupKey{"promhippie", "github_exporter", 1, "1d"}]
+
+	if daily.Total != 2 || daily.Success != 2 || daily.Duration != 200 {
+		t.Errorf("Expected daily window to contain the latest rollup only, got %v", daily)
+	}
+
+	weekly := sums[rollupKey{"promhippie", "github_exporter", 1, "7d"}]
+
+	if weekly.Total != 6 || weekly.Failure != 3 || weekly.Name != "build" {
+		t.Errorf("Expected weekly window to contain both rollups, got %v", weekly)
+	}
+}
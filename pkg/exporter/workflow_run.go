@@ -1,6 +1,7 @@
 package exporter
 
 import (
+	"errors"
 	"log/slog"
 	"time"
 
@@ -105,19 +106,30 @@ func (c *WorkflowRunCollector) Describe(ch chan<- *prometheus.Desc) {
 
 // Collect is called by the Prometheus registry when collecting metrics.
 func (c *WorkflowRunCollector) Collect(ch chan<- prometheus.Metric) {
-	if err := c.db.PruneWorkflowRuns(
-		c.config.WorkflowRuns.PurgeWindow,
-	); err != nil {
-		c.logger.Error("Failed to prune workflows",
-			"err", err,
+	now := time.Now()
+	count := 0
+
+	err := c.db.WalkWorkflowRuns(c.config.WorkflowRuns.Window, c.config.WorkflowRuns.MaxRows, func(record *store.WorkflowRun) error {
+		count++
+
+		c.logger.Debug("Collecting workflow run",
+			"owner", record.Owner,
+			"repo", record.Repo,
+			"workflow", record.WorkflowID,
+			"number", record.Number,
 		)
-	}
 
-	now := time.Now()
-	records, err := c.db.GetWorkflowRuns(c.config.WorkflowRuns.Window)
+		c.collect(ch, record)
+		return nil
+	})
+
 	c.duration.WithLabelValues("workflow_run").Observe(time.Since(now).Seconds())
 
-	if err != nil {
+	if errors.Is(err, store.ErrLimitReached) {
+		c.logger.Warn("Reached maximum number of workflow runs, skipping older records",
+			"max_rows", c.config.WorkflowRuns.MaxRows,
+		)
+	} else if err != nil {
 		c.logger.Error("Failed to fetch workflow runs",
 			"err", err,
 		)
@@ -127,69 +139,63 @@ func (c *WorkflowRunCollector) Collect(ch chan<- prometheus.Metric) {
 	}
 
 	c.logger.Debug("Fetched workflow runs",
-		"count", len(records),
+		"count", count,
 		"duration", time.Since(now),
 	)
+}
 
-	for _, record := range records {
-		c.logger.Debug("Collecting workflow run",
-			"owner", record.Owner,
-			"repo", record.Repo,
-			"workflow", record.WorkflowID,
-			"number", record.Number,
-		)
-
-		labels := []string{}
-
-		for _, label := range c.config.WorkflowRuns.Labels {
-			labels = append(
-				labels,
-				record.ByLabel(label),
-			)
-		}
+// collect sends the metrics for a single workflow run.
+func (c *WorkflowRunCollector) collect(ch chan<- prometheus.Metric, record *store.WorkflowRun) {
+	labels := []string{}
 
-		ch <- prometheus.MustNewConstMetric(
-			c.Status,
-			prometheus.GaugeValue,
-			statusToGauge(record.Status),
-			labels...,
+	for _, label := range c.config.WorkflowRuns.Labels {
+		labels = append(
+			labels,
+			record.ByLabel(label),
 		)
+	}
 
-		ch <- prometheus.MustNewConstMetric(
-			c.Duration,
-			prometheus.GaugeValue,
-			float64((record.UpdatedAt-record.StartedAt)*1000),
-			labels...,
-		)
+	ch <- prometheus.MustNewConstMetric(
+		c.Status,
+		prometheus.GaugeValue,
+		statusToGauge(record.Status),
+		labels...,
+	)
 
-		ch <- prometheus.MustNewConstMetric(
-			c.Creation,
-			prometheus.GaugeValue,
-			time.Since(time.Unix(record.StartedAt, 0)).Minutes(),
-			labels...,
-		)
+	ch <- prometheus.MustNewConstMetric(
+		c.Duration,
+		prometheus.GaugeValue,
+		float64((record.UpdatedAt-record.StartedAt)*1000),
+		labels...,
+	)
 
-		ch <- prometheus.MustNewConstMetric(
-			c.Created,
-			prometheus.GaugeValue,
-			float64(record.CreatedAt),
-			labels...,
-		)
+	ch <- prometheus.MustNewConstMetric(
+		c.Creation,
+		prometheus.GaugeValue,
+		time.Since(time.Unix(record.StartedAt, 0)).Minutes(),
+		labels...,
+	)
 
-		ch <- prometheus.MustNewConstMetric(
-			c.Updated,
-			prometheus.GaugeValue,
-			float64(record.UpdatedAt),
-			labels...,
-		)
+	ch <- prometheus.MustNewConstMetric(
+		c.Created,
+		prometheus.GaugeValue,
+		float64(record.CreatedAt),
+		labels...,
+	)
 
-		ch <- prometheus.MustNewConstMetric(
-			c.Started,
-			prometheus.GaugeValue,
-			float64(record.StartedAt),
-			labels...,
-		)
-	}
+	ch <- prometheus.MustNewConstMetric(
+		c.Updated,
+		prometheus.GaugeValue,
+		float64(record.UpdatedAt),
+		labels...,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.Started,
+		prometheus.GaugeValue,
+		float64(record.StartedAt),
+		labels...,
+	)
 }
 
 func statusToGauge(conclusion string) float64 {
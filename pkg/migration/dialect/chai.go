@@ -40,3 +40,14 @@ func (g ChaiDialect) AllSQL() string {
 	ORDER BY
 		version ASC;`
 }
+
+// ExistsSQL returns a query to count the schema tables, it's used to detect
+// if the schema table exists without creating it.
+func (g ChaiDialect) ExistsSQL() string {
+	return `SELECT
+		COUNT(*)
+	FROM
+		__chai_catalog
+	WHERE
+		type = 'table' AND name = 'darwin_migrations';`
+}
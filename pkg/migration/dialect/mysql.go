@@ -43,3 +43,14 @@ func (m MySQLDialect) AllSQL() string {
 	ORDER BY
 		version ASC;`
 }
+
+// ExistsSQL returns a query to count the schema tables, it's used to detect
+// if the schema table exists without creating it.
+func (m MySQLDialect) ExistsSQL() string {
+	return `SELECT
+		COUNT(*)
+	FROM
+		information_schema.tables
+	WHERE
+		table_schema = DATABASE() AND table_name = 'darwin_migrations';`
+}
@@ -43,3 +43,14 @@ func (p PostgresDialect) AllSQL() string {
 	ORDER BY
 		version ASC;`
 }
+
+// ExistsSQL returns a query to count the schema tables, it's used to detect
+// if the schema table exists without creating it.
+func (p PostgresDialect) ExistsSQL() string {
+	return `SELECT
+		COUNT(*)
+	FROM
+		information_schema.tables
+	WHERE
+		table_schema = current_schema() AND table_name = 'darwin_migrations';`
+}
@@ -42,3 +42,14 @@ func (s SqliteDialect) AllSQL() string {
 	ORDER BY
 		version ASC;`
 }
+
+// ExistsSQL returns a query to count the schema tables, it's used to detect
+// if the schema table exists without creating it.
+func (s SqliteDialect) ExistsSQL() string {
+	return `SELECT
+		COUNT(*)
+	FROM
+		sqlite_master
+	WHERE
+		type = 'table' AND name = 'darwin_migrations';`
+}
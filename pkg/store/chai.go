@@ -3,10 +3,13 @@
 package store
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/url"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/GuiaBolso/darwin"
@@ -73,9 +76,138 @@ var (
 				PRIMARY KEY(owner, repo, identifier)
 			);`,
 		},
+		{
+			Version:     4,
+			Description: "Creating table deployment_reviews",
+			Script: `CREATE TABLE deployment_reviews (
+				owner TEXT NOT NULL,
+				repo TEXT NOT NULL,
+				run_id INTEGER NOT NULL,
+				environment TEXT NOT NULL,
+				status TEXT,
+				approver TEXT,
+				requested_at INTEGER,
+				reviewed_at INTEGER,
+				PRIMARY KEY(owner, repo, run_id, environment)
+			);`,
+		},
+		{
+			Version:     5,
+			Description: "Creating table workflow_run_rollups",
+			Script: `CREATE TABLE workflow_run_rollups (
+				owner TEXT NOT NULL,
+				repo TEXT NOT NULL,
+				workflow_id INTEGER NOT NULL,
+				name TEXT,
+				bucket INTEGER NOT NULL,
+				total INTEGER,
+				success INTEGER,
+				failure INTEGER,
+				duration INTEGER,
+				PRIMARY KEY(owner, repo, workflow_id, bucket)
+			);`,
+		},
+		{
+			Version:     6,
+			Description: "Creating table leases",
+			Script: `CREATE TABLE leases (
+				name TEXT NOT NULL,
+				holder TEXT NOT NULL,
+				expires_at INTEGER NOT NULL,
+				PRIMARY KEY(name)
+			);`,
+		},
+		{
+			Version:     7,
+			Description: "Creating table http_cache",
+			Script: `CREATE TABLE http_cache (
+				cache_key TEXT NOT NULL,
+				etag TEXT NOT NULL,
+				last_modified TEXT NOT NULL,
+				header TEXT NOT NULL,
+				body BYTEA NOT NULL,
+				updated_at INTEGER NOT NULL,
+				PRIMARY KEY(cache_key)
+			);`,
+		},
+		{
+			Version:     8,
+			Description: "Creating index for workflow_runs retention",
+			Script:      `CREATE INDEX workflow_runs_retention_idx ON workflow_runs (owner, repo, updated_at);`,
+		},
+		{
+			Version:     9,
+			Description: "Creating index for workflow_jobs retention",
+			Script:      `CREATE INDEX workflow_jobs_retention_idx ON workflow_jobs (owner, repo, created_at);`,
+		},
 	}
 )
 
+var (
+	// chaiSelectWorkflowJobStatsQuery returns a row per job as Chai is not
+	// able to group by multiple columns, the rows get merged afterwards.
+	chaiSelectWorkflowJobStatsQuery = `
+SELECT
+	owner,
+	repo,
+	workflow_name,
+	name,
+	conclusion,
+	1 AS total,
+	completed_at - started_at AS duration,
+	completed_at - started_at AS max_duration
+FROM
+	workflow_jobs
+WHERE
+	created_at > :window AND status = 'completed';`
+)
+
+var (
+	// chaiQueryWorkflowRunsOrder only orders by the timestamp as Chai is not
+	// able to order by multiple columns.
+	chaiQueryWorkflowRunsOrder = "updated_at DESC"
+
+	// chaiQueryWorkflowJobsOrder only orders by the timestamp as Chai is not
+	// able to order by multiple columns.
+	chaiQueryWorkflowJobsOrder = "created_at DESC"
+
+	// chaiListWorkflowRunsQuery relies on the primary key order as Chai is
+	// not able to order by multiple columns.
+	chaiListWorkflowRunsQuery = strings.Replace(
+		listWorkflowRunsQuery,
+		"ORDER BY\n\towner, repo, workflow_id, number\n",
+		"",
+		1,
+	)
+
+	// chaiListWorkflowJobsQuery relies on the primary key order as Chai is
+	// not able to order by multiple columns.
+	chaiListWorkflowJobsQuery = strings.Replace(
+		listWorkflowJobsQuery,
+		"ORDER BY\n\towner, repo, identifier\n",
+		"",
+		1,
+	)
+
+	// chaiListWorkflowRunRollupsQuery relies on the primary key order as Chai
+	// is not able to order by multiple columns.
+	chaiListWorkflowRunRollupsQuery = strings.Replace(
+		listWorkflowRunRollupsQuery,
+		"ORDER BY\n\towner, repo, workflow_id, bucket\n",
+		"",
+		1,
+	)
+
+	// chaiListDeploymentReviewsQuery relies on the primary key order as Chai
+	// is not able to order by multiple columns.
+	chaiListDeploymentReviewsQuery = strings.Replace(
+		listDeploymentReviewsQuery,
+		"ORDER BY\n\towner, repo, run_id, environment\n",
+		"",
+		1,
+	)
+)
+
 func init() {
 	register("chai", NewChaiStore)
 	register("genji", NewChaiStore)
@@ -136,9 +268,26 @@ func (s *chaiStore) Migrate() error {
 	return nil
 }
 
+// Migrations returns the status of all db migrations.
+func (s *chaiStore) Migrations() ([]*Migration, error) {
+	return migrationStatus(
+		s.handle.DB,
+		dialect.ChaiDialect{},
+		chaiMigrations,
+	)
+}
+
 // StoreWorkflowRunEvent implements the Store interface.
 func (s *chaiStore) StoreWorkflowRunEvent(event *github.WorkflowRunEvent) error {
-	return storeWorkflowRunEvent(s.handle, event)
+	record := workflowRunFromEvent(event)
+
+	if err := s.transaction(func(tx *sqlx.Tx) error {
+		return chaiWriteWorkflowRun(tx, record)
+	}); err != nil {
+		return err
+	}
+
+	return resolveWorkflowRunEvent(s.handle, event, record)
 }
 
 // GetWorkflowRuns implements the Store interface.
@@ -146,14 +295,51 @@ func (s *chaiStore) GetWorkflowRuns(window time.Duration) ([]*WorkflowRun, error
 	return getWorkflowRuns(s.handle, window)
 }
 
+// WalkWorkflowRuns implements the Store interface.
+func (s *chaiStore) WalkWorkflowRuns(window time.Duration, limit int, fn func(*WorkflowRun) error) error {
+	return walkWorkflowRuns(s.handle, walkWorkflowRunsQuery, window, limit, fn)
+}
+
 // PruneWorkflowRuns implements the Store interface.
-func (s *chaiStore) PruneWorkflowRuns(timeframe time.Duration) error {
-	return pruneWorkflowRuns(s.handle, timeframe)
+func (s *chaiStore) PruneWorkflowRuns(retention Retention, limit int) (map[string]int64, error) {
+	return pruneWorkflowRuns(s.handle, purgeWorkflowRunsQuery, purgeWorkflowRunsRepoQuery, retention, limit)
+}
+
+// CountWorkflowRuns implements the Store interface.
+func (s *chaiStore) CountWorkflowRuns() (int64, error) {
+	return countWorkflowRuns(s.handle)
+}
+
+// ListWorkflowRuns implements the Store interface.
+func (s *chaiStore) ListWorkflowRuns(offset, limit int) ([]*WorkflowRun, error) {
+	return listWorkflowRuns(s.handle, chaiListWorkflowRunsQuery, offset, limit)
+}
+
+// ImportWorkflowRuns implements the Store interface.
+func (s *chaiStore) ImportWorkflowRuns(records []*WorkflowRun) error {
+	return s.transaction(func(tx *sqlx.Tx) error {
+		for _, record := range records {
+			if err := chaiWriteWorkflowRun(tx, record); err != nil && !errors.Is(err, ErrOutdated) {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// QueryWorkflowRuns implements the Store interface.
+func (s *chaiStore) QueryWorkflowRuns(filter *Filter) ([]*WorkflowRun, error) {
+	return queryWorkflowRuns(s.handle, chaiQueryWorkflowRunsOrder, filter)
 }
 
 // StoreWorkflowJobEvent implements the Store interface.
 func (s *chaiStore) StoreWorkflowJobEvent(event *github.WorkflowJobEvent) error {
-	return storeWorkflowJobEvent(s.handle, event)
+	record := workflowJobFromEvent(event)
+
+	return s.transaction(func(tx *sqlx.Tx) error {
+		return chaiWriteWorkflowJob(tx, record)
+	})
 }
 
 // GetWorkflowJobs implements the Store interface.
@@ -161,9 +347,284 @@ func (s *chaiStore) GetWorkflowJobs(window time.Duration) ([]*WorkflowJob, error
 	return getWorkflowJobs(s.handle, window)
 }
 
+// WalkWorkflowJobs implements the Store interface.
+func (s *chaiStore) WalkWorkflowJobs(window time.Duration, limit int, fn func(*WorkflowJob) error) error {
+	return walkWorkflowJobs(s.handle, walkWorkflowJobsQuery, window, limit, fn)
+}
+
+// GetWorkflowJobStats implements the Store interface.
+func (s *chaiStore) GetWorkflowJobStats(window time.Duration) ([]*WorkflowJobStats, error) {
+	return getWorkflowJobStats(s.handle, chaiSelectWorkflowJobStatsQuery, window)
+}
+
 // PruneWorkflowJobs implements the Store interface.
-func (s *chaiStore) PruneWorkflowJobs(timeframe time.Duration) error {
-	return pruneWorkflowJobs(s.handle, timeframe)
+func (s *chaiStore) PruneWorkflowJobs(retention Retention, limit int) (map[string]int64, error) {
+	return pruneWorkflowJobs(s.handle, purgeWorkflowJobsQuery, purgeWorkflowJobsRepoQuery, retention, limit)
+}
+
+// CountWorkflowJobs implements the Store interface.
+func (s *chaiStore) CountWorkflowJobs() (int64, error) {
+	return countWorkflowJobs(s.handle)
+}
+
+// ListWorkflowJobs implements the Store interface.
+func (s *chaiStore) ListWorkflowJobs(offset, limit int) ([]*WorkflowJob, error) {
+	return listWorkflowJobs(s.handle, chaiListWorkflowJobsQuery, offset, limit)
+}
+
+// ImportWorkflowJobs implements the Store interface.
+func (s *chaiStore) ImportWorkflowJobs(records []*WorkflowJob) error {
+	return s.transaction(func(tx *sqlx.Tx) error {
+		for _, record := range records {
+			if err := chaiWriteWorkflowJob(tx, record); err != nil && !errors.Is(err, ErrOutdated) {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// QueryWorkflowJobs implements the Store interface.
+func (s *chaiStore) QueryWorkflowJobs(filter *Filter) ([]*WorkflowJob, error) {
+	return queryWorkflowJobs(s.handle, chaiQueryWorkflowJobsOrder, filter)
+}
+
+// RollupWorkflowRuns implements the Store interface.
+func (s *chaiStore) RollupWorkflowRuns(timeframe time.Duration) (int64, error) {
+	return rollupWorkflowRuns(s.handle, timeframe)
+}
+
+// GetWorkflowRunRollups implements the Store interface.
+func (s *chaiStore) GetWorkflowRunRollups(window time.Duration) ([]*WorkflowRunRollup, error) {
+	return getWorkflowRunRollups(s.handle, window)
+}
+
+// PruneWorkflowRunRollups implements the Store interface.
+func (s *chaiStore) PruneWorkflowRunRollups(timeframe time.Duration, limit int) (int64, error) {
+	return pruneWorkflowRunRollups(s.handle, purgeWorkflowRunRollupsQuery, timeframe, limit)
+}
+
+// CountWorkflowRunRollups implements the Store interface.
+func (s *chaiStore) CountWorkflowRunRollups() (int64, error) {
+	return countWorkflowRunRollups(s.handle)
+}
+
+// ListWorkflowRunRollups implements the Store interface.
+func (s *chaiStore) ListWorkflowRunRollups(offset, limit int) ([]*WorkflowRunRollup, error) {
+	return listWorkflowRunRollups(s.handle, chaiListWorkflowRunRollupsQuery, offset, limit)
+}
+
+// ImportWorkflowRunRollups implements the Store interface.
+func (s *chaiStore) ImportWorkflowRunRollups(records []*WorkflowRunRollup) error {
+	return importWorkflowRunRollups(s.handle, records)
+}
+
+// StoreDeploymentProtectionRuleEvent implements the Store interface.
+func (s *chaiStore) StoreDeploymentProtectionRuleEvent(event *github.DeploymentProtectionRuleEvent) error {
+	record, err := deploymentProtectionRuleRecord(event)
+
+	if err != nil || record == nil {
+		return err
+	}
+
+	return s.transaction(func(tx *sqlx.Tx) error {
+		return chaiWriteDeploymentReview(tx, record, deploymentRequestApplies)
+	})
+}
+
+// StoreDeploymentReviewEvent implements the Store interface.
+func (s *chaiStore) StoreDeploymentReviewEvent(event *github.DeploymentReviewEvent) error {
+	requested, reviewed := deploymentReviewRecords(event)
+
+	return s.transaction(func(tx *sqlx.Tx) error {
+		for _, record := range requested {
+			if err := chaiWriteDeploymentReview(tx, record, deploymentRequestApplies); err != nil {
+				return err
+			}
+		}
+
+		for _, record := range reviewed {
+			if err := chaiWriteDeploymentReview(tx, record, deploymentReviewApplies); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetDeploymentReviews implements the Store interface.
+func (s *chaiStore) GetDeploymentReviews(window time.Duration) ([]*DeploymentReview, error) {
+	return getDeploymentReviews(s.handle, window)
+}
+
+// PruneDeploymentReviews implements the Store interface.
+func (s *chaiStore) PruneDeploymentReviews(timeframe time.Duration, limit int) (int64, error) {
+	return pruneDeploymentReviews(s.handle, purgeDeploymentReviewsQuery, timeframe, limit)
+}
+
+// CountDeploymentReviews implements the Store interface.
+func (s *chaiStore) CountDeploymentReviews() (int64, error) {
+	return countDeploymentReviews(s.handle)
+}
+
+// ListDeploymentReviews implements the Store interface.
+func (s *chaiStore) ListDeploymentReviews(offset, limit int) ([]*DeploymentReview, error) {
+	return listDeploymentReviews(s.handle, chaiListDeploymentReviewsQuery, offset, limit)
+}
+
+// ImportDeploymentReviews implements the Store interface.
+func (s *chaiStore) ImportDeploymentReviews(records []*DeploymentReview) error {
+	return importDeploymentReviews(s.handle, records)
+}
+
+// GetCacheEntry implements the Store interface.
+func (s *chaiStore) GetCacheEntry(key string) (*CacheEntry, error) {
+	return getCacheEntry(s.handle, key)
+}
+
+// StoreCacheEntry implements the Store interface.
+func (s *chaiStore) StoreCacheEntry(record *CacheEntry) error {
+	return storeCacheEntry(s.handle, record)
+}
+
+// PruneCacheEntries implements the Store interface.
+func (s *chaiStore) PruneCacheEntries(timeframe time.Duration, limit int) (int64, error) {
+	return pruneCacheEntries(s.handle, purgeCacheEntriesQuery, timeframe, limit)
+}
+
+// AcquireLease implements the Store interface.
+func (s *chaiStore) AcquireLease(name, holder string, ttl time.Duration) (bool, error) {
+	return acquireLease(s.handle, name, holder, ttl)
+}
+
+// ReleaseLease implements the Store interface.
+func (s *chaiStore) ReleaseLease(name, holder string) error {
+	return releaseLease(s.handle, name, holder)
+}
+
+// GetTableStats implements the Store interface.
+func (s *chaiStore) GetTableStats() ([]*TableStats, error) {
+	return getTableStats(s.handle)
+}
+
+// GetSize implements the Store interface.
+func (s *chaiStore) GetSize() (int64, error) {
+	return 0, ErrUnsupported
+}
+
+// chaiWriteWorkflowRun creates or updates the record within the transaction.
+func chaiWriteWorkflowRun(tx *sqlx.Tx, record *WorkflowRun) error {
+	existing := &WorkflowRun{}
+	query, args, err := tx.BindNamed(findWorkflowRunQuery, record)
+
+	if err != nil {
+		return fmt.Errorf("failed to prepare find: %w", err)
+	}
+
+	if err := tx.Get(existing, query, args...); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to find record: %w", err)
+		}
+
+		if _, err := tx.NamedExec(createWorkflowRunQuery, record); err != nil {
+			return fmt.Errorf("failed to create record: %w", err)
+		}
+
+		return nil
+	}
+
+	if workflowRunOutdated(existing, record) {
+		return nil
+	}
+
+	if _, err := tx.NamedExec(updateWorkflowRunQuery, record); err != nil {
+		return fmt.Errorf("failed to update record: %w", err)
+	}
+
+	return nil
+}
+
+// chaiWriteWorkflowJob creates or updates the record within the transaction.
+func chaiWriteWorkflowJob(tx *sqlx.Tx, record *WorkflowJob) error {
+	existing, err := findWorkflowJob(tx, record)
+
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		if _, err := tx.NamedExec(createWorkflowJobQuery, record); err != nil {
+			return fmt.Errorf("failed to create record: %w", err)
+		}
+
+		return nil
+	}
+
+	if jobRegression(existing, record) {
+		return outdatedWorkflowJob(existing, record)
+	}
+
+	if _, err := tx.NamedExec(updateWorkflowJobQuery, record); err != nil {
+		return fmt.Errorf("failed to update record: %w", err)
+	}
+
+	return nil
+}
+
+// chaiWriteDeploymentReview creates the review or updates it if the record
+// applies to the existing review.
+func chaiWriteDeploymentReview(tx *sqlx.Tx, record *DeploymentReview, applies func(existing, record *DeploymentReview) bool) error {
+	existing := &DeploymentReview{}
+	query, args, err := tx.BindNamed(findDeploymentReviewQuery, record)
+
+	if err != nil {
+		return fmt.Errorf("failed to prepare find: %w", err)
+	}
+
+	if err := tx.Get(existing, query, args...); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to find record: %w", err)
+		}
+
+		if _, err := tx.NamedExec(createDeploymentReviewQuery, record); err != nil {
+			return fmt.Errorf("failed to create record: %w", err)
+		}
+
+		return nil
+	}
+
+	if !applies(existing, record) {
+		return nil
+	}
+
+	if _, err := tx.NamedExec(updateDeploymentReviewQuery, record); err != nil {
+		return fmt.Errorf("failed to update record: %w", err)
+	}
+
+	return nil
+}
+
+// transaction wraps the callback within a transaction, Chai doesn't support
+// upserts with conditions but it only permits a single writing transaction.
+func (s *chaiStore) transaction(fn func(tx *sqlx.Tx) error) error {
+	tx, err := s.handle.Beginx()
+
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
 }
 
 func (s *chaiStore) dsn() string {
@@ -0,0 +1,43 @@
+//go:build chai
+
+package store
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testChaiStore(t *testing.T) Store {
+	t.Helper()
+
+	s, err := NewChaiStore(
+		fmt.Sprintf("chai://%s", path.Join(t.TempDir(), "test.db")),
+		slog.Default(),
+	)
+
+	require.NoError(t, err)
+
+	_, err = s.Open()
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = s.Close() })
+	require.NoError(t, s.Migrate())
+
+	return s
+}
+
+func TestChaiConcurrentWorkflowRuns(t *testing.T) {
+	testConcurrentWorkflowRuns(t, testChaiStore(t))
+}
+
+func TestChaiConcurrentWorkflowJobs(t *testing.T) {
+	testConcurrentWorkflowJobs(t, testChaiStore(t))
+}
+
+func TestChaiConcurrentDeploymentReviews(t *testing.T) {
+	testConcurrentDeploymentReviews(t, testChaiStore(t))
+}
@@ -0,0 +1,114 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// getCacheEntry fetches a cached API response by the key.
+func getCacheEntry(handle *sqlx.DB, key string) (*CacheEntry, error) {
+	record := &CacheEntry{}
+
+	if err := handle.Get(
+		record,
+		handle.Rebind(selectCacheEntryQuery),
+		key,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		return nil, fmt.Errorf("failed to fetch cache entry: %w", err)
+	}
+
+	return record, nil
+}
+
+// storeCacheEntry replaces a cached API response within a transaction, that
+// way no dialect specific upsert is required.
+func storeCacheEntry(handle *sqlx.DB, record *CacheEntry) error {
+	tx, err := handle.Beginx()
+
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	if _, err := tx.NamedExec(
+		deleteCacheEntryQuery,
+		record,
+	); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to delete cache entry: %w", err)
+	}
+
+	if _, err := tx.NamedExec(
+		createCacheEntryQuery,
+		record,
+	); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to create cache entry: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// pruneCacheEntries prunes outdated cached API responses.
+func pruneCacheEntries(handle *sqlx.DB, query string, timeframe time.Duration, limit int) (int64, error) {
+	total, err := pruneRecords(handle, query, timeframe, limit)
+
+	if err != nil {
+		return total, fmt.Errorf("failed to prune cache entries: %w", err)
+	}
+
+	return total, nil
+}
+
+var selectCacheEntryQuery = `
+SELECT
+	cache_key,
+	etag,
+	last_modified,
+	header,
+	body,
+	updated_at
+FROM
+	http_cache
+WHERE
+	cache_key = ?;`
+
+var createCacheEntryQuery = `
+INSERT INTO http_cache (
+	cache_key,
+	etag,
+	last_modified,
+	header,
+	body,
+	updated_at
+) VALUES (
+	:cache_key,
+	:etag,
+	:last_modified,
+	:header,
+	:body,
+	:updated_at
+);`
+
+var deleteCacheEntryQuery = `
+DELETE FROM
+	http_cache
+WHERE
+	cache_key=:cache_key;`
+
+var purgeCacheEntriesQuery = `
+DELETE FROM
+	http_cache
+WHERE
+	updated_at < :timeframe;`
@@ -0,0 +1,462 @@
+package store
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	callbackRunRegexp = regexp.MustCompile(`/actions/runs/(\d+)/`)
+)
+
+// storeDeploymentProtectionRuleEvent handles deployment_protection_rule events from GitHub.
+func storeDeploymentProtectionRuleEvent(handle *sqlx.DB, request string, event *github.DeploymentProtectionRuleEvent) error {
+	record, err := deploymentProtectionRuleRecord(event)
+
+	if err != nil || record == nil {
+		return err
+	}
+
+	return upsertDeploymentReview(handle, request, record)
+}
+
+// storeDeploymentReviewEvent handles deployment_review events from GitHub.
+func storeDeploymentReviewEvent(handle *sqlx.DB, request, review string, event *github.DeploymentReviewEvent) error {
+	requested, reviewed := deploymentReviewRecords(event)
+
+	for _, record := range requested {
+		if err := upsertDeploymentReview(handle, request, record); err != nil {
+			return err
+		}
+	}
+
+	for _, record := range reviewed {
+		if err := upsertDeploymentReview(handle, review, record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// upsertDeploymentReview creates or updates the review with a dialect
+// specific upsert, the guard of the upsert decides if the record applies.
+func upsertDeploymentReview(handle *sqlx.DB, upsert string, record *DeploymentReview) error {
+	if _, err := handle.NamedExec(
+		upsert,
+		record,
+	); err != nil {
+		return fmt.Errorf("failed to upsert record: %w", err)
+	}
+
+	return nil
+}
+
+// deploymentProtectionRuleRecord converts a deployment_protection_rule event
+// to a pending review, other actions than requested are ignored.
+func deploymentProtectionRuleRecord(event *github.DeploymentProtectionRuleEvent) (*DeploymentReview, error) {
+	if event.GetAction() != "requested" {
+		return nil, nil
+	}
+
+	matches := callbackRunRegexp.FindStringSubmatch(
+		event.GetDeploymentCallbackURL(),
+	)
+
+	if len(matches) != 2 {
+		return nil, fmt.Errorf("failed to parse run from callback url")
+	}
+
+	runID, err := strconv.ParseInt(matches[1], 10, 64)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse run from callback url: %w", err)
+	}
+
+	record := &DeploymentReview{
+		Owner:       event.GetRepo().GetOwner().GetLogin(),
+		Repo:        event.GetRepo().GetName(),
+		RunID:       runID,
+		Environment: event.GetEnvironment(),
+		Status:      "pending",
+		RequestedAt: event.GetDeployment().GetCreatedAt().Unix(),
+	}
+
+	if record.RequestedAt <= 0 {
+		record.RequestedAt = time.Now().Unix()
+	}
+
+	return record, nil
+}
+
+// deploymentReviewRecords converts a deployment_review event to requested
+// and reviewed records.
+func deploymentReviewRecords(event *github.DeploymentReviewEvent) ([]*DeploymentReview, []*DeploymentReview) {
+	requested := make([]*DeploymentReview, 0)
+	reviewed := make([]*DeploymentReview, 0)
+
+	switch event.GetAction() {
+	case "requested":
+		record := &DeploymentReview{
+			Owner:       event.GetRepo().GetOwner().GetLogin(),
+			Repo:        event.GetRepo().GetName(),
+			RunID:       event.GetWorkflowRun().GetID(),
+			Environment: event.GetEnvironment(),
+			Status:      "pending",
+			RequestedAt: event.GetWorkflowJobRun().GetCreatedAt().Unix(),
+		}
+
+		if record.RequestedAt <= 0 {
+			record.RequestedAt = time.Now().Unix()
+		}
+
+		requested = append(requested, record)
+	case "approved", "rejected":
+		jobRuns := event.WorkflowJobRuns
+
+		if len(jobRuns) == 0 && event.WorkflowJobRun != nil {
+			jobRuns = []*github.WorkflowJobRun{
+				event.WorkflowJobRun,
+			}
+		}
+
+		for _, jobRun := range jobRuns {
+			record := &DeploymentReview{
+				Owner:       event.GetRepo().GetOwner().GetLogin(),
+				Repo:        event.GetRepo().GetName(),
+				RunID:       event.GetWorkflowRun().GetID(),
+				Environment: jobRun.GetEnvironment(),
+				Status:      event.GetAction(),
+				Approver:    event.GetApprover().GetLogin(),
+				RequestedAt: jobRun.GetCreatedAt().Unix(),
+				ReviewedAt:  jobRun.GetUpdatedAt().Unix(),
+			}
+
+			if record.ReviewedAt <= 0 {
+				record.ReviewedAt = time.Now().Unix()
+			}
+
+			reviewed = append(reviewed, record)
+		}
+	}
+
+	return requested, reviewed
+}
+
+// deploymentRequestApplies checks if a request should replace the existing
+// review. If the review is already pending or the request have been delivered
+// after the review itself, in both cases the existing record is more accurate.
+func deploymentRequestApplies(existing, record *DeploymentReview) bool {
+	return existing.Status != "pending" && record.RequestedAt > existing.ReviewedAt
+}
+
+// deploymentReviewApplies checks if a review should replace the existing
+// review, the original request time gets preserved on the record.
+func deploymentReviewApplies(existing, record *DeploymentReview) bool {
+	if (existing.Status == "approved" || existing.Status == "rejected") && existing.ReviewedAt > record.ReviewedAt {
+		return false
+	}
+
+	if existing.RequestedAt > 0 && existing.RequestedAt <= record.ReviewedAt {
+		record.RequestedAt = existing.RequestedAt
+	}
+
+	return true
+}
+
+// resolveDeploymentReviews finishes pending reviews of a run which continued
+// without an explicit review, e.g. through a custom protection rule. Reviews
+// requested after the run continued are kept pending, unless the run has been
+// completed, e.g. because it got cancelled while waiting for the review.
+func resolveDeploymentReviews(handle *sqlx.DB, owner, repo string, runID, resolvedAt int64, completed bool) error {
+	query := resolveDeploymentReviewsQuery
+
+	if completed {
+		query = completeDeploymentReviewsQuery
+	}
+
+	if _, err := handle.NamedExec(
+		query,
+		map[string]interface{}{
+			"owner":       owner,
+			"repo":        repo,
+			"run_id":      runID,
+			"reviewed_at": resolvedAt,
+		},
+	); err != nil {
+		return fmt.Errorf("failed to resolve deployment reviews: %w", err)
+	}
+
+	return nil
+}
+
+// getDeploymentReviews retrieves the deployment reviews from the database.
+func getDeploymentReviews(handle *sqlx.DB, window time.Duration) ([]*DeploymentReview, error) {
+	records := make([]*DeploymentReview, 0)
+
+	rows, err := handle.NamedQuery(
+		selectDeploymentReviewsQuery,
+		map[string]interface{}{
+			"window": time.Now().Add(-window).Unix(),
+		},
+	)
+
+	if err != nil {
+		return records, err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		record := &DeploymentReview{}
+
+		if err := rows.StructScan(
+			record,
+		); err != nil {
+			return records, err
+		}
+
+		records = append(
+			records,
+			record,
+		)
+	}
+
+	if err := rows.Err(); err != nil {
+		return records, err
+	}
+
+	return records, nil
+}
+
+// countDeploymentReviews counts all stored deployment reviews.
+func countDeploymentReviews(handle *sqlx.DB) (int64, error) {
+	total := int64(0)
+
+	if err := handle.Get(
+		&total,
+		countDeploymentReviewsQuery,
+	); err != nil {
+		return 0, fmt.Errorf("failed to count deployment reviews: %w", err)
+	}
+
+	return total, nil
+}
+
+// listDeploymentReviews retrieves a page of all stored deployment reviews.
+func listDeploymentReviews(handle *sqlx.DB, query string, offset, limit int) ([]*DeploymentReview, error) {
+	records := make([]*DeploymentReview, 0)
+
+	rows, err := handle.NamedQuery(
+		query,
+		map[string]interface{}{
+			"offset": offset,
+			"limit":  limit,
+		},
+	)
+
+	if err != nil {
+		return records, err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		record := &DeploymentReview{}
+
+		if err := rows.StructScan(
+			record,
+		); err != nil {
+			return records, err
+		}
+
+		records = append(
+			records,
+			record,
+		)
+	}
+
+	if err := rows.Err(); err != nil {
+		return records, err
+	}
+
+	return records, nil
+}
+
+// importDeploymentReviews replaces the records within a single transaction.
+func importDeploymentReviews(handle *sqlx.DB, records []*DeploymentReview) error {
+	tx, err := handle.Beginx()
+
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	for _, record := range records {
+		if _, err := tx.NamedExec(
+			deleteDeploymentReviewQuery,
+			record,
+		); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to import record: %w", err)
+		}
+
+		if _, err := tx.NamedExec(
+			createDeploymentReviewQuery,
+			record,
+		); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to import record: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+var selectDeploymentReviewsQuery = `
+SELECT
+	owner,
+	repo,
+	run_id,
+	environment,
+	status,
+	approver,
+	requested_at,
+	reviewed_at
+FROM
+	deployment_reviews
+WHERE
+	requested_at > :window OR status = 'pending'
+ORDER BY
+	requested_at ASC;`
+
+var listDeploymentReviewsQuery = `
+SELECT
+	owner,
+	repo,
+	run_id,
+	environment,
+	status,
+	approver,
+	requested_at,
+	reviewed_at
+FROM
+	deployment_reviews
+ORDER BY
+	owner, repo, run_id, environment
+LIMIT :limit OFFSET :offset;`
+
+var countDeploymentReviewsQuery = `
+SELECT
+	COUNT(*)
+FROM
+	deployment_reviews;`
+
+var findDeploymentReviewQuery = `
+SELECT
+	owner,
+	repo,
+	run_id,
+	environment,
+	status,
+	approver,
+	requested_at,
+	reviewed_at
+FROM
+	deployment_reviews
+WHERE
+	owner=:owner AND repo=:repo AND run_id=:run_id AND environment=:environment;`
+
+var createDeploymentReviewQuery = `
+INSERT INTO deployment_reviews (
+	owner,
+	repo,
+	run_id,
+	environment,
+	status,
+	approver,
+	requested_at,
+	reviewed_at
+) VALUES (
+	:owner,
+	:repo,
+	:run_id,
+	:environment,
+	:status,
+	:approver,
+	:requested_at,
+	:reviewed_at
+);`
+
+// upsertDeploymentRequestQuery is used by PostgreSQL and SQLite, the guard
+// mirrors the deploymentRequestApplies function.
+var upsertDeploymentRequestQuery = strings.TrimSuffix(createDeploymentReviewQuery, ";") + `
+ON CONFLICT (owner, repo, run_id, environment) DO UPDATE SET
+	status=excluded.status,
+	approver=excluded.approver,
+	requested_at=excluded.requested_at,
+	reviewed_at=excluded.reviewed_at
+WHERE
+	deployment_reviews.status <> 'pending' AND deployment_reviews.reviewed_at < excluded.requested_at;`
+
+// upsertDeploymentReviewQuery is used by PostgreSQL and SQLite, the guard
+// mirrors the deploymentReviewApplies function and preserves the original
+// request time.
+var upsertDeploymentReviewQuery = strings.TrimSuffix(createDeploymentReviewQuery, ";") + `
+ON CONFLICT (owner, repo, run_id, environment) DO UPDATE SET
+	status=excluded.status,
+	approver=excluded.approver,
+	requested_at=CASE WHEN deployment_reviews.requested_at > 0 AND deployment_reviews.requested_at <= excluded.reviewed_at THEN deployment_reviews.requested_at ELSE excluded.requested_at END,
+	reviewed_at=excluded.reviewed_at
+WHERE
+	deployment_reviews.status NOT IN ('approved', 'rejected') OR deployment_reviews.reviewed_at <= excluded.reviewed_at;`
+
+var updateDeploymentReviewQuery = `
+UPDATE
+	deployment_reviews
+SET
+	status=:status,
+	approver=:approver,
+	requested_at=:requested_at,
+	reviewed_at=:reviewed_at
+WHERE
+	owner=:owner AND repo=:repo AND run_id=:run_id AND environment=:environment;`
+
+var deleteDeploymentReviewQuery = `
+DELETE FROM
+	deployment_reviews
+WHERE
+	owner=:owner AND repo=:repo AND run_id=:run_id AND environment=:environment;`
+
+var resolveDeploymentReviewsQuery = `
+UPDATE
+	deployment_reviews
+SET
+	status='resolved',
+	reviewed_at=:reviewed_at
+WHERE
+	owner=:owner AND repo=:repo AND run_id=:run_id AND status='pending' AND requested_at < :reviewed_at;`
+
+var completeDeploymentReviewsQuery = `
+UPDATE
+	deployment_reviews
+SET
+	status='resolved',
+	reviewed_at=:reviewed_at
+WHERE
+	owner=:owner AND repo=:repo AND run_id=:run_id AND status='pending';`
+
+var purgeDeploymentReviewsQuery = `
+DELETE FROM
+	deployment_reviews
+WHERE
+	requested_at < :timeframe;`
@@ -0,0 +1,228 @@
+package store
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// filterConditions builds the conditions shared by all record types, the
+// time range applies to the timestamp column.
+func filterConditions(filter *Filter, timestamp string) ([]string, map[string]interface{}) {
+	conditions := make([]string, 0)
+	params := map[string]interface{}{
+		"offset": filter.Offset,
+		"limit":  filter.Limit,
+	}
+
+	if filter.Owner != "" {
+		conditions = append(conditions, "owner = :owner")
+		params["owner"] = filter.Owner
+	}
+
+	if filter.Repo != "" {
+		conditions = append(conditions, "repo = :repo")
+		params["repo"] = filter.Repo
+	}
+
+	if filter.Branch != "" {
+		conditions = append(conditions, "branch = :branch")
+		params["branch"] = filter.Branch
+	}
+
+	if filter.Since > 0 {
+		conditions = append(conditions, timestamp+" >= :since")
+		params["since"] = filter.Since
+	}
+
+	if filter.Until > 0 {
+		conditions = append(conditions, timestamp+" < :until")
+		params["until"] = filter.Until
+	}
+
+	return conditions, params
+}
+
+// filterQuery appends the conditions, the order and the page to the base
+// query. The order has to be unique, otherwise pages could overlap.
+func filterQuery(base string, conditions []string, order string, limit int) string {
+	query := base
+
+	if len(conditions) > 0 {
+		query = query + "WHERE\n\t" + strings.Join(conditions, " AND ") + "\n"
+	}
+
+	query = query + "ORDER BY\n\t" + order
+
+	if limit > 0 {
+		query = query + "\nLIMIT :limit OFFSET :offset"
+	}
+
+	return query + ";"
+}
+
+// queryWorkflowRuns retrieves the workflow runs matching the filter. The
+// workflow matches the workflow ID if it's numeric, otherwise the name.
+func queryWorkflowRuns(handle *sqlx.DB, order string, filter *Filter) ([]*WorkflowRun, error) {
+	conditions, params := filterConditions(filter, "updated_at")
+
+	if filter.Workflow != "" {
+		if id, err := strconv.ParseInt(filter.Workflow, 10, 64); err == nil {
+			conditions = append(conditions, "workflow_id = :workflow")
+			params["workflow"] = id
+		} else {
+			conditions = append(conditions, "name = :workflow")
+			params["workflow"] = filter.Workflow
+		}
+	}
+
+	if filter.Status != "" {
+		conditions = append(conditions, "status = :status")
+		params["status"] = filter.Status
+	}
+
+	return selectRecords[WorkflowRun](
+		handle,
+		filterQuery(queryWorkflowRunsQuery, conditions, order, filter.Limit),
+		params,
+	)
+}
+
+// queryWorkflowJobs retrieves the workflow jobs matching the filter. The
+// status matches the status or the conclusion of the jobs.
+func queryWorkflowJobs(handle *sqlx.DB, order string, filter *Filter) ([]*WorkflowJob, error) {
+	conditions, params := filterConditions(filter, "created_at")
+
+	if filter.Workflow != "" {
+		conditions = append(conditions, "workflow_name = :workflow")
+		params["workflow"] = filter.Workflow
+	}
+
+	if filter.Status != "" {
+		conditions = append(conditions, "(status = :status OR conclusion = :status)")
+		params["status"] = filter.Status
+	}
+
+	return selectRecords[WorkflowJob](
+		handle,
+		filterQuery(queryWorkflowJobsQuery, conditions, order, filter.Limit),
+		params,
+	)
+}
+
+// selectRecords scans all records returned by the query.
+func selectRecords[T any](handle *sqlx.DB, query string, params map[string]interface{}) ([]*T, error) {
+	records := make([]*T, 0)
+
+	rows, err := handle.NamedQuery(
+		query,
+		params,
+	)
+
+	if err != nil {
+		return records, err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		record := new(T)
+
+		if err := rows.StructScan(
+			record,
+		); err != nil {
+			return records, err
+		}
+
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return records, err
+	}
+
+	return records, nil
+}
+
+// matchWorkflowRun checks if the workflow run matches the filter.
+func (f *Filter) matchWorkflowRun(record *WorkflowRun) bool {
+	if f.Workflow != "" && f.Workflow != strconv.FormatInt(record.WorkflowID, 10) && f.Workflow != record.Name {
+		return false
+	}
+
+	if f.Status != "" && f.Status != record.Status {
+		return false
+	}
+
+	return f.match(record.Owner, record.Repo, record.Branch, record.UpdatedAt)
+}
+
+// matchWorkflowJob checks if the workflow job matches the filter.
+func (f *Filter) matchWorkflowJob(record *WorkflowJob) bool {
+	if f.Workflow != "" && f.Workflow != record.WorkflowName {
+		return false
+	}
+
+	if f.Status != "" && f.Status != record.Status && f.Status != record.Conclusion {
+		return false
+	}
+
+	return f.match(record.Owner, record.Repo, record.Branch, record.CreatedAt)
+}
+
+// match checks the conditions shared by all record types.
+func (f *Filter) match(owner, repo, branch string, timestamp int64) bool {
+	if f.Owner != "" && f.Owner != owner {
+		return false
+	}
+
+	if f.Repo != "" && f.Repo != repo {
+		return false
+	}
+
+	if f.Branch != "" && f.Branch != branch {
+		return false
+	}
+
+	if f.Since > 0 && timestamp < f.Since {
+		return false
+	}
+
+	if f.Until > 0 && timestamp >= f.Until {
+		return false
+	}
+
+	return true
+}
+
+// queryWorkflowRunsOrder returns the newest runs first, the primary key keeps
+// the order unique.
+var queryWorkflowRunsOrder = "updated_at DESC, owner, repo, workflow_id, number"
+
+// queryWorkflowJobsOrder returns the newest jobs first, the primary key keeps
+// the order unique.
+var queryWorkflowJobsOrder = "created_at DESC, owner, repo, identifier"
+
+// queryWorkflowRunsQuery selects the same columns as the list query.
+var queryWorkflowRunsQuery = strings.SplitN(listWorkflowRunsQuery, "ORDER BY", 2)[0]
+
+// queryWorkflowJobsQuery selects the same columns as the list query.
+var queryWorkflowJobsQuery = strings.SplitN(listWorkflowJobsQuery, "ORDER BY", 2)[0]
+
+// walkConditions prepares the query and parameters of a walk. With a limit the
+// database returns one more row than allowed, that way the walk is able to
+// detect truncation without reading the whole window.
+func walkConditions(query string, window time.Duration, limit int) (string, map[string]interface{}) {
+	params := map[string]interface{}{
+		"window": time.Now().Add(-window).Unix(),
+	}
+
+	if limit > 0 {
+		query = strings.TrimSuffix(query, ";") + "\nLIMIT :limit;"
+		params["limit"] = limit + 1
+	}
+
+	return query, params
+}
@@ -0,0 +1,107 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// acquireLease takes over or renews the named lease if it's free, expired or
+// already held by the holder. The conditional update is atomic for all
+// dialects, only a missing lease gets inserted and a concurrent insert loses
+// on the primary key.
+func acquireLease(handle *sqlx.DB, name, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	params := map[string]interface{}{
+		"name":       name,
+		"holder":     holder,
+		"now":        now.Unix(),
+		"expires_at": now.Add(ttl).Unix(),
+	}
+
+	res, err := handle.NamedExec(
+		updateLeaseQuery,
+		params,
+	)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to update lease: %w", err)
+	}
+
+	if affected, err := res.RowsAffected(); err == nil && affected > 0 {
+		return true, nil
+	}
+
+	_, insertErr := handle.NamedExec(
+		createLeaseQuery,
+		params,
+	)
+
+	if insertErr == nil {
+		return true, nil
+	}
+
+	current := ""
+
+	if err := handle.Get(
+		&current,
+		handle.Rebind(findLeaseQuery),
+		name,
+	); err != nil {
+		return false, fmt.Errorf("failed to create lease: %w", insertErr)
+	}
+
+	// MySQL reports unchanged rows as not affected, that's why a renewal
+	// within the same second ends up here.
+	return current == holder, nil
+}
+
+// releaseLease gives up the named lease if it's held by the holder.
+func releaseLease(handle *sqlx.DB, name, holder string) error {
+	if _, err := handle.NamedExec(
+		deleteLeaseQuery,
+		map[string]interface{}{
+			"name":   name,
+			"holder": holder,
+		},
+	); err != nil {
+		return fmt.Errorf("failed to release lease: %w", err)
+	}
+
+	return nil
+}
+
+var updateLeaseQuery = `
+UPDATE
+	leases
+SET
+	holder=:holder,
+	expires_at=:expires_at
+WHERE
+	name=:name AND (holder=:holder OR expires_at < :now);`
+
+var createLeaseQuery = `
+INSERT INTO leases (
+	name,
+	holder,
+	expires_at
+) VALUES (
+	:name,
+	:holder,
+	:expires_at
+);`
+
+var findLeaseQuery = `
+SELECT
+	holder
+FROM
+	leases
+WHERE
+	name = ?;`
+
+var deleteLeaseQuery = `
+DELETE FROM
+	leases
+WHERE
+	name=:name AND holder=:holder;`
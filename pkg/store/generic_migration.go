@@ -0,0 +1,76 @@
+package store
+
+import (
+	"cmp"
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"github.com/GuiaBolso/darwin"
+)
+
+// migrationDialect extends the darwin dialect by a query to detect the
+// bookkeeping table.
+type migrationDialect interface {
+	darwin.Dialect
+	ExistsSQL() string
+}
+
+// migrationStatus compares the defined migrations with the applied ones. The
+// status is read-only, without the bookkeeping table of darwin all migrations
+// are reported as pending.
+func migrationStatus(db *sql.DB, dialect migrationDialect, migrations []darwin.Migration) ([]*Migration, error) {
+	exists := 0
+
+	if err := db.QueryRow(dialect.ExistsSQL()).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to detect migration table: %w", err)
+	}
+
+	records := make([]darwin.MigrationRecord, 0)
+
+	if exists > 0 {
+		applied, err := darwin.NewGenericDriver(db, dialect).All()
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch applied migrations: %w", err)
+		}
+
+		records = applied
+	}
+
+	applied := make(map[float64]darwin.MigrationRecord, len(records))
+
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+
+	result := make([]*Migration, 0, len(migrations))
+
+	for _, migration := range migrations {
+		record, ok := applied[migration.Version]
+
+		status := &Migration{
+			Version:     migration.Version,
+			Description: migration.Description,
+			Script:      migration.Script,
+			Status:      MigrationPending,
+		}
+
+		if ok {
+			status.Status = MigrationApplied
+			status.AppliedAt = record.AppliedAt.Unix()
+
+			if record.Checksum != migration.Checksum() {
+				status.Status = MigrationChanged
+			}
+		}
+
+		result = append(result, status)
+	}
+
+	slices.SortStableFunc(result, func(a, b *Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+
+	return result, nil
+}
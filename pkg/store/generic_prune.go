@@ -0,0 +1,131 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// pruneRecords deletes records older than the timeframe in batches until a
+// batch deletes less records than the limit. Every batch is a single
+// statement, so multiple exporters can safely prune the same database.
+func pruneRecords(handle *sqlx.DB, query string, timeframe time.Duration, limit int) (int64, error) {
+	return pruneBatches(handle, query, map[string]interface{}{
+		"timeframe": time.Now().Add(-timeframe).Unix(),
+	}, limit)
+}
+
+// pruneBatches executes the prune query with the params until a batch deletes
+// less records than the limit.
+func pruneBatches(handle *sqlx.DB, query string, params map[string]interface{}, limit int) (int64, error) {
+	total := int64(0)
+	params["limit"] = limit
+
+	for {
+		res, err := handle.NamedExec(
+			query,
+			params,
+		)
+
+		if err != nil {
+			return total, err
+		}
+
+		affected, err := res.RowsAffected()
+
+		if err != nil {
+			return total, err
+		}
+
+		total += affected
+
+		if affected == 0 || affected < int64(limit) {
+			return total, nil
+		}
+	}
+}
+
+// pruneRetention deletes outdated records based on the retention. Without
+// rules a single prune query gets executed, otherwise every repository gets
+// pruned with the window of the matching rule. The deleted records get
+// reported per rule.
+func pruneRetention(handle *sqlx.DB, repos, query, scoped string, retention Retention, limit int) (map[string]int64, error) {
+	result := make(map[string]int64)
+
+	if len(retention.Rules) == 0 {
+		deleted, err := pruneRecords(handle, query, retention.Window, limit)
+		result[RetentionDefault] = deleted
+
+		return result, err
+	}
+
+	records := make([]struct {
+		Owner string `db:"owner"`
+		Repo  string `db:"repo"`
+	}, 0)
+
+	if err := handle.Select(&records, repos); err != nil {
+		return result, err
+	}
+
+	seen := make(map[[2]string]bool, len(records))
+
+	for _, record := range records {
+		key := [2]string{record.Owner, record.Repo}
+
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		rule, window := retention.Resolve(record.Owner, record.Repo)
+
+		deleted, err := pruneBatches(handle, scoped, map[string]interface{}{
+			"owner":     record.Owner,
+			"repo":      record.Repo,
+			"timeframe": time.Now().Add(-window).Unix(),
+		}, limit)
+
+		result[rule] += deleted
+
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// pruneWorkflowRuns prunes older workflow run records.
+func pruneWorkflowRuns(handle *sqlx.DB, query, scoped string, retention Retention, limit int) (map[string]int64, error) {
+	total, err := pruneRetention(handle, reposWorkflowRunsQuery, query, scoped, retention, limit)
+
+	if err != nil {
+		return total, fmt.Errorf("failed to prune workflow runs: %w", err)
+	}
+
+	return total, nil
+}
+
+// pruneWorkflowJobs prunes older workflow job records.
+func pruneWorkflowJobs(handle *sqlx.DB, query, scoped string, retention Retention, limit int) (map[string]int64, error) {
+	total, err := pruneRetention(handle, reposWorkflowJobsQuery, query, scoped, retention, limit)
+
+	if err != nil {
+		return total, fmt.Errorf("failed to prune workflow jobs: %w", err)
+	}
+
+	return total, nil
+}
+
+// pruneDeploymentReviews prunes older deployment review records.
+func pruneDeploymentReviews(handle *sqlx.DB, query string, timeframe time.Duration, limit int) (int64, error) {
+	total, err := pruneRecords(handle, query, timeframe, limit)
+
+	if err != nil {
+		return total, fmt.Errorf("failed to prune deployment reviews: %w", err)
+	}
+
+	return total, nil
+}
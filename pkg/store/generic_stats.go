@@ -0,0 +1,67 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// statsTables defines the tables and the timestamp columns used for the
+// table stats, these are the same columns used for pruning or expiry.
+var statsTables = [][2]string{
+	{"workflow_runs", "updated_at"},
+	{"workflow_jobs", "created_at"},
+	{"deployment_reviews", "requested_at"},
+	{"workflow_run_rollups", "bucket"},
+	{"http_cache", "updated_at"},
+	{"leases", "expires_at"},
+}
+
+// tableStatsRow scans the aggregates, the timestamps are empty for tables
+// without any records.
+type tableStatsRow struct {
+	Total  int64         `db:"total"`
+	Oldest sql.NullInt64 `db:"oldest"`
+	Newest sql.NullInt64 `db:"newest"`
+}
+
+// getTableStats counts the records and fetches the record range per table.
+func getTableStats(handle *sqlx.DB) ([]*TableStats, error) {
+	records := make([]*TableStats, 0, len(statsTables))
+
+	for _, table := range statsTables {
+		row := tableStatsRow{}
+
+		if err := handle.Get(
+			&row,
+			fmt.Sprintf(
+				"SELECT COUNT(*) AS total, MIN(%[2]s) AS oldest, MAX(%[2]s) AS newest FROM %[1]s;",
+				table[0],
+				table[1],
+			),
+		); err != nil {
+			return nil, fmt.Errorf("failed to fetch stats for %s: %w", table[0], err)
+		}
+
+		records = append(records, &TableStats{
+			Table:  table[0],
+			Rows:   row.Total,
+			Oldest: row.Oldest.Int64,
+			Newest: row.Newest.Int64,
+		})
+	}
+
+	return records, nil
+}
+
+// getSize fetches the on-disk size of the database.
+func getSize(handle *sqlx.DB, query string) (int64, error) {
+	var size sql.NullInt64
+
+	if err := handle.Get(&size, query); err != nil {
+		return 0, fmt.Errorf("failed to fetch database size: %w", err)
+	}
+
+	return size.Int64, nil
+}
@@ -11,11 +11,43 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
-// storeWorkflowJobEvent handles workflow_run events from GitHub.
-func storeWorkflowJobEvent(handle *sqlx.DB, event *github.WorkflowJobEvent) error {
+// storeWorkflowJobEvent handles workflow_job events from GitHub.
+func storeWorkflowJobEvent(handle *sqlx.DB, upsert string, event *github.WorkflowJobEvent) error {
+	record := workflowJobFromEvent(event)
+
+	res, err := handle.NamedExec(
+		upsert,
+		record,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to upsert record: %w", err)
+	}
+
+	if affected, err := res.RowsAffected(); err != nil || affected > 0 {
+		return nil
+	}
+
+	// Nothing have been written, this happens if the guard of the upsert
+	// rejected the update or if MySQL detected an identical row.
+	existing, err := findWorkflowJob(handle, record)
+
+	if err != nil {
+		return err
+	}
+
+	if existing != nil && jobRegression(existing, record) {
+		return outdatedWorkflowJob(existing, record)
+	}
+
+	return nil
+}
+
+// workflowJobFromEvent converts a workflow_job event to a record.
+func workflowJobFromEvent(event *github.WorkflowJobEvent) *WorkflowJob {
 	job := event.WorkflowJob
 
-	record := &WorkflowJob{
+	return &WorkflowJob{
 		Owner:           event.GetRepo().GetOwner().GetLogin(),
 		Repo:            event.GetRepo().GetName(),
 		Name:            job.GetName(),
@@ -36,59 +68,253 @@ func storeWorkflowJobEvent(handle *sqlx.DB, event *github.WorkflowJobEvent) erro
 		RunnerGroupName: job.GetRunnerGroupName(),
 		WorkflowName:    job.GetWorkflowName(),
 	}
-
-	return createOrUpdateWorkflowJob(handle, record)
 }
 
-// createOrUpdateWorkflowJob creates or updates the record.
-func createOrUpdateWorkflowJob(handle *sqlx.DB, record *WorkflowJob) error {
+// findWorkflowJob fetches the stored state of the record.
+func findWorkflowJob(handle sqlx.Ext, record *WorkflowJob) (*WorkflowJob, error) {
 	existing := &WorkflowJob{}
-	stmt, err := handle.PrepareNamed(findWorkflowJobQuery)
+	query, args, err := handle.BindNamed(findWorkflowJobQuery, record)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare find: %w", err)
+	}
+
+	if err := sqlx.Get(handle, existing, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to find record: %w", err)
+	}
+
+	return existing, nil
+}
+
+// outdatedWorkflowJob wraps ErrOutdated with the conflicting states.
+func outdatedWorkflowJob(existing, record *WorkflowJob) error {
+	return fmt.Errorf(
+		"%w: status %s would replace %s",
+		ErrOutdated,
+		record.Status,
+		existing.Status,
+	)
+}
+
+// jobRegression checks if the record would move the stored job backwards
+// within the lifecycle queued, waiting, in_progress and completed. For equal
+// states the started and completed timestamps decide.
+func jobRegression(existing, record *WorkflowJob) bool {
+	current := jobStatusOrder(existing.Status)
+	next := jobStatusOrder(record.Status)
+
+	switch {
+	case next < current:
+		return true
+	case next > current:
+		return false
+	}
+
+	if record.CompletedAt > 0 && record.CompletedAt < existing.CompletedAt {
+		return true
+	}
+
+	if record.StartedAt > 0 && record.StartedAt < existing.StartedAt {
+		return true
+	}
+
+	return false
+}
+
+// jobStatusOrderExpr is the SQL counterpart of jobStatusOrder.
+const jobStatusOrderExpr = `CASE %s WHEN 'queued' THEN 1 WHEN 'waiting' THEN 2 WHEN 'in_progress' THEN 3 WHEN 'completed' THEN 4 ELSE 0 END`
+
+// jobProgressExpr is the SQL counterpart of a negated jobRegression, the
+// existing and the new record get referenced by the given column formats.
+func jobProgressExpr(existing, record string) string {
+	col := func(format, name string) string {
+		return fmt.Sprintf(format, name)
+	}
+
+	current := fmt.Sprintf(jobStatusOrderExpr, col(existing, "status"))
+	next := fmt.Sprintf(jobStatusOrderExpr, col(record, "status"))
+
+	return fmt.Sprintf(
+		"(%s > %s OR (%s = %s AND NOT (%s > 0 AND %s < %s) AND NOT (%s > 0 AND %s < %s)))",
+		next, current,
+		next, current,
+		col(record, "completed_at"), col(record, "completed_at"), col(existing, "completed_at"),
+		col(record, "started_at"), col(record, "started_at"), col(existing, "started_at"),
+	)
+}
 
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		return fmt.Errorf("failed to prepare find: %w", err)
+// jobStatusOrder returns the position of the status within the lifecycle.
+func jobStatusOrder(status string) int {
+	switch status {
+	case "queued":
+		return 1
+	case "waiting":
+		return 2
+	case "in_progress":
+		return 3
+	case "completed":
+		return 4
 	}
 
-	if err := stmt.Get(existing, record); err != nil && !errors.Is(err, sql.ErrNoRows) {
-		return fmt.Errorf("failed to find record: %w", err)
+	return 0
+}
+
+// getWorkflowJobs retrieves the workflow jobs from the database.
+func getWorkflowJobs(handle *sqlx.DB, window time.Duration) ([]*WorkflowJob, error) {
+	records := make([]*WorkflowJob, 0)
+
+	if err := walkWorkflowJobs(
+		handle,
+		selectWorkflowJobsQuery,
+		window,
+		0,
+		func(record *WorkflowJob) error {
+			records = append(
+				records,
+				record,
+			)
+
+			return nil
+		},
+	); err != nil {
+		return records, err
 	}
 
-	if existing.Identifier == 0 {
-		if _, err := handle.NamedExec(
-			createWorkflowJobQuery,
+	return records, nil
+}
+
+// walkWorkflowJobs executes the callback for every workflow job while scanning the
+// result, ErrLimitReached gets returned if more rows than the limit exist.
+func walkWorkflowJobs(handle *sqlx.DB, query string, window time.Duration, limit int, fn func(*WorkflowJob) error) error {
+	query, params := walkConditions(query, window, limit)
+	rows, err := handle.NamedQuery(
+		query,
+		params,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	for count := 0; rows.Next(); count++ {
+		if limit > 0 && count >= limit {
+			return ErrLimitReached
+		}
+
+		record := &WorkflowJob{}
+
+		if err := rows.StructScan(
 			record,
 		); err != nil {
-			return fmt.Errorf("failed to create record: %w", err)
+			return err
 		}
-	} else {
-		if existing.CreatedAt > record.CreatedAt {
-			return nil
-		} else if existing.CreatedAt == record.CreatedAt && existing.Status == "completed" {
-			// The updatedAt timestamp is in seconds, so if the existing record has
-			// the same timestamp as the new record, and the status is "completed",
-			// we can safely ignore the update.
-			return nil
+
+		if err := fn(record); err != nil {
+			return err
 		}
+	}
 
-		if _, err := handle.NamedExec(
-			updateWorkflowJobQuery,
-			record,
+	return rows.Err()
+}
+
+// getWorkflowJobStats retrieves aggregated workflow job stats from the
+// database. The query may return multiple rows per job which get merged.
+func getWorkflowJobStats(handle *sqlx.DB, query string, window time.Duration) ([]*WorkflowJobStats, error) {
+	records := make([]*WorkflowJobStats, 0)
+	mapping := make(map[[4]string]*WorkflowJobStats)
+
+	rows, err := handle.NamedQuery(
+		query,
+		map[string]interface{}{
+			"window": time.Now().Add(-window).Unix(),
+		},
+	)
+
+	if err != nil {
+		return records, err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		row := &workflowJobStatsRow{}
+
+		if err := rows.StructScan(
+			row,
 		); err != nil {
-			return fmt.Errorf("failed to update record: %w", err)
+			return records, err
+		}
+
+		key := [4]string{row.Owner, row.Repo, row.WorkflowName, row.Name}
+		record, ok := mapping[key]
+
+		if !ok {
+			record = &WorkflowJobStats{
+				Owner:        row.Owner,
+				Repo:         row.Repo,
+				WorkflowName: row.WorkflowName,
+				Name:         row.Name,
+			}
+
+			mapping[key] = record
+			records = append(records, record)
+		}
+
+		record.Total += row.Total
+		record.Duration += row.Duration
+
+		if row.MaxDuration > record.MaxDuration {
+			record.MaxDuration = row.MaxDuration
+		}
+
+		switch row.Conclusion {
+		case "failure", "timed_out":
+			record.Failures += row.Total
 		}
 	}
 
-	return nil
+	if err := rows.Err(); err != nil {
+		return records, err
+	}
+
+	return records, nil
 }
 
-// getWorkflowJobs retrieves the workflow jobs from the database.
-func getWorkflowJobs(handle *sqlx.DB, window time.Duration) ([]*WorkflowJob, error) {
+// workflowJobStatsRow defines a single row of the workflow job stats query.
+type workflowJobStatsRow struct {
+	WorkflowJobStats
+	Conclusion string `db:"conclusion"`
+}
+
+// countWorkflowJobs counts all stored workflow jobs.
+func countWorkflowJobs(handle *sqlx.DB) (int64, error) {
+	total := int64(0)
+
+	if err := handle.Get(
+		&total,
+		countWorkflowJobsQuery,
+	); err != nil {
+		return 0, fmt.Errorf("failed to count workflow jobs: %w", err)
+	}
+
+	return total, nil
+}
+
+// listWorkflowJobs retrieves a page of all stored workflow jobs.
+func listWorkflowJobs(handle *sqlx.DB, query string, offset, limit int) ([]*WorkflowJob, error) {
 	records := make([]*WorkflowJob, 0)
 
 	rows, err := handle.NamedQuery(
-		selectWorkflowJobsQuery,
+		query,
 		map[string]interface{}{
-			"window": time.Now().Add(-window).Unix(),
+			"offset": offset,
+			"limit":  limit,
 		},
 	)
 
@@ -120,15 +346,26 @@ func getWorkflowJobs(handle *sqlx.DB, window time.Duration) ([]*WorkflowJob, err
 	return records, nil
 }
 
-// pruneWorkflowJobs prunes older workflow job records.
-func pruneWorkflowJobs(handle *sqlx.DB, timeframe time.Duration) error {
-	if _, err := handle.NamedExec(
-		purgeWorkflowJobsQuery,
-		map[string]interface{}{
-			"timeframe": time.Now().Add(-timeframe).Unix(),
-		},
-	); err != nil {
-		return fmt.Errorf("failed to prune workflow jobs: %w", err)
+// importWorkflowJobs writes the records within a single transaction.
+func importWorkflowJobs(handle *sqlx.DB, upsert string, records []*WorkflowJob) error {
+	tx, err := handle.Beginx()
+
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	for _, record := range records {
+		if _, err := tx.NamedExec(
+			upsert,
+			record,
+		); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to import record: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
@@ -162,11 +399,67 @@ WHERE
 ORDER BY
 	created_at ASC;`
 
+// walkWorkflowJobsQuery returns the newest records first, that way a limit drops
+// the oldest records.
+var walkWorkflowJobsQuery = strings.TrimSuffix(selectWorkflowJobsQuery, "ASC;") + "DESC;"
+
+var listWorkflowJobsQuery = `
+SELECT
+	owner,
+	repo,
+	name,
+	status,
+	conclusion,
+	branch,
+	sha,
+	identifier,
+	run_id,
+	run_attempt,
+	created_at,
+	started_at,
+	completed_at,
+	labels,
+	runner_id,
+	runner_name,
+	runner_group_id,
+	runner_group_name,
+	workflow_name
+FROM
+	workflow_jobs
+ORDER BY
+	owner, repo, identifier
+LIMIT :limit OFFSET :offset;`
+
+var countWorkflowJobsQuery = `
+SELECT
+	COUNT(*)
+FROM
+	workflow_jobs;`
+
+var selectWorkflowJobStatsQuery = `
+SELECT
+	owner,
+	repo,
+	workflow_name,
+	name,
+	conclusion,
+	COUNT(*) AS total,
+	SUM(completed_at - started_at) AS duration,
+	MAX(completed_at - started_at) AS max_duration
+FROM
+	workflow_jobs
+WHERE
+	created_at > :window AND status = 'completed'
+GROUP BY
+	owner, repo, workflow_name, name, conclusion;`
+
 var findWorkflowJobQuery = `
 SELECT
 	identifier,
+	status,
 	created_at,
-	status
+	started_at,
+	completed_at
 FROM
 	workflow_jobs
 WHERE
@@ -215,6 +508,26 @@ INSERT INTO workflow_jobs (
 	:workflow_name
 );`
 
+// upsertWorkflowJobQuery is used by PostgreSQL and SQLite, the guard mirrors
+// the jobRegression function.
+var upsertWorkflowJobQuery = strings.TrimSuffix(createWorkflowJobQuery, ";") + `
+ON CONFLICT (owner, repo, identifier) DO UPDATE SET
+	run_attempt=excluded.run_attempt,
+	conclusion=excluded.conclusion,
+	name=excluded.name,
+	status=excluded.status,
+	branch=excluded.branch,
+	sha=excluded.sha,
+	created_at=excluded.created_at,
+	started_at=excluded.started_at,
+	completed_at=excluded.completed_at,
+	runner_id=excluded.runner_id,
+	runner_name=excluded.runner_name,
+	runner_group_id=excluded.runner_group_id,
+	runner_group_name=excluded.runner_group_name
+WHERE
+	` + jobProgressExpr("workflow_jobs.%s", "excluded.%s") + `;`
+
 var updateWorkflowJobQuery = `
 UPDATE
 	workflow_jobs
@@ -241,3 +554,15 @@ DELETE FROM
 	workflow_jobs
 WHERE
 	created_at < :timeframe;`
+
+var purgeWorkflowJobsRepoQuery = `
+DELETE FROM
+	workflow_jobs
+WHERE
+	owner=:owner AND repo=:repo AND created_at < :timeframe;`
+
+var reposWorkflowJobsQuery = `
+SELECT DISTINCT
+	owner, repo
+FROM
+	workflow_jobs;`
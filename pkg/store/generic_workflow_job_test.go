@@ -0,0 +1,65 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobRegression(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing *WorkflowJob
+		record   *WorkflowJob
+		expected bool
+	}{
+		{
+			name:     "queued after in_progress",
+			existing: &WorkflowJob{Status: "in_progress", StartedAt: 20},
+			record:   &WorkflowJob{Status: "queued"},
+			expected: true,
+		},
+		{
+			name:     "in_progress after completed",
+			existing: &WorkflowJob{Status: "completed", StartedAt: 20, CompletedAt: 30},
+			record:   &WorkflowJob{Status: "in_progress", StartedAt: 20},
+			expected: true,
+		},
+		{
+			name:     "waiting after queued",
+			existing: &WorkflowJob{Status: "queued"},
+			record:   &WorkflowJob{Status: "waiting"},
+			expected: false,
+		},
+		{
+			name:     "completed after in_progress",
+			existing: &WorkflowJob{Status: "in_progress", StartedAt: 20},
+			record:   &WorkflowJob{Status: "completed", StartedAt: 20, CompletedAt: 30},
+			expected: false,
+		},
+		{
+			name:     "older completion",
+			existing: &WorkflowJob{Status: "completed", StartedAt: 20, CompletedAt: 30},
+			record:   &WorkflowJob{Status: "completed", StartedAt: 20, CompletedAt: 25},
+			expected: true,
+		},
+		{
+			name:     "redelivered completion",
+			existing: &WorkflowJob{Status: "completed", StartedAt: 20, CompletedAt: 30},
+			record:   &WorkflowJob{Status: "completed", StartedAt: 20, CompletedAt: 30},
+			expected: false,
+		},
+		{
+			name:     "older start",
+			existing: &WorkflowJob{Status: "in_progress", StartedAt: 20},
+			record:   &WorkflowJob{Status: "in_progress", StartedAt: 10},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, jobRegression(tt.existing, tt.record))
+		})
+	}
+}
@@ -0,0 +1,345 @@
+package store
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// workflowRunRollupKey identifies a single hourly rollup.
+type workflowRunRollupKey struct {
+	owner      string
+	repo       string
+	workflowID int64
+	bucket     int64
+}
+
+// rollupStart returns the beginning of the oldest hour which gets
+// re-aggregated for the timeframe.
+func rollupStart(timeframe time.Duration) int64 {
+	return time.Now().Add(-timeframe).Truncate(time.Hour).Unix()
+}
+
+// addWorkflowRunRollup adds a finished workflow run to the rollup of the hour
+// it has been updated last, unfinished runs and runs updated before the start
+// are ignored.
+func addWorkflowRunRollup(rollups map[workflowRunRollupKey]*WorkflowRunRollup, start int64, record *WorkflowRun) {
+	if record.UpdatedAt < start {
+		return
+	}
+
+	switch record.Status {
+	case "", "completed", "queued", "in_progress", "waiting", "requested", "pending":
+		return
+	}
+
+	bucket := time.Unix(record.UpdatedAt, 0).Truncate(time.Hour).Unix()
+	key := workflowRunRollupKey{record.Owner, record.Repo, record.WorkflowID, bucket}
+	rollup, ok := rollups[key]
+
+	if !ok {
+		rollup = &WorkflowRunRollup{
+			Owner:      record.Owner,
+			Repo:       record.Repo,
+			WorkflowID: record.WorkflowID,
+			Name:       record.Name,
+			Bucket:     bucket,
+		}
+
+		rollups[key] = rollup
+	}
+
+	rollup.Total++
+	rollup.Duration += record.UpdatedAt - record.StartedAt
+
+	switch record.Status {
+	case "success":
+		rollup.Success++
+	case "failure", "timed_out", "startup_failure":
+		rollup.Failure++
+	}
+}
+
+// sortedWorkflowRunRollups returns the rollups ordered by their hour.
+func sortedWorkflowRunRollups(rollups map[workflowRunRollupKey]*WorkflowRunRollup) []*WorkflowRunRollup {
+	records := make([]*WorkflowRunRollup, 0, len(rollups))
+
+	for _, rollup := range rollups {
+		records = append(records, rollup)
+	}
+
+	slices.SortStableFunc(records, func(a, b *WorkflowRunRollup) int {
+		return cmp.Compare(a.Bucket, b.Bucket)
+	})
+
+	return records
+}
+
+// rollupWorkflowRuns aggregates the workflow runs of the timeframe and
+// replaces all rollups since the start within a single transaction, that way
+// runs which moved to a later hour are not counted twice.
+func rollupWorkflowRuns(handle *sqlx.DB, timeframe time.Duration) (int64, error) {
+	start := rollupStart(timeframe)
+	rollups := make(map[workflowRunRollupKey]*WorkflowRunRollup)
+
+	if err := walkWorkflowRuns(
+		handle,
+		selectWorkflowRunsQuery,
+		time.Since(time.Unix(start, 0))+time.Second,
+		0,
+		func(record *WorkflowRun) error {
+			addWorkflowRunRollup(rollups, start, record)
+			return nil
+		},
+	); err != nil {
+		return 0, fmt.Errorf("failed to aggregate workflow runs: %w", err)
+	}
+
+	tx, err := handle.Beginx()
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	if _, err := tx.NamedExec(
+		deleteWorkflowRunRollupsQuery,
+		map[string]interface{}{
+			"bucket": start,
+		},
+	); err != nil {
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("failed to delete rollups: %w", err)
+	}
+
+	for _, rollup := range sortedWorkflowRunRollups(rollups) {
+		if _, err := tx.NamedExec(
+			createWorkflowRunRollupQuery,
+			rollup,
+		); err != nil {
+			_ = tx.Rollback()
+			return 0, fmt.Errorf("failed to create rollup: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return int64(len(rollups)), nil
+}
+
+// getWorkflowRunRollups retrieves the hourly rollups of the window.
+func getWorkflowRunRollups(handle *sqlx.DB, window time.Duration) ([]*WorkflowRunRollup, error) {
+	records := make([]*WorkflowRunRollup, 0)
+
+	rows, err := handle.NamedQuery(
+		selectWorkflowRunRollupsQuery,
+		map[string]interface{}{
+			"window": time.Now().Add(-window).Unix(),
+		},
+	)
+
+	if err != nil {
+		return records, err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		record := &WorkflowRunRollup{}
+
+		if err := rows.StructScan(
+			record,
+		); err != nil {
+			return records, err
+		}
+
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return records, err
+	}
+
+	return records, nil
+}
+
+// pruneWorkflowRunRollups prunes older workflow run rollups.
+func pruneWorkflowRunRollups(handle *sqlx.DB, query string, timeframe time.Duration, limit int) (int64, error) {
+	total, err := pruneRecords(handle, query, timeframe, limit)
+
+	if err != nil {
+		return total, fmt.Errorf("failed to prune workflow run rollups: %w", err)
+	}
+
+	return total, nil
+}
+
+// countWorkflowRunRollups counts all stored workflow run rollups.
+func countWorkflowRunRollups(handle *sqlx.DB) (int64, error) {
+	total := int64(0)
+
+	if err := handle.Get(
+		&total,
+		countWorkflowRunRollupsQuery,
+	); err != nil {
+		return 0, fmt.Errorf("failed to count workflow run rollups: %w", err)
+	}
+
+	return total, nil
+}
+
+// listWorkflowRunRollups retrieves a page of all stored workflow run rollups.
+func listWorkflowRunRollups(handle *sqlx.DB, query string, offset, limit int) ([]*WorkflowRunRollup, error) {
+	records := make([]*WorkflowRunRollup, 0)
+
+	rows, err := handle.NamedQuery(
+		query,
+		map[string]interface{}{
+			"offset": offset,
+			"limit":  limit,
+		},
+	)
+
+	if err != nil {
+		return records, err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		record := &WorkflowRunRollup{}
+
+		if err := rows.StructScan(
+			record,
+		); err != nil {
+			return records, err
+		}
+
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return records, err
+	}
+
+	return records, nil
+}
+
+// importWorkflowRunRollups replaces the records within a single transaction.
+func importWorkflowRunRollups(handle *sqlx.DB, records []*WorkflowRunRollup) error {
+	tx, err := handle.Beginx()
+
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	for _, record := range records {
+		if _, err := tx.NamedExec(
+			deleteWorkflowRunRollupQuery,
+			record,
+		); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to import record: %w", err)
+		}
+
+		if _, err := tx.NamedExec(
+			createWorkflowRunRollupQuery,
+			record,
+		); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to import record: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+var selectWorkflowRunRollupsQuery = `
+SELECT
+	owner,
+	repo,
+	workflow_id,
+	name,
+	bucket,
+	total,
+	success,
+	failure,
+	duration
+FROM
+	workflow_run_rollups
+WHERE
+	bucket > :window
+ORDER BY
+	bucket ASC;`
+
+var listWorkflowRunRollupsQuery = `
+SELECT
+	owner,
+	repo,
+	workflow_id,
+	name,
+	bucket,
+	total,
+	success,
+	failure,
+	duration
+FROM
+	workflow_run_rollups
+ORDER BY
+	owner, repo, workflow_id, bucket
+LIMIT :limit OFFSET :offset;`
+
+var countWorkflowRunRollupsQuery = `
+SELECT
+	COUNT(*)
+FROM
+	workflow_run_rollups;`
+
+var createWorkflowRunRollupQuery = `
+INSERT INTO workflow_run_rollups (
+	owner,
+	repo,
+	workflow_id,
+	name,
+	bucket,
+	total,
+	success,
+	failure,
+	duration
+) VALUES (
+	:owner,
+	:repo,
+	:workflow_id,
+	:name,
+	:bucket,
+	:total,
+	:success,
+	:failure,
+	:duration
+);`
+
+var deleteWorkflowRunRollupQuery = `
+DELETE FROM
+	workflow_run_rollups
+WHERE
+	owner=:owner AND repo=:repo AND workflow_id=:workflow_id AND bucket=:bucket;`
+
+var deleteWorkflowRunRollupsQuery = `
+DELETE FROM
+	workflow_run_rollups
+WHERE
+	bucket >= :bucket;`
+
+var purgeWorkflowRunRollupsQuery = `
+DELETE FROM
+	workflow_run_rollups
+WHERE
+	bucket < :timeframe;`
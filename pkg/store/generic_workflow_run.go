@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/go-github/v90/github"
@@ -11,11 +12,21 @@ import (
 )
 
 // storeWorkflowRunEvent handles workflow_run events from GitHub.
-func storeWorkflowRunEvent(handle *sqlx.DB, event *github.WorkflowRunEvent) error {
-	createdAt := event.GetWorkflowRun().GetCreatedAt().Unix()
-	updatedAt := event.GetWorkflowRun().GetUpdatedAt().Unix()
-	startedAt := event.GetWorkflowRun().GetRunStartedAt().Unix()
+func storeWorkflowRunEvent(handle *sqlx.DB, upsert string, event *github.WorkflowRunEvent) error {
+	record := workflowRunFromEvent(event)
 
+	if _, err := handle.NamedExec(
+		upsert,
+		record,
+	); err != nil {
+		return fmt.Errorf("failed to upsert record: %w", err)
+	}
+
+	return resolveWorkflowRunEvent(handle, event, record)
+}
+
+// workflowRunFromEvent converts a workflow_run event to a record.
+func workflowRunFromEvent(event *github.WorkflowRunEvent) *WorkflowRun {
 	record := &WorkflowRun{
 		Owner:      event.GetRepo().GetOwner().GetLogin(),
 		Repo:       event.GetRepo().GetName(),
@@ -30,67 +41,158 @@ func storeWorkflowRunEvent(handle *sqlx.DB, event *github.WorkflowRunEvent) erro
 		SHA:        event.GetWorkflowRun().GetHeadSHA(),
 		Identifier: event.GetWorkflowRun().GetID(),
 		Actor:      event.GetWorkflowRun().GetActor().GetLogin(),
-		CreatedAt:  createdAt,
-		UpdatedAt:  updatedAt,
-		StartedAt:  startedAt,
+		CreatedAt:  event.GetWorkflowRun().GetCreatedAt().Unix(),
+		UpdatedAt:  event.GetWorkflowRun().GetUpdatedAt().Unix(),
+		StartedAt:  event.GetWorkflowRun().GetRunStartedAt().Unix(),
 	}
 
 	if record.Status == "" {
 		record.Status = event.GetWorkflowRun().GetStatus()
 	}
 
-	return createOrUpdateWorkflowRun(handle, record)
+	return record
 }
 
-// createOrUpdateWorkflowRun creates or updates the record.
-func createOrUpdateWorkflowRun(handle *sqlx.DB, record *WorkflowRun) error {
-	existing := &WorkflowRun{}
-	stmt, err := handle.PrepareNamed(findWorkflowRunQuery)
-
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		return fmt.Errorf("failed to prepare find: %w", err)
+// resolveWorkflowRunEvent resolves pending deployment reviews of a run which
+// continues its execution. Events older than the stored run got delivered out
+// of order and are skipped, only reviews requested before the event resolve
+// unless the run has been completed.
+func resolveWorkflowRunEvent(handle *sqlx.DB, event *github.WorkflowRunEvent, record *WorkflowRun) error {
+	if !workflowRunResolves(event) {
+		return nil
 	}
 
-	if err := stmt.Get(existing, record); err != nil && !errors.Is(err, sql.ErrNoRows) {
+	latest := int64(0)
+
+	if err := handle.Get(
+		&latest,
+		handle.Rebind(latestWorkflowRunQuery),
+		record.Owner,
+		record.Repo,
+		record.Identifier,
+	); err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return fmt.Errorf("failed to find record: %w", err)
 	}
 
-	if existing.Identifier == 0 {
-		if _, err := handle.NamedExec(
-			createWorkflowRunQuery,
-			record,
-		); err != nil {
-			return fmt.Errorf("failed to create record: %w", err)
-		}
-	} else {
-		if existing.UpdatedAt > record.UpdatedAt {
-			return nil
-		} else if existing.UpdatedAt == record.UpdatedAt && existing.Status == "completed" {
-			// The updatedAt timestamp is in seconds, so if the existing record has
-			// the same timestamp as the new record, and the status is "completed",
-			// we can safely ignore the update.
+	if latest > record.UpdatedAt {
+		return nil
+	}
+
+	return resolveDeploymentReviews(
+		handle,
+		record.Owner,
+		record.Repo,
+		record.Identifier,
+		record.UpdatedAt,
+		event.GetWorkflowRun().GetStatus() == "completed",
+	)
+}
+
+// workflowRunResolves checks if the event continues the execution of the run.
+func workflowRunResolves(event *github.WorkflowRunEvent) bool {
+	switch event.GetWorkflowRun().GetStatus() {
+	case "in_progress", "completed":
+		return true
+	}
+
+	return false
+}
+
+// workflowRunOutdated checks if the record is older than the stored run. The
+// updatedAt timestamp is in seconds, so if the existing record has the same
+// timestamp as the new record, and the status is "completed", we can safely
+// ignore the update.
+func workflowRunOutdated(existing, record *WorkflowRun) bool {
+	if existing.UpdatedAt > record.UpdatedAt {
+		return true
+	}
+
+	return existing.UpdatedAt == record.UpdatedAt && existing.Status == "completed"
+}
+
+// getWorkflowRuns retrieves the workflow runs from the database.
+func getWorkflowRuns(handle *sqlx.DB, window time.Duration) ([]*WorkflowRun, error) {
+	records := make([]*WorkflowRun, 0)
+
+	if err := walkWorkflowRuns(
+		handle,
+		selectWorkflowRunsQuery,
+		window,
+		0,
+		func(record *WorkflowRun) error {
+			records = append(
+				records,
+				record,
+			)
+
 			return nil
+		},
+	); err != nil {
+		return records, err
+	}
+
+	return records, nil
+}
+
+// walkWorkflowRuns executes the callback for every workflow run while scanning the
+// result, ErrLimitReached gets returned if more rows than the limit exist.
+func walkWorkflowRuns(handle *sqlx.DB, query string, window time.Duration, limit int, fn func(*WorkflowRun) error) error {
+	query, params := walkConditions(query, window, limit)
+	rows, err := handle.NamedQuery(
+		query,
+		params,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	for count := 0; rows.Next(); count++ {
+		if limit > 0 && count >= limit {
+			return ErrLimitReached
 		}
 
-		if _, err := handle.NamedExec(
-			updateWorkflowRunQuery,
+		record := &WorkflowRun{}
+
+		if err := rows.StructScan(
 			record,
 		); err != nil {
-			return fmt.Errorf("failed to update record: %w", err)
+			return err
+		}
+
+		if err := fn(record); err != nil {
+			return err
 		}
 	}
 
-	return nil
+	return rows.Err()
 }
 
-// getWorkflowRuns retrieves the workflow runs from the database.
-func getWorkflowRuns(handle *sqlx.DB, window time.Duration) ([]*WorkflowRun, error) {
+// countWorkflowRuns counts all stored workflow runs.
+func countWorkflowRuns(handle *sqlx.DB) (int64, error) {
+	total := int64(0)
+
+	if err := handle.Get(
+		&total,
+		countWorkflowRunsQuery,
+	); err != nil {
+		return 0, fmt.Errorf("failed to count workflow runs: %w", err)
+	}
+
+	return total, nil
+}
+
+// listWorkflowRuns retrieves a page of all stored workflow runs.
+func listWorkflowRuns(handle *sqlx.DB, query string, offset, limit int) ([]*WorkflowRun, error) {
 	records := make([]*WorkflowRun, 0)
 
 	rows, err := handle.NamedQuery(
-		selectWorkflowRunsQuery,
+		query,
 		map[string]interface{}{
-			"window": time.Now().Add(-window).Unix(),
+			"offset": offset,
+			"limit":  limit,
 		},
 	)
 
@@ -122,15 +224,26 @@ func getWorkflowRuns(handle *sqlx.DB, window time.Duration) ([]*WorkflowRun, err
 	return records, nil
 }
 
-// pruneWorkflowRuns prunes older workflow run records.
-func pruneWorkflowRuns(handle *sqlx.DB, timeframe time.Duration) error {
-	if _, err := handle.NamedExec(
-		purgeWorkflowRunsQuery,
-		map[string]interface{}{
-			"timeframe": time.Now().Add(-timeframe).Unix(),
-		},
-	); err != nil {
-		return fmt.Errorf("failed to prune workflow runs: %w", err)
+// importWorkflowRuns writes the records within a single transaction.
+func importWorkflowRuns(handle *sqlx.DB, upsert string, records []*WorkflowRun) error {
+	tx, err := handle.Beginx()
+
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	for _, record := range records {
+		if _, err := tx.NamedExec(
+			upsert,
+			record,
+		); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to import record: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
@@ -161,6 +274,40 @@ WHERE
 ORDER BY
 	updated_at ASC;`
 
+// walkWorkflowRunsQuery returns the newest records first, that way a limit drops
+// the oldest records.
+var walkWorkflowRunsQuery = strings.TrimSuffix(selectWorkflowRunsQuery, "ASC;") + "DESC;"
+
+var listWorkflowRunsQuery = `
+SELECT
+	owner,
+	repo,
+	workflow_id,
+	number,
+	attempt,
+	event,
+	name,
+	title,
+	status,
+	branch,
+	sha,
+	identifier,
+	actor,
+	created_at,
+	updated_at,
+	started_at
+FROM
+	workflow_runs
+ORDER BY
+	owner, repo, workflow_id, number
+LIMIT :limit OFFSET :offset;`
+
+var countWorkflowRunsQuery = `
+SELECT
+	COUNT(*)
+FROM
+	workflow_runs;`
+
 var findWorkflowRunQuery = `
 SELECT
 	identifier,
@@ -208,6 +355,26 @@ INSERT INTO workflow_runs (
 	:started_at
 );`
 
+// upsertWorkflowRunQuery is used by PostgreSQL and SQLite, the guard mirrors
+// a negated workflowRunOutdated function.
+var upsertWorkflowRunQuery = strings.TrimSuffix(createWorkflowRunQuery, ";") + `
+ON CONFLICT (owner, repo, workflow_id, number) DO UPDATE SET
+	attempt=excluded.attempt,
+	event=excluded.event,
+	name=excluded.name,
+	title=excluded.title,
+	status=excluded.status,
+	branch=excluded.branch,
+	sha=excluded.sha,
+	identifier=excluded.identifier,
+	actor=excluded.actor,
+	created_at=excluded.created_at,
+	updated_at=excluded.updated_at,
+	started_at=excluded.started_at
+WHERE
+	workflow_runs.updated_at < excluded.updated_at OR
+	(workflow_runs.updated_at = excluded.updated_at AND workflow_runs.status <> 'completed');`
+
 var updateWorkflowRunQuery = `
 UPDATE
 	workflow_runs
@@ -227,8 +394,28 @@ SET
 WHERE
 	owner=:owner AND repo=:repo AND workflow_id=:workflow_id AND number=:number;`
 
+var latestWorkflowRunQuery = `
+SELECT
+	updated_at
+FROM
+	workflow_runs
+WHERE
+	owner=? AND repo=? AND identifier=?;`
+
 var purgeWorkflowRunsQuery = `
 DELETE FROM
 	workflow_runs
 WHERE
 	updated_at < :timeframe;`
+
+var purgeWorkflowRunsRepoQuery = `
+DELETE FROM
+	workflow_runs
+WHERE
+	owner=:owner AND repo=:repo AND updated_at < :timeframe;`
+
+var reposWorkflowRunsQuery = `
+SELECT DISTINCT
+	owner, repo
+FROM
+	workflow_runs;`
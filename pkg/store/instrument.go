@@ -0,0 +1,158 @@
+package store
+
+import (
+	"time"
+
+	"github.com/google/go-github/v90/github"
+)
+
+// Observer gets called with the operation, the table and the latency of an
+// instrumented store call.
+type Observer func(operation, table string, duration time.Duration)
+
+// instrumentedStore wraps a store to observe the latency of store, get and
+// prune operations, all other methods are passed through.
+type instrumentedStore struct {
+	Store
+
+	observe Observer
+}
+
+// Instrument wraps the store to report the latency of the store, get and
+// prune operations to the observer.
+func Instrument(s Store, observe Observer) Store {
+	return &instrumentedStore{
+		Store:   s,
+		observe: observe,
+	}
+}
+
+// StoreWorkflowRunEvent implements the Store interface.
+func (s *instrumentedStore) StoreWorkflowRunEvent(event *github.WorkflowRunEvent) error {
+	defer s.track("store", "workflow_runs", time.Now())
+	return s.Store.StoreWorkflowRunEvent(event)
+}
+
+// GetWorkflowRuns implements the Store interface.
+func (s *instrumentedStore) GetWorkflowRuns(window time.Duration) ([]*WorkflowRun, error) {
+	defer s.track("get", "workflow_runs", time.Now())
+	return s.Store.GetWorkflowRuns(window)
+}
+
+// WalkWorkflowRuns implements the Store interface.
+func (s *instrumentedStore) WalkWorkflowRuns(window time.Duration, limit int, fn func(*WorkflowRun) error) error {
+	defer s.track("get", "workflow_runs", time.Now())
+	return s.Store.WalkWorkflowRuns(window, limit, fn)
+}
+
+// PruneWorkflowRuns implements the Store interface.
+func (s *instrumentedStore) PruneWorkflowRuns(retention Retention, limit int) (map[string]int64, error) {
+	defer s.track("prune", "workflow_runs", time.Now())
+	return s.Store.PruneWorkflowRuns(retention, limit)
+}
+
+// QueryWorkflowRuns implements the Store interface.
+func (s *instrumentedStore) QueryWorkflowRuns(filter *Filter) ([]*WorkflowRun, error) {
+	defer s.track("get", "workflow_runs", time.Now())
+	return s.Store.QueryWorkflowRuns(filter)
+}
+
+// StoreWorkflowJobEvent implements the Store interface.
+func (s *instrumentedStore) StoreWorkflowJobEvent(event *github.WorkflowJobEvent) error {
+	defer s.track("store", "workflow_jobs", time.Now())
+	return s.Store.StoreWorkflowJobEvent(event)
+}
+
+// GetWorkflowJobs implements the Store interface.
+func (s *instrumentedStore) GetWorkflowJobs(window time.Duration) ([]*WorkflowJob, error) {
+	defer s.track("get", "workflow_jobs", time.Now())
+	return s.Store.GetWorkflowJobs(window)
+}
+
+// WalkWorkflowJobs implements the Store interface.
+func (s *instrumentedStore) WalkWorkflowJobs(window time.Duration, limit int, fn func(*WorkflowJob) error) error {
+	defer s.track("get", "workflow_jobs", time.Now())
+	return s.Store.WalkWorkflowJobs(window, limit, fn)
+}
+
+// GetWorkflowJobStats implements the Store interface.
+func (s *instrumentedStore) GetWorkflowJobStats(window time.Duration) ([]*WorkflowJobStats, error) {
+	defer s.track("get", "workflow_jobs", time.Now())
+	return s.Store.GetWorkflowJobStats(window)
+}
+
+// PruneWorkflowJobs implements the Store interface.
+func (s *instrumentedStore) PruneWorkflowJobs(retention Retention, limit int) (map[string]int64, error) {
+	defer s.track("prune", "workflow_jobs", time.Now())
+	return s.Store.PruneWorkflowJobs(retention, limit)
+}
+
+// QueryWorkflowJobs implements the Store interface.
+func (s *instrumentedStore) QueryWorkflowJobs(filter *Filter) ([]*WorkflowJob, error) {
+	defer s.track("get", "workflow_jobs", time.Now())
+	return s.Store.QueryWorkflowJobs(filter)
+}
+
+// RollupWorkflowRuns implements the Store interface.
+func (s *instrumentedStore) RollupWorkflowRuns(timeframe time.Duration) (int64, error) {
+	defer s.track("store", "workflow_run_rollups", time.Now())
+	return s.Store.RollupWorkflowRuns(timeframe)
+}
+
+// GetWorkflowRunRollups implements the Store interface.
+func (s *instrumentedStore) GetWorkflowRunRollups(window time.Duration) ([]*WorkflowRunRollup, error) {
+	defer s.track("get", "workflow_run_rollups", time.Now())
+	return s.Store.GetWorkflowRunRollups(window)
+}
+
+// PruneWorkflowRunRollups implements the Store interface.
+func (s *instrumentedStore) PruneWorkflowRunRollups(timeframe time.Duration, limit int) (int64, error) {
+	defer s.track("prune", "workflow_run_rollups", time.Now())
+	return s.Store.PruneWorkflowRunRollups(timeframe, limit)
+}
+
+// StoreDeploymentProtectionRuleEvent implements the Store interface.
+func (s *instrumentedStore) StoreDeploymentProtectionRuleEvent(event *github.DeploymentProtectionRuleEvent) error {
+	defer s.track("store", "deployment_reviews", time.Now())
+	return s.Store.StoreDeploymentProtectionRuleEvent(event)
+}
+
+// StoreDeploymentReviewEvent implements the Store interface.
+func (s *instrumentedStore) StoreDeploymentReviewEvent(event *github.DeploymentReviewEvent) error {
+	defer s.track("store", "deployment_reviews", time.Now())
+	return s.Store.StoreDeploymentReviewEvent(event)
+}
+
+// GetDeploymentReviews implements the Store interface.
+func (s *instrumentedStore) GetDeploymentReviews(window time.Duration) ([]*DeploymentReview, error) {
+	defer s.track("get", "deployment_reviews", time.Now())
+	return s.Store.GetDeploymentReviews(window)
+}
+
+// PruneDeploymentReviews implements the Store interface.
+func (s *instrumentedStore) PruneDeploymentReviews(timeframe time.Duration, limit int) (int64, error) {
+	defer s.track("prune", "deployment_reviews", time.Now())
+	return s.Store.PruneDeploymentReviews(timeframe, limit)
+}
+
+// GetCacheEntry implements the Store interface.
+func (s *instrumentedStore) GetCacheEntry(key string) (*CacheEntry, error) {
+	defer s.track("get", "http_cache", time.Now())
+	return s.Store.GetCacheEntry(key)
+}
+
+// StoreCacheEntry implements the Store interface.
+func (s *instrumentedStore) StoreCacheEntry(record *CacheEntry) error {
+	defer s.track("store", "http_cache", time.Now())
+	return s.Store.StoreCacheEntry(record)
+}
+
+// PruneCacheEntries implements the Store interface.
+func (s *instrumentedStore) PruneCacheEntries(timeframe time.Duration, limit int) (int64, error) {
+	defer s.track("prune", "http_cache", time.Now())
+	return s.Store.PruneCacheEntries(timeframe, limit)
+}
+
+func (s *instrumentedStore) track(operation, table string, started time.Time) {
+	s.observe(operation, table, time.Since(started))
+}
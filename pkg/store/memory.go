@@ -0,0 +1,868 @@
+package store
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+)
+
+func init() {
+	register("memory", NewMemoryStore)
+}
+
+type memoryRunKey struct {
+	owner      string
+	repo       string
+	workflowID int64
+	number     int
+}
+
+type memoryJobKey struct {
+	owner      string
+	repo       string
+	identifier int64
+}
+
+type memoryReviewKey struct {
+	owner       string
+	repo        string
+	runID       int64
+	environment string
+}
+
+// memoryStore implements the Store interface without any persistence.
+type memoryStore struct {
+	logger  *slog.Logger
+	limit   int
+	mutex   sync.RWMutex
+	runs    map[memoryRunKey]*WorkflowRun
+	jobs    map[memoryJobKey]*WorkflowJob
+	reviews map[memoryReviewKey]*DeploymentReview
+	rollups map[workflowRunRollupKey]*WorkflowRunRollup
+	cache   map[string]*CacheEntry
+}
+
+// Open simply opens the database connection.
+func (s *memoryStore) Open() (bool, error) {
+	return true, nil
+}
+
+// Close simply closes the database connection.
+func (s *memoryStore) Close() error {
+	return nil
+}
+
+// Ping just tests the database connection.
+func (s *memoryStore) Ping() (bool, error) {
+	return true, nil
+}
+
+// Migrate executes required db migrations.
+func (s *memoryStore) Migrate() error {
+	return nil
+}
+
+// Migrations returns the status of all db migrations.
+func (s *memoryStore) Migrations() ([]*Migration, error) {
+	return []*Migration{}, nil
+}
+
+// StoreWorkflowRunEvent implements the Store interface.
+func (s *memoryStore) StoreWorkflowRunEvent(event *github.WorkflowRunEvent) error {
+	record := workflowRunFromEvent(event)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := memoryRunKey{record.Owner, record.Repo, record.WorkflowID, record.Number}
+
+	if existing, ok := s.runs[key]; !ok || !workflowRunOutdated(existing, record) {
+		s.runs[key] = record
+		evictRecords(s.runs, s.limit, func(r *WorkflowRun) int64 { return r.UpdatedAt })
+	}
+
+	if !workflowRunResolves(event) {
+		return nil
+	}
+
+	if stored, ok := s.runs[key]; ok && stored.UpdatedAt > record.UpdatedAt {
+		return nil
+	}
+
+	completed := event.GetWorkflowRun().GetStatus() == "completed"
+
+	for _, review := range s.reviews {
+		if review.Owner == record.Owner && review.Repo == record.Repo && review.RunID == record.Identifier && review.Status == "pending" && (completed || review.RequestedAt < record.UpdatedAt) {
+			review.Status = "resolved"
+			review.ReviewedAt = record.UpdatedAt
+		}
+	}
+
+	return nil
+}
+
+// GetWorkflowRuns implements the Store interface.
+func (s *memoryStore) GetWorkflowRuns(window time.Duration) ([]*WorkflowRun, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	timeframe := time.Now().Add(-window).Unix()
+	records := make([]*WorkflowRun, 0)
+
+	for _, record := range s.runs {
+		if record.UpdatedAt > timeframe {
+			copied := *record
+			records = append(records, &copied)
+		}
+	}
+
+	slices.SortStableFunc(records, func(a, b *WorkflowRun) int {
+		return cmp.Compare(a.UpdatedAt, b.UpdatedAt)
+	})
+
+	return records, nil
+}
+
+// WalkWorkflowRuns implements the Store interface.
+func (s *memoryStore) WalkWorkflowRuns(window time.Duration, limit int, fn func(*WorkflowRun) error) error {
+	records, err := s.GetWorkflowRuns(window)
+
+	if err != nil {
+		return err
+	}
+
+	return walkMemory(records, limit, fn)
+}
+
+// PruneWorkflowRuns implements the Store interface.
+func (s *memoryStore) PruneWorkflowRuns(retention Retention, _ int) (map[string]int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return pruneMemoryRetention(s.runs, retention, func(r *WorkflowRun) (string, string, int64) { return r.Owner, r.Repo, r.UpdatedAt }), nil
+}
+
+// CountWorkflowRuns implements the Store interface.
+func (s *memoryStore) CountWorkflowRuns() (int64, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return int64(len(s.runs)), nil
+}
+
+// ListWorkflowRuns implements the Store interface.
+func (s *memoryStore) ListWorkflowRuns(offset, limit int) ([]*WorkflowRun, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	records := make([]*WorkflowRun, 0, len(s.runs))
+
+	for _, record := range s.runs {
+		copied := *record
+		records = append(records, &copied)
+	}
+
+	slices.SortFunc(records, func(a, b *WorkflowRun) int {
+		return cmp.Or(
+			cmp.Compare(a.Owner, b.Owner),
+			cmp.Compare(a.Repo, b.Repo),
+			cmp.Compare(a.WorkflowID, b.WorkflowID),
+			cmp.Compare(a.Number, b.Number),
+		)
+	})
+
+	return paginate(records, offset, limit), nil
+}
+
+// ImportWorkflowRuns implements the Store interface.
+func (s *memoryStore) ImportWorkflowRuns(records []*WorkflowRun) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, record := range records {
+		key := memoryRunKey{record.Owner, record.Repo, record.WorkflowID, record.Number}
+
+		if existing, ok := s.runs[key]; ok && workflowRunOutdated(existing, record) {
+			continue
+		}
+
+		copied := *record
+		s.runs[key] = &copied
+	}
+
+	evictRecords(s.runs, s.limit, func(r *WorkflowRun) int64 { return r.UpdatedAt })
+	return nil
+}
+
+// QueryWorkflowRuns implements the Store interface.
+func (s *memoryStore) QueryWorkflowRuns(filter *Filter) ([]*WorkflowRun, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	records := make([]*WorkflowRun, 0)
+
+	for _, record := range s.runs {
+		if filter.matchWorkflowRun(record) {
+			copied := *record
+			records = append(records, &copied)
+		}
+	}
+
+	slices.SortFunc(records, func(a, b *WorkflowRun) int {
+		return cmp.Or(
+			cmp.Compare(b.UpdatedAt, a.UpdatedAt),
+			cmp.Compare(a.Owner, b.Owner),
+			cmp.Compare(a.Repo, b.Repo),
+			cmp.Compare(a.WorkflowID, b.WorkflowID),
+			cmp.Compare(a.Number, b.Number),
+		)
+	})
+
+	if filter.Limit <= 0 {
+		return records, nil
+	}
+
+	return paginate(records, filter.Offset, filter.Limit), nil
+}
+
+// StoreWorkflowJobEvent implements the Store interface.
+func (s *memoryStore) StoreWorkflowJobEvent(event *github.WorkflowJobEvent) error {
+	record := workflowJobFromEvent(event)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := memoryJobKey{record.Owner, record.Repo, record.Identifier}
+
+	if existing, ok := s.jobs[key]; ok {
+		if jobRegression(existing, record) {
+			return outdatedWorkflowJob(existing, record)
+		}
+
+		// Updates don't touch these columns within the SQL drivers.
+		record.RunID = existing.RunID
+		record.Labels = existing.Labels
+		record.WorkflowName = existing.WorkflowName
+	}
+
+	s.jobs[key] = record
+	evictRecords(s.jobs, s.limit, func(r *WorkflowJob) int64 { return r.CreatedAt })
+
+	return nil
+}
+
+// GetWorkflowJobs implements the Store interface.
+func (s *memoryStore) GetWorkflowJobs(window time.Duration) ([]*WorkflowJob, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	timeframe := time.Now().Add(-window).Unix()
+	records := make([]*WorkflowJob, 0)
+
+	for _, record := range s.jobs {
+		if record.CreatedAt > timeframe {
+			copied := *record
+			records = append(records, &copied)
+		}
+	}
+
+	slices.SortStableFunc(records, func(a, b *WorkflowJob) int {
+		return cmp.Compare(a.CreatedAt, b.CreatedAt)
+	})
+
+	return records, nil
+}
+
+// WalkWorkflowJobs implements the Store interface.
+func (s *memoryStore) WalkWorkflowJobs(window time.Duration, limit int, fn func(*WorkflowJob) error) error {
+	records, err := s.GetWorkflowJobs(window)
+
+	if err != nil {
+		return err
+	}
+
+	return walkMemory(records, limit, fn)
+}
+
+// GetWorkflowJobStats implements the Store interface.
+func (s *memoryStore) GetWorkflowJobStats(window time.Duration) ([]*WorkflowJobStats, error) {
+	jobs, err := s.GetWorkflowJobs(window)
+
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*WorkflowJobStats, 0)
+	mapping := make(map[[4]string]*WorkflowJobStats)
+
+	for _, job := range jobs {
+		if job.Status != "completed" {
+			continue
+		}
+
+		key := [4]string{job.Owner, job.Repo, job.WorkflowName, job.Name}
+		record, ok := mapping[key]
+
+		if !ok {
+			record = &WorkflowJobStats{
+				Owner:        job.Owner,
+				Repo:         job.Repo,
+				WorkflowName: job.WorkflowName,
+				Name:         job.Name,
+			}
+
+			mapping[key] = record
+			records = append(records, record)
+		}
+
+		duration := job.CompletedAt - job.StartedAt
+
+		record.Total++
+		record.Duration += duration
+
+		if duration > record.MaxDuration {
+			record.MaxDuration = duration
+		}
+
+		switch job.Conclusion {
+		case "failure", "timed_out":
+			record.Failures++
+		}
+	}
+
+	return records, nil
+}
+
+// PruneWorkflowJobs implements the Store interface.
+func (s *memoryStore) PruneWorkflowJobs(retention Retention, _ int) (map[string]int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return pruneMemoryRetention(s.jobs, retention, func(r *WorkflowJob) (string, string, int64) { return r.Owner, r.Repo, r.CreatedAt }), nil
+}
+
+// CountWorkflowJobs implements the Store interface.
+func (s *memoryStore) CountWorkflowJobs() (int64, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return int64(len(s.jobs)), nil
+}
+
+// ListWorkflowJobs implements the Store interface.
+func (s *memoryStore) ListWorkflowJobs(offset, limit int) ([]*WorkflowJob, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	records := make([]*WorkflowJob, 0, len(s.jobs))
+
+	for _, record := range s.jobs {
+		copied := *record
+		records = append(records, &copied)
+	}
+
+	slices.SortFunc(records, func(a, b *WorkflowJob) int {
+		return cmp.Or(
+			cmp.Compare(a.Owner, b.Owner),
+			cmp.Compare(a.Repo, b.Repo),
+			cmp.Compare(a.Identifier, b.Identifier),
+		)
+	})
+
+	return paginate(records, offset, limit), nil
+}
+
+// ImportWorkflowJobs implements the Store interface.
+func (s *memoryStore) ImportWorkflowJobs(records []*WorkflowJob) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, record := range records {
+		key := memoryJobKey{record.Owner, record.Repo, record.Identifier}
+
+		if existing, ok := s.jobs[key]; ok && jobRegression(existing, record) {
+			continue
+		}
+
+		copied := *record
+		s.jobs[key] = &copied
+	}
+
+	evictRecords(s.jobs, s.limit, func(r *WorkflowJob) int64 { return r.CreatedAt })
+	return nil
+}
+
+// QueryWorkflowJobs implements the Store interface.
+func (s *memoryStore) QueryWorkflowJobs(filter *Filter) ([]*WorkflowJob, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	records := make([]*WorkflowJob, 0)
+
+	for _, record := range s.jobs {
+		if filter.matchWorkflowJob(record) {
+			copied := *record
+			records = append(records, &copied)
+		}
+	}
+
+	slices.SortFunc(records, func(a, b *WorkflowJob) int {
+		return cmp.Or(
+			cmp.Compare(b.CreatedAt, a.CreatedAt),
+			cmp.Compare(a.Owner, b.Owner),
+			cmp.Compare(a.Repo, b.Repo),
+			cmp.Compare(a.Identifier, b.Identifier),
+		)
+	})
+
+	if filter.Limit <= 0 {
+		return records, nil
+	}
+
+	return paginate(records, filter.Offset, filter.Limit), nil
+}
+
+// RollupWorkflowRuns implements the Store interface.
+func (s *memoryStore) RollupWorkflowRuns(timeframe time.Duration) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	start := rollupStart(timeframe)
+	rollups := make(map[workflowRunRollupKey]*WorkflowRunRollup)
+
+	for _, record := range s.runs {
+		addWorkflowRunRollup(rollups, start, record)
+	}
+
+	for key := range s.rollups {
+		if key.bucket >= start {
+			delete(s.rollups, key)
+		}
+	}
+
+	for key, rollup := range rollups {
+		s.rollups[key] = rollup
+	}
+
+	return int64(len(rollups)), nil
+}
+
+// GetWorkflowRunRollups implements the Store interface.
+func (s *memoryStore) GetWorkflowRunRollups(window time.Duration) ([]*WorkflowRunRollup, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	timeframe := time.Now().Add(-window).Unix()
+	records := make([]*WorkflowRunRollup, 0)
+
+	for _, record := range s.rollups {
+		if record.Bucket > timeframe {
+			copied := *record
+			records = append(records, &copied)
+		}
+	}
+
+	slices.SortStableFunc(records, func(a, b *WorkflowRunRollup) int {
+		return cmp.Compare(a.Bucket, b.Bucket)
+	})
+
+	return records, nil
+}
+
+// PruneWorkflowRunRollups implements the Store interface.
+func (s *memoryStore) PruneWorkflowRunRollups(timeframe time.Duration, _ int) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return pruneMemory(s.rollups, timeframe, func(r *WorkflowRunRollup) int64 { return r.Bucket }), nil
+}
+
+// CountWorkflowRunRollups implements the Store interface.
+func (s *memoryStore) CountWorkflowRunRollups() (int64, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return int64(len(s.rollups)), nil
+}
+
+// ListWorkflowRunRollups implements the Store interface.
+func (s *memoryStore) ListWorkflowRunRollups(offset, limit int) ([]*WorkflowRunRollup, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	records := make([]*WorkflowRunRollup, 0, len(s.rollups))
+
+	for _, record := range s.rollups {
+		copied := *record
+		records = append(records, &copied)
+	}
+
+	slices.SortFunc(records, func(a, b *WorkflowRunRollup) int {
+		return cmp.Or(
+			cmp.Compare(a.Owner, b.Owner),
+			cmp.Compare(a.Repo, b.Repo),
+			cmp.Compare(a.WorkflowID, b.WorkflowID),
+			cmp.Compare(a.Bucket, b.Bucket),
+		)
+	})
+
+	return paginate(records, offset, limit), nil
+}
+
+// ImportWorkflowRunRollups implements the Store interface.
+func (s *memoryStore) ImportWorkflowRunRollups(records []*WorkflowRunRollup) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, record := range records {
+		copied := *record
+		s.rollups[workflowRunRollupKey{record.Owner, record.Repo, record.WorkflowID, record.Bucket}] = &copied
+	}
+
+	return nil
+}
+
+// StoreDeploymentProtectionRuleEvent implements the Store interface.
+func (s *memoryStore) StoreDeploymentProtectionRuleEvent(event *github.DeploymentProtectionRuleEvent) error {
+	record, err := deploymentProtectionRuleRecord(event)
+
+	if err != nil || record == nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.requestDeploymentReview(record)
+	return nil
+}
+
+// StoreDeploymentReviewEvent implements the Store interface.
+func (s *memoryStore) StoreDeploymentReviewEvent(event *github.DeploymentReviewEvent) error {
+	requested, reviewed := deploymentReviewRecords(event)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, record := range requested {
+		s.requestDeploymentReview(record)
+	}
+
+	for _, record := range reviewed {
+		key := memoryReviewKey{record.Owner, record.Repo, record.RunID, record.Environment}
+
+		if existing, ok := s.reviews[key]; ok && !deploymentReviewApplies(existing, record) {
+			continue
+		}
+
+		s.reviews[key] = record
+		evictRecords(s.reviews, s.limit, func(r *DeploymentReview) int64 { return r.RequestedAt })
+	}
+
+	return nil
+}
+
+// GetDeploymentReviews implements the Store interface.
+func (s *memoryStore) GetDeploymentReviews(window time.Duration) ([]*DeploymentReview, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	timeframe := time.Now().Add(-window).Unix()
+	records := make([]*DeploymentReview, 0)
+
+	for _, record := range s.reviews {
+		if record.RequestedAt > timeframe || record.Status == "pending" {
+			copied := *record
+			records = append(records, &copied)
+		}
+	}
+
+	slices.SortStableFunc(records, func(a, b *DeploymentReview) int {
+		return cmp.Compare(a.RequestedAt, b.RequestedAt)
+	})
+
+	return records, nil
+}
+
+// PruneDeploymentReviews implements the Store interface.
+func (s *memoryStore) PruneDeploymentReviews(timeframe time.Duration, _ int) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return pruneMemory(s.reviews, timeframe, func(r *DeploymentReview) int64 { return r.RequestedAt }), nil
+}
+
+// CountDeploymentReviews implements the Store interface.
+func (s *memoryStore) CountDeploymentReviews() (int64, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return int64(len(s.reviews)), nil
+}
+
+// ListDeploymentReviews implements the Store interface.
+func (s *memoryStore) ListDeploymentReviews(offset, limit int) ([]*DeploymentReview, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	records := make([]*DeploymentReview, 0, len(s.reviews))
+
+	for _, record := range s.reviews {
+		copied := *record
+		records = append(records, &copied)
+	}
+
+	slices.SortFunc(records, func(a, b *DeploymentReview) int {
+		return cmp.Or(
+			cmp.Compare(a.Owner, b.Owner),
+			cmp.Compare(a.Repo, b.Repo),
+			cmp.Compare(a.RunID, b.RunID),
+			cmp.Compare(a.Environment, b.Environment),
+		)
+	})
+
+	return paginate(records, offset, limit), nil
+}
+
+// ImportDeploymentReviews implements the Store interface.
+func (s *memoryStore) ImportDeploymentReviews(records []*DeploymentReview) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, record := range records {
+		copied := *record
+		s.reviews[memoryReviewKey{record.Owner, record.Repo, record.RunID, record.Environment}] = &copied
+	}
+
+	evictRecords(s.reviews, s.limit, func(r *DeploymentReview) int64 { return r.RequestedAt })
+	return nil
+}
+
+// GetCacheEntry implements the Store interface.
+func (s *memoryStore) GetCacheEntry(key string) (*CacheEntry, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	record, ok := s.cache[key]
+
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	copied := *record
+	return &copied, nil
+}
+
+// StoreCacheEntry implements the Store interface.
+func (s *memoryStore) StoreCacheEntry(record *CacheEntry) error {
+	copied := *record
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.cache[record.Key] = &copied
+	evictRecords(s.cache, s.limit, func(r *CacheEntry) int64 { return r.UpdatedAt })
+
+	return nil
+}
+
+// PruneCacheEntries implements the Store interface.
+func (s *memoryStore) PruneCacheEntries(timeframe time.Duration, _ int) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return pruneMemory(s.cache, timeframe, func(r *CacheEntry) int64 { return r.UpdatedAt }), nil
+}
+
+// AcquireLease implements the Store interface, the memory store is never
+// shared, so it's always the leader.
+func (s *memoryStore) AcquireLease(_, _ string, _ time.Duration) (bool, error) {
+	return true, nil
+}
+
+// ReleaseLease implements the Store interface.
+func (s *memoryStore) ReleaseLease(_, _ string) error {
+	return nil
+}
+
+// GetTableStats implements the Store interface.
+func (s *memoryStore) GetTableStats() ([]*TableStats, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return []*TableStats{
+		memoryTableStats("workflow_runs", s.runs, func(r *WorkflowRun) int64 { return r.UpdatedAt }),
+		memoryTableStats("workflow_jobs", s.jobs, func(r *WorkflowJob) int64 { return r.CreatedAt }),
+		memoryTableStats("deployment_reviews", s.reviews, func(r *DeploymentReview) int64 { return r.RequestedAt }),
+		memoryTableStats("workflow_run_rollups", s.rollups, func(r *WorkflowRunRollup) int64 { return r.Bucket }),
+		memoryTableStats("http_cache", s.cache, func(r *CacheEntry) int64 { return r.UpdatedAt }),
+		{Table: "leases"},
+	}, nil
+}
+
+// GetSize implements the Store interface.
+func (s *memoryStore) GetSize() (int64, error) {
+	return 0, ErrUnsupported
+}
+
+// requestDeploymentReview creates a pending review or restarts a finished
+// one, the caller has to hold the lock.
+func (s *memoryStore) requestDeploymentReview(record *DeploymentReview) {
+	key := memoryReviewKey{record.Owner, record.Repo, record.RunID, record.Environment}
+
+	if existing, ok := s.reviews[key]; ok && !deploymentRequestApplies(existing, record) {
+		return
+	}
+
+	s.reviews[key] = record
+	evictRecords(s.reviews, s.limit, func(r *DeploymentReview) int64 { return r.RequestedAt })
+}
+
+// pruneMemory deletes all records older than the timeframe.
+func pruneMemory[K comparable, V any](records map[K]V, timeframe time.Duration, timestamp func(V) int64) int64 {
+	deleted := int64(0)
+	border := time.Now().Add(-timeframe).Unix()
+
+	for key, record := range records {
+		if timestamp(record) < border {
+			delete(records, key)
+			deleted++
+		}
+	}
+
+	return deleted
+}
+
+// memoryTableStats counts the records and detects the record range.
+func memoryTableStats[K comparable, V any](table string, records map[K]V, timestamp func(V) int64) *TableStats {
+	result := &TableStats{
+		Table: table,
+		Rows:  int64(len(records)),
+	}
+
+	for _, record := range records {
+		value := timestamp(record)
+
+		if result.Oldest == 0 || value < result.Oldest {
+			result.Oldest = value
+		}
+
+		if value > result.Newest {
+			result.Newest = value
+		}
+	}
+
+	return result
+}
+
+// walkMemory executes the callback for the sorted records, starting with the
+// newest ones.
+func walkMemory[V any](records []V, limit int, fn func(V) error) error {
+	for count, i := 0, len(records)-1; i >= 0; count, i = count+1, i-1 {
+		if limit > 0 && count >= limit {
+			return ErrLimitReached
+		}
+
+		if err := fn(records[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// paginate returns the requested page of the sorted records.
+func paginate[V any](records []V, offset, limit int) []V {
+	if offset >= len(records) {
+		return records[:0]
+	}
+
+	records = records[offset:]
+
+	if limit > 0 && limit < len(records) {
+		records = records[:limit]
+	}
+
+	return records
+}
+
+// evictRecords deletes the oldest records if the limit is exceeded.
+func evictRecords[K comparable, V any](records map[K]V, limit int, timestamp func(V) int64) {
+	if limit <= 0 || len(records) <= limit {
+		return
+	}
+
+	keys := make([]K, 0, len(records))
+
+	for key := range records {
+		keys = append(keys, key)
+	}
+
+	slices.SortFunc(keys, func(a, b K) int {
+		return cmp.Compare(timestamp(records[a]), timestamp(records[b]))
+	})
+
+	for _, key := range keys[:len(keys)-limit] {
+		delete(records, key)
+	}
+}
+
+// NewMemoryStore initializes a new in-memory store, the optional limit
+// parameter defines the maximum number of records per record type.
+func NewMemoryStore(dsn string, logger *slog.Logger) (Store, error) {
+	parsed, err := url.Parse(dsn)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dsn: %w", err)
+	}
+
+	client := &memoryStore{
+		logger:  logger,
+		runs:    make(map[memoryRunKey]*WorkflowRun),
+		jobs:    make(map[memoryJobKey]*WorkflowJob),
+		reviews: make(map[memoryReviewKey]*DeploymentReview),
+		rollups: make(map[workflowRunRollupKey]*WorkflowRunRollup),
+		cache:   make(map[string]*CacheEntry),
+	}
+
+	if val := parsed.Query().Get("limit"); val != "" {
+		limit, err := strconv.Atoi(val)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse limit: %w", err)
+		}
+
+		client.limit = limit
+	}
+
+	return client, nil
+}
+
+// pruneMemoryRetention deletes records older than the window of the matching
+// retention rule and reports the deleted records per rule.
+func pruneMemoryRetention[K comparable, V any](records map[K]V, retention Retention, resolve func(V) (string, string, int64)) map[string]int64 {
+	result := map[string]int64{
+		RetentionDefault: 0,
+	}
+
+	now := time.Now()
+
+	for key, record := range records {
+		owner, repo, timestamp := resolve(record)
+		rule, window := retention.Resolve(owner, repo)
+
+		if timestamp < now.Add(-window).Unix() {
+			delete(records, key)
+			result[rule]++
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,328 @@
+package store
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMemoryJobEvent(id int64, status string, created, started, completed time.Time) *github.WorkflowJobEvent {
+	job := &github.WorkflowJob{
+		ID:        github.Ptr(id),
+		RunID:     github.Ptr(int64(1)),
+		Name:      github.Ptr("build / test (linux)"),
+		Status:    github.Ptr(status),
+		CreatedAt: &github.Timestamp{Time: created},
+	}
+
+	if !started.IsZero() {
+		job.StartedAt = &github.Timestamp{Time: started}
+	}
+
+	if !completed.IsZero() {
+		job.CompletedAt = &github.Timestamp{Time: completed}
+		job.Conclusion = github.Ptr("failure")
+	}
+
+	return &github.WorkflowJobEvent{
+		Repo: &github.Repository{
+			Name:  github.Ptr("github_exporter"),
+			Owner: &github.User{Login: github.Ptr("promhippie")},
+		},
+		WorkflowJob: job,
+	}
+}
+
+func TestMemoryWorkflowJobs(t *testing.T) {
+	s, err := New("memory://", slog.Default())
+	require.NoError(t, err)
+
+	now := time.Now().Truncate(time.Second)
+
+	require.NoError(t, s.StoreWorkflowJobEvent(testMemoryJobEvent(1, "in_progress", now, now, time.Time{})))
+	require.NoError(t, s.StoreWorkflowJobEvent(testMemoryJobEvent(1, "completed", now, now, now.Add(time.Minute))))
+	assert.ErrorIs(t, s.StoreWorkflowJobEvent(testMemoryJobEvent(1, "queued", now, time.Time{}, time.Time{})), ErrOutdated)
+
+	records, err := s.GetWorkflowJobs(time.Hour)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "completed", records[0].Status)
+
+	stats, err := s.GetWorkflowJobStats(time.Hour)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, int64(1), stats[0].Failures)
+	assert.Equal(t, int64(60), stats[0].Duration)
+
+	require.NoError(t, s.StoreWorkflowJobEvent(testMemoryJobEvent(2, "queued", now.Add(-2*time.Hour), time.Time{}, time.Time{})))
+
+	deleted, err := s.PruneWorkflowJobs(Retention{Window: time.Hour}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted[RetentionDefault])
+}
+
+func TestMemoryConcurrentWorkflowRuns(t *testing.T) {
+	s, err := New("memory://", slog.Default())
+	require.NoError(t, err)
+
+	testConcurrentWorkflowRuns(t, s)
+}
+
+func TestMemoryConcurrentWorkflowJobs(t *testing.T) {
+	s, err := New("memory://", slog.Default())
+	require.NoError(t, err)
+
+	testConcurrentWorkflowJobs(t, s)
+}
+
+func TestMemoryConcurrentDeploymentReviews(t *testing.T) {
+	s, err := New("memory://", slog.Default())
+	require.NoError(t, err)
+
+	testConcurrentDeploymentReviews(t, s)
+}
+
+func TestMemoryLimit(t *testing.T) {
+	s, err := New("memory://?limit=2", slog.Default())
+	require.NoError(t, err)
+
+	now := time.Now().Truncate(time.Second)
+
+	for i := range 3 {
+		require.NoError(t, s.StoreWorkflowJobEvent(testMemoryJobEvent(int64(i+1), "queued", now.Add(time.Duration(i)*time.Second), time.Time{}, time.Time{})))
+	}
+
+	records, err := s.GetWorkflowJobs(time.Hour)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, int64(2), records[0].Identifier)
+	assert.Equal(t, int64(3), records[1].Identifier)
+
+	_, err = New("memory://?limit=foo", slog.Default())
+	assert.Error(t, err)
+}
+
+func TestMemoryWalkWorkflowJobs(t *testing.T) {
+	s, err := New("memory://", slog.Default())
+	require.NoError(t, err)
+
+	now := time.Now().Truncate(time.Second)
+
+	for i := range 3 {
+		require.NoError(t, s.StoreWorkflowJobEvent(testMemoryJobEvent(int64(i+1), "queued", now.Add(time.Duration(i)*time.Second), time.Time{}, time.Time{})))
+	}
+
+	ids := make([]int64, 0)
+
+	err = s.WalkWorkflowJobs(time.Hour, 2, func(record *WorkflowJob) error {
+		ids = append(ids, record.Identifier)
+		return nil
+	})
+
+	assert.ErrorIs(t, err, ErrLimitReached)
+	assert.Equal(t, []int64{3, 2}, ids)
+}
+
+func TestMemoryTableStats(t *testing.T) {
+	s, err := New("memory://", slog.Default())
+	require.NoError(t, err)
+
+	now := time.Now().Truncate(time.Second)
+
+	for i := range 3 {
+		require.NoError(t, s.StoreWorkflowJobEvent(testMemoryJobEvent(int64(i+1), "queued", now.Add(time.Duration(i)*time.Second), time.Time{}, time.Time{})))
+	}
+
+	records, err := s.GetTableStats()
+	require.NoError(t, err)
+	require.Len(t, records, 6)
+
+	assert.Equal(t, &TableStats{Table: "workflow_runs"}, records[0])
+	assert.Equal(t, &TableStats{Table: "workflow_jobs", Rows: 3, Oldest: now.Unix(), Newest: now.Add(2 * time.Second).Unix()}, records[1])
+
+	_, err = s.GetSize()
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
+
+func testRollupRuns(updated time.Time) []*WorkflowRun {
+	records := make([]*WorkflowRun, 0)
+
+	for i, status := range []string{"success", "failure", "in_progress"} {
+		records = append(records, &WorkflowRun{
+			Owner:      "promhippie",
+			Repo:       "github_exporter",
+			WorkflowID: 1,
+			Number:     i + 1,
+			Name:       "ci",
+			Status:     status,
+			StartedAt:  updated.Add(-time.Minute).Unix(),
+			UpdatedAt:  updated.Unix(),
+		})
+	}
+
+	return records
+}
+
+func TestMemoryRollupWorkflowRuns(t *testing.T) {
+	s, err := New("memory://", slog.Default())
+	require.NoError(t, err)
+
+	updated := time.Now().Add(-2 * time.Hour).Truncate(time.Hour).Add(time.Minute)
+	require.NoError(t, s.ImportWorkflowRuns(testRollupRuns(updated)))
+
+	for range 2 {
+		written, err := s.RollupWorkflowRuns(3 * time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), written)
+	}
+
+	records, err := s.GetWorkflowRunRollups(24 * time.Hour)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	assert.Equal(t, &WorkflowRunRollup{
+		Owner:      "promhippie",
+		Repo:       "github_exporter",
+		WorkflowID: 1,
+		Name:       "ci",
+		Bucket:     updated.Truncate(time.Hour).Unix(),
+		Total:      2,
+		Success:    1,
+		Failure:    1,
+		Duration:   120,
+	}, records[0])
+
+	_, err = s.PruneWorkflowRuns(Retention{Window: time.Minute}, 100)
+	require.NoError(t, err)
+
+	written, err := s.RollupWorkflowRuns(time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), written)
+
+	records, err = s.GetWorkflowRunRollups(24 * time.Hour)
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+}
+
+func TestMemoryPruneRetention(t *testing.T) {
+	s, err := New("memory://", slog.Default())
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	for i, owner := range []string{"promhippie", "sandbox-one", "other"} {
+		event := testMemoryJobEvent(int64(i+1), "queued", now.Add(-72*time.Hour), time.Time{}, time.Time{})
+		event.Repo.Owner.Login = github.Ptr(owner)
+
+		require.NoError(t, s.StoreWorkflowJobEvent(event))
+	}
+
+	deleted, err := s.PruneWorkflowJobs(Retention{
+		Window: 24 * time.Hour,
+		Rules: []RetentionRule{
+			{Pattern: "promhippie/github_exporter", Window: 90 * 24 * time.Hour},
+			{Pattern: "sandbox-*", Window: 48 * time.Hour},
+		},
+	}, 0)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"sandbox-*": 1, RetentionDefault: 1}, deleted)
+
+	count, err := s.CountWorkflowJobs()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func testRunEvent(status string, updated time.Time) *github.WorkflowRunEvent {
+	return &github.WorkflowRunEvent{
+		Repo: &github.Repository{
+			Name:  github.Ptr("github_exporter"),
+			Owner: &github.User{Login: github.Ptr("promhippie")},
+		},
+		WorkflowRun: &github.WorkflowRun{
+			ID:         github.Ptr(int64(1)),
+			WorkflowID: github.Ptr(int64(1)),
+			RunNumber:  github.Ptr(1),
+			Status:     github.Ptr(status),
+			CreatedAt:  &github.Timestamp{Time: updated},
+			UpdatedAt:  &github.Timestamp{Time: updated},
+		},
+	}
+}
+
+func testReviewEvent(environment string, requested time.Time) *github.DeploymentReviewEvent {
+	return &github.DeploymentReviewEvent{
+		Action:      github.Ptr("requested"),
+		Environment: github.Ptr(environment),
+		Repo: &github.Repository{
+			Name:  github.Ptr("github_exporter"),
+			Owner: &github.User{Login: github.Ptr("promhippie")},
+		},
+		WorkflowRun: &github.WorkflowRun{
+			ID: github.Ptr(int64(1)),
+		},
+		WorkflowJobRun: &github.WorkflowJobRun{
+			CreatedAt: &github.Timestamp{Time: requested},
+		},
+	}
+}
+
+// testDeploymentReviews verifies the transitions from pending to resolved
+// reviews, it's shared by the tests of all drivers.
+func testDeploymentReviews(t *testing.T, s Store) {
+	t.Helper()
+
+	now := time.Now().Truncate(time.Second)
+
+	reviews := func() map[string]*DeploymentReview {
+		records, err := s.GetDeploymentReviews(time.Hour)
+		require.NoError(t, err)
+
+		result := make(map[string]*DeploymentReview, len(records))
+
+		for _, record := range records {
+			result[record.Environment] = record
+		}
+
+		return result
+	}
+
+	require.NoError(t, s.StoreDeploymentReviewEvent(testReviewEvent("production", now)))
+
+	require.NoError(t, s.StoreWorkflowRunEvent(testRunEvent("in_progress", now.Add(-time.Minute))))
+	assert.Equal(t, "pending", reviews()["production"].Status)
+
+	require.NoError(t, s.StoreWorkflowRunEvent(testRunEvent("in_progress", now.Add(2*time.Minute))))
+	assert.Equal(t, "resolved", reviews()["production"].Status)
+	assert.Equal(t, now.Add(2*time.Minute).Unix(), reviews()["production"].ReviewedAt)
+
+	require.NoError(t, s.StoreDeploymentReviewEvent(testReviewEvent("staging", now.Add(3*time.Minute))))
+	require.NoError(t, s.StoreWorkflowRunEvent(testRunEvent("waiting", now.Add(5*time.Minute))))
+
+	require.NoError(t, s.StoreWorkflowRunEvent(testRunEvent("in_progress", now.Add(4*time.Minute))))
+	assert.Equal(t, "pending", reviews()["staging"].Status)
+
+	require.NoError(t, s.StoreWorkflowRunEvent(testRunEvent("completed", now.Add(6*time.Minute))))
+	assert.Equal(t, "resolved", reviews()["staging"].Status)
+	assert.Equal(t, now.Add(6*time.Minute).Unix(), reviews()["staging"].ReviewedAt)
+	assert.Equal(t, now.Add(2*time.Minute).Unix(), reviews()["production"].ReviewedAt)
+
+	require.NoError(t, s.StoreDeploymentReviewEvent(testReviewEvent("canary", now.Add(10*time.Minute))))
+	require.NoError(t, s.StoreWorkflowRunEvent(testRunEvent("in_progress", now.Add(7*time.Minute))))
+	assert.Equal(t, "pending", reviews()["canary"].Status)
+
+	require.NoError(t, s.StoreWorkflowRunEvent(testRunEvent("completed", now.Add(8*time.Minute))))
+	assert.Equal(t, "resolved", reviews()["canary"].Status)
+	assert.Equal(t, now.Add(8*time.Minute).Unix(), reviews()["canary"].ReviewedAt)
+}
+
+func TestMemoryDeploymentReviews(t *testing.T) {
+	s, err := New("memory://", slog.Default())
+	require.NoError(t, err)
+
+	testDeploymentReviews(t, s)
+}
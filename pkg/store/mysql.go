@@ -73,9 +73,151 @@ var (
 				PRIMARY KEY(owner, repo, identifier)
 			);`,
 		},
+		{
+			Version:     4,
+			Description: "Creating table deployment_reviews",
+			Script: `CREATE TABLE deployment_reviews (
+				owner VARCHAR(255) NOT NULL,
+				repo VARCHAR(255) NOT NULL,
+				run_id BIGINT NOT NULL,
+				environment VARCHAR(255) NOT NULL,
+				status VARCHAR(255),
+				approver VARCHAR(255),
+				requested_at BIGINT,
+				reviewed_at BIGINT,
+				PRIMARY KEY(owner, repo, run_id, environment)
+			) ENGINE=InnoDB CHARACTER SET=utf8;`,
+		},
+		{
+			Version:     5,
+			Description: "Creating table workflow_run_rollups",
+			Script: `CREATE TABLE workflow_run_rollups (
+				owner VARCHAR(255) NOT NULL,
+				repo VARCHAR(255) NOT NULL,
+				workflow_id BIGINT NOT NULL,
+				name VARCHAR(255),
+				bucket BIGINT NOT NULL,
+				total BIGINT,
+				success BIGINT,
+				failure BIGINT,
+				duration BIGINT,
+				PRIMARY KEY(owner, repo, workflow_id, bucket)
+			) ENGINE=InnoDB CHARACTER SET=utf8;`,
+		},
+		{
+			Version:     6,
+			Description: "Creating table leases",
+			Script: `CREATE TABLE leases (
+				name VARCHAR(255) NOT NULL,
+				holder VARCHAR(255) NOT NULL,
+				expires_at BIGINT NOT NULL,
+				PRIMARY KEY(name)
+			) ENGINE=InnoDB CHARACTER SET=utf8;`,
+		},
+		{
+			Version:     7,
+			Description: "Creating table http_cache",
+			Script: `CREATE TABLE http_cache (
+				cache_key VARCHAR(64) NOT NULL,
+				etag VARCHAR(255) NOT NULL,
+				last_modified VARCHAR(255) NOT NULL,
+				header TEXT NOT NULL,
+				body LONGBLOB NOT NULL,
+				updated_at BIGINT NOT NULL,
+				PRIMARY KEY(cache_key)
+			) ENGINE=InnoDB CHARACTER SET=utf8;`,
+		},
+		{
+			Version:     8,
+			Description: "Creating index for workflow_runs retention",
+			Script:      `CREATE INDEX workflow_runs_retention_idx ON workflow_runs (owner, repo, updated_at);`,
+		},
+		{
+			Version:     9,
+			Description: "Creating index for workflow_jobs retention",
+			Script:      `CREATE INDEX workflow_jobs_retention_idx ON workflow_jobs (owner, repo, created_at);`,
+		},
 	}
 )
 
+const (
+	// mysqlDeploymentReviewGuard mirrors the deploymentReviewApplies function.
+	mysqlDeploymentReviewGuard = "(status NOT IN ('approved', 'rejected') OR reviewed_at <= VALUES(reviewed_at))"
+)
+
+var (
+	// mysqlUpsertWorkflowRunQuery assigns status and updated_at last as MySQL
+	// evaluates the guard again for every column with the updated values.
+	mysqlUpsertWorkflowRunQuery = strings.TrimSuffix(createWorkflowRunQuery, ";") + `
+ON DUPLICATE KEY UPDATE
+` + mysqlGuardedColumns(
+		"(updated_at < VALUES(updated_at) OR (updated_at = VALUES(updated_at) AND status <> 'completed'))",
+		"attempt",
+		"event",
+		"name",
+		"title",
+		"branch",
+		"sha",
+		"identifier",
+		"actor",
+		"created_at",
+		"started_at",
+		"status",
+		"updated_at",
+	) + ";"
+
+	// mysqlUpsertWorkflowJobQuery assigns the columns used by the guard last
+	// as MySQL evaluates the guard again for every column with the updated
+	// values.
+	mysqlUpsertWorkflowJobQuery = strings.TrimSuffix(createWorkflowJobQuery, ";") + `
+ON DUPLICATE KEY UPDATE
+` + mysqlGuardedColumns(
+		jobProgressExpr("%s", "VALUES(%s)"),
+		"run_attempt",
+		"conclusion",
+		"name",
+		"branch",
+		"sha",
+		"created_at",
+		"runner_id",
+		"runner_name",
+		"runner_group_id",
+		"runner_group_name",
+		"completed_at",
+		"started_at",
+		"status",
+	) + ";"
+
+	// mysqlUpsertDeploymentRequestQuery assigns status last as MySQL evaluates
+	// the guard again for every column with the updated values.
+	mysqlUpsertDeploymentRequestQuery = strings.TrimSuffix(createDeploymentReviewQuery, ";") + `
+ON DUPLICATE KEY UPDATE
+` + mysqlGuardedColumns(
+		"(status <> 'pending' AND reviewed_at < VALUES(requested_at))",
+		"approver",
+		"requested_at",
+		"reviewed_at",
+		"status",
+	) + ";"
+
+	// mysqlUpsertDeploymentReviewQuery preserves the original request time
+	// and assigns status last as MySQL evaluates the guard again for every
+	// column with the updated values.
+	mysqlUpsertDeploymentReviewQuery = strings.TrimSuffix(createDeploymentReviewQuery, ";") + `
+ON DUPLICATE KEY UPDATE
+	requested_at=IF(` + mysqlDeploymentReviewGuard + ` AND NOT (requested_at > 0 AND requested_at <= VALUES(reviewed_at)), VALUES(requested_at), requested_at),
+` + mysqlGuardedColumns(
+		mysqlDeploymentReviewGuard,
+		"approver",
+		"reviewed_at",
+		"status",
+	) + ";"
+
+	// mysqlSizeQuery sums up the data and index size of all tables within the
+	// current database.
+	mysqlSizeQuery = `SELECT SUM(data_length + index_length) FROM information_schema.tables WHERE table_schema = DATABASE();`
+)
+
 func init() {
 	register("mysql", NewMysqlStore)
 	register("mariadb", NewMysqlStore)
@@ -147,9 +289,18 @@ func (s *mysqlStore) Migrate() error {
 	return nil
 }
 
+// Migrations returns the status of all db migrations.
+func (s *mysqlStore) Migrations() ([]*Migration, error) {
+	return migrationStatus(
+		s.handle.DB,
+		dialect.MySQLDialect{},
+		mysqlMigrations,
+	)
+}
+
 // StoreWorkflowRunEvent implements the Store interface.
 func (s *mysqlStore) StoreWorkflowRunEvent(event *github.WorkflowRunEvent) error {
-	return storeWorkflowRunEvent(s.handle, event)
+	return storeWorkflowRunEvent(s.handle, mysqlUpsertWorkflowRunQuery, event)
 }
 
 // GetWorkflowRuns implements the Store interface.
@@ -157,14 +308,45 @@ func (s *mysqlStore) GetWorkflowRuns(window time.Duration) ([]*WorkflowRun, erro
 	return getWorkflowRuns(s.handle, window)
 }
 
+// WalkWorkflowRuns implements the Store interface.
+func (s *mysqlStore) WalkWorkflowRuns(window time.Duration, limit int, fn func(*WorkflowRun) error) error {
+	return walkWorkflowRuns(s.handle, walkWorkflowRunsQuery, window, limit, fn)
+}
+
 // PruneWorkflowRuns implements the Store interface.
-func (s *mysqlStore) PruneWorkflowRuns(timeframe time.Duration) error {
-	return pruneWorkflowRuns(s.handle, timeframe)
+func (s *mysqlStore) PruneWorkflowRuns(retention Retention, limit int) (map[string]int64, error) {
+	return pruneWorkflowRuns(
+		s.handle,
+		mysqlPruneQuery("workflow_runs", "updated_at < :timeframe"),
+		mysqlPruneQuery("workflow_runs", "owner=:owner AND repo=:repo AND updated_at < :timeframe"),
+		retention,
+		limit,
+	)
+}
+
+// CountWorkflowRuns implements the Store interface.
+func (s *mysqlStore) CountWorkflowRuns() (int64, error) {
+	return countWorkflowRuns(s.handle)
+}
+
+// ListWorkflowRuns implements the Store interface.
+func (s *mysqlStore) ListWorkflowRuns(offset, limit int) ([]*WorkflowRun, error) {
+	return listWorkflowRuns(s.handle, listWorkflowRunsQuery, offset, limit)
+}
+
+// ImportWorkflowRuns implements the Store interface.
+func (s *mysqlStore) ImportWorkflowRuns(records []*WorkflowRun) error {
+	return importWorkflowRuns(s.handle, mysqlUpsertWorkflowRunQuery, records)
+}
+
+// QueryWorkflowRuns implements the Store interface.
+func (s *mysqlStore) QueryWorkflowRuns(filter *Filter) ([]*WorkflowRun, error) {
+	return queryWorkflowRuns(s.handle, queryWorkflowRunsOrder, filter)
 }
 
 // StoreWorkflowJobEvent implements the Store interface.
 func (s *mysqlStore) StoreWorkflowJobEvent(event *github.WorkflowJobEvent) error {
-	return storeWorkflowJobEvent(s.handle, event)
+	return storeWorkflowJobEvent(s.handle, mysqlUpsertWorkflowJobQuery, event)
 }
 
 // GetWorkflowJobs implements the Store interface.
@@ -172,9 +354,168 @@ func (s *mysqlStore) GetWorkflowJobs(window time.Duration) ([]*WorkflowJob, erro
 	return getWorkflowJobs(s.handle, window)
 }
 
+// WalkWorkflowJobs implements the Store interface.
+func (s *mysqlStore) WalkWorkflowJobs(window time.Duration, limit int, fn func(*WorkflowJob) error) error {
+	return walkWorkflowJobs(s.handle, walkWorkflowJobsQuery, window, limit, fn)
+}
+
+// GetWorkflowJobStats implements the Store interface.
+func (s *mysqlStore) GetWorkflowJobStats(window time.Duration) ([]*WorkflowJobStats, error) {
+	return getWorkflowJobStats(s.handle, selectWorkflowJobStatsQuery, window)
+}
+
 // PruneWorkflowJobs implements the Store interface.
-func (s *mysqlStore) PruneWorkflowJobs(timeframe time.Duration) error {
-	return pruneWorkflowJobs(s.handle, timeframe)
+func (s *mysqlStore) PruneWorkflowJobs(retention Retention, limit int) (map[string]int64, error) {
+	return pruneWorkflowJobs(
+		s.handle,
+		mysqlPruneQuery("workflow_jobs", "created_at < :timeframe"),
+		mysqlPruneQuery("workflow_jobs", "owner=:owner AND repo=:repo AND created_at < :timeframe"),
+		retention,
+		limit,
+	)
+}
+
+// CountWorkflowJobs implements the Store interface.
+func (s *mysqlStore) CountWorkflowJobs() (int64, error) {
+	return countWorkflowJobs(s.handle)
+}
+
+// ListWorkflowJobs implements the Store interface.
+func (s *mysqlStore) ListWorkflowJobs(offset, limit int) ([]*WorkflowJob, error) {
+	return listWorkflowJobs(s.handle, listWorkflowJobsQuery, offset, limit)
+}
+
+// ImportWorkflowJobs implements the Store interface.
+func (s *mysqlStore) ImportWorkflowJobs(records []*WorkflowJob) error {
+	return importWorkflowJobs(s.handle, mysqlUpsertWorkflowJobQuery, records)
+}
+
+// QueryWorkflowJobs implements the Store interface.
+func (s *mysqlStore) QueryWorkflowJobs(filter *Filter) ([]*WorkflowJob, error) {
+	return queryWorkflowJobs(s.handle, queryWorkflowJobsOrder, filter)
+}
+
+// RollupWorkflowRuns implements the Store interface.
+func (s *mysqlStore) RollupWorkflowRuns(timeframe time.Duration) (int64, error) {
+	return rollupWorkflowRuns(s.handle, timeframe)
+}
+
+// GetWorkflowRunRollups implements the Store interface.
+func (s *mysqlStore) GetWorkflowRunRollups(window time.Duration) ([]*WorkflowRunRollup, error) {
+	return getWorkflowRunRollups(s.handle, window)
+}
+
+// PruneWorkflowRunRollups implements the Store interface.
+func (s *mysqlStore) PruneWorkflowRunRollups(timeframe time.Duration, limit int) (int64, error) {
+	return pruneWorkflowRunRollups(s.handle, mysqlPruneQuery("workflow_run_rollups", "bucket < :timeframe"), timeframe, limit)
+}
+
+// CountWorkflowRunRollups implements the Store interface.
+func (s *mysqlStore) CountWorkflowRunRollups() (int64, error) {
+	return countWorkflowRunRollups(s.handle)
+}
+
+// ListWorkflowRunRollups implements the Store interface.
+func (s *mysqlStore) ListWorkflowRunRollups(offset, limit int) ([]*WorkflowRunRollup, error) {
+	return listWorkflowRunRollups(s.handle, listWorkflowRunRollupsQuery, offset, limit)
+}
+
+// ImportWorkflowRunRollups implements the Store interface.
+func (s *mysqlStore) ImportWorkflowRunRollups(records []*WorkflowRunRollup) error {
+	return importWorkflowRunRollups(s.handle, records)
+}
+
+// StoreDeploymentProtectionRuleEvent implements the Store interface.
+func (s *mysqlStore) StoreDeploymentProtectionRuleEvent(event *github.DeploymentProtectionRuleEvent) error {
+	return storeDeploymentProtectionRuleEvent(s.handle, mysqlUpsertDeploymentRequestQuery, event)
+}
+
+// StoreDeploymentReviewEvent implements the Store interface.
+func (s *mysqlStore) StoreDeploymentReviewEvent(event *github.DeploymentReviewEvent) error {
+	return storeDeploymentReviewEvent(s.handle, mysqlUpsertDeploymentRequestQuery, mysqlUpsertDeploymentReviewQuery, event)
+}
+
+// GetDeploymentReviews implements the Store interface.
+func (s *mysqlStore) GetDeploymentReviews(window time.Duration) ([]*DeploymentReview, error) {
+	return getDeploymentReviews(s.handle, window)
+}
+
+// PruneDeploymentReviews implements the Store interface.
+func (s *mysqlStore) PruneDeploymentReviews(timeframe time.Duration, limit int) (int64, error) {
+	return pruneDeploymentReviews(s.handle, mysqlPruneQuery("deployment_reviews", "requested_at < :timeframe"), timeframe, limit)
+}
+
+// CountDeploymentReviews implements the Store interface.
+func (s *mysqlStore) CountDeploymentReviews() (int64, error) {
+	return countDeploymentReviews(s.handle)
+}
+
+// ListDeploymentReviews implements the Store interface.
+func (s *mysqlStore) ListDeploymentReviews(offset, limit int) ([]*DeploymentReview, error) {
+	return listDeploymentReviews(s.handle, listDeploymentReviewsQuery, offset, limit)
+}
+
+// ImportDeploymentReviews implements the Store interface.
+func (s *mysqlStore) ImportDeploymentReviews(records []*DeploymentReview) error {
+	return importDeploymentReviews(s.handle, records)
+}
+
+// GetCacheEntry implements the Store interface.
+func (s *mysqlStore) GetCacheEntry(key string) (*CacheEntry, error) {
+	return getCacheEntry(s.handle, key)
+}
+
+// StoreCacheEntry implements the Store interface.
+func (s *mysqlStore) StoreCacheEntry(record *CacheEntry) error {
+	return storeCacheEntry(s.handle, record)
+}
+
+// PruneCacheEntries implements the Store interface.
+func (s *mysqlStore) PruneCacheEntries(timeframe time.Duration, limit int) (int64, error) {
+	return pruneCacheEntries(s.handle, mysqlPruneQuery("http_cache", "updated_at < :timeframe"), timeframe, limit)
+}
+
+// AcquireLease implements the Store interface.
+func (s *mysqlStore) AcquireLease(name, holder string, ttl time.Duration) (bool, error) {
+	return acquireLease(s.handle, name, holder, ttl)
+}
+
+// ReleaseLease implements the Store interface.
+func (s *mysqlStore) ReleaseLease(name, holder string) error {
+	return releaseLease(s.handle, name, holder)
+}
+
+// GetTableStats implements the Store interface.
+func (s *mysqlStore) GetTableStats() ([]*TableStats, error) {
+	return getTableStats(s.handle)
+}
+
+// GetSize implements the Store interface.
+func (s *mysqlStore) GetSize() (int64, error) {
+	return getSize(s.handle, mysqlSizeQuery)
+}
+
+// mysqlGuardedColumns only assigns the new column values if the guard matches.
+func mysqlGuardedColumns(guard string, columns ...string) string {
+	assignments := make([]string, 0, len(columns))
+
+	for _, column := range columns {
+		assignments = append(
+			assignments,
+			fmt.Sprintf("\t%[1]s=IF(%[2]s, VALUES(%[1]s), %[1]s)", column, guard),
+		)
+	}
+
+	return strings.Join(assignments, ",\n")
+}
+
+// mysqlPruneQuery deletes a batch of records.
+func mysqlPruneQuery(table, condition string) string {
+	return fmt.Sprintf(
+		"DELETE FROM %[1]s WHERE %[2]s LIMIT :limit;",
+		table,
+		condition,
+	)
 }
 
 func (s *mysqlStore) dsn() string {
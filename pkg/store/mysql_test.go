@@ -0,0 +1,17 @@
+package store
+
+import (
+	"testing"
+)
+
+func TestMysqlConcurrentWorkflowRuns(t *testing.T) {
+	testConcurrentWorkflowRuns(t, testDSNStore(t, "GITHUB_EXPORTER_TEST_MYSQL_DSN"))
+}
+
+func TestMysqlConcurrentWorkflowJobs(t *testing.T) {
+	testConcurrentWorkflowJobs(t, testDSNStore(t, "GITHUB_EXPORTER_TEST_MYSQL_DSN"))
+}
+
+func TestMysqlConcurrentDeploymentReviews(t *testing.T) {
+	testConcurrentDeploymentReviews(t, testDSNStore(t, "GITHUB_EXPORTER_TEST_MYSQL_DSN"))
+}
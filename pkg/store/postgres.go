@@ -83,7 +83,74 @@ var (
 			Description: "Fix run_id be BIGINT",
 			Script:      `ALTER TABLE workflow_jobs ALTER COLUMN run_id TYPE BIGINT USING run_id::BIGINT;`,
 		},
+		{
+			Version:     6,
+			Description: "Creating table deployment_reviews",
+			Script: `CREATE TABLE deployment_reviews (
+				owner TEXT NOT NULL,
+				repo TEXT NOT NULL,
+				run_id BIGINT NOT NULL,
+				environment TEXT NOT NULL,
+				status TEXT,
+				approver TEXT,
+				requested_at BIGINT,
+				reviewed_at BIGINT,
+				PRIMARY KEY(owner, repo, run_id, environment)
+			);`,
+		},
+		{
+			Version:     7,
+			Description: "Creating table workflow_run_rollups",
+			Script: `CREATE TABLE workflow_run_rollups (
+				owner TEXT NOT NULL,
+				repo TEXT NOT NULL,
+				workflow_id BIGINT NOT NULL,
+				name TEXT,
+				bucket BIGINT NOT NULL,
+				total BIGINT,
+				success BIGINT,
+				failure BIGINT,
+				duration BIGINT,
+				PRIMARY KEY(owner, repo, workflow_id, bucket)
+			);`,
+		},
+		{
+			Version:     8,
+			Description: "Creating table leases",
+			Script: `CREATE TABLE leases (
+				name TEXT NOT NULL,
+				holder TEXT NOT NULL,
+				expires_at BIGINT NOT NULL,
+				PRIMARY KEY(name)
+			);`,
+		},
+		{
+			Version:     9,
+			Description: "Creating table http_cache",
+			Script: `CREATE TABLE http_cache (
+				cache_key TEXT NOT NULL,
+				etag TEXT NOT NULL,
+				last_modified TEXT NOT NULL,
+				header TEXT NOT NULL,
+				body BYTEA NOT NULL,
+				updated_at BIGINT NOT NULL,
+				PRIMARY KEY(cache_key)
+			);`,
+		},
+		{
+			Version:     10,
+			Description: "Creating index for workflow_runs retention",
+			Script:      `CREATE INDEX workflow_runs_retention_idx ON workflow_runs (owner, repo, updated_at);`,
+		},
+		{
+			Version:     11,
+			Description: "Creating index for workflow_jobs retention",
+			Script:      `CREATE INDEX workflow_jobs_retention_idx ON workflow_jobs (owner, repo, created_at);`,
+		},
 	}
+
+	// postgresSizeQuery fetches the size of the current database including indices.
+	postgresSizeQuery = `SELECT pg_database_size(current_database());`
 )
 
 func init() {
@@ -157,9 +224,18 @@ func (s *postgresStore) Migrate() error {
 	return nil
 }
 
+// Migrations returns the status of all db migrations.
+func (s *postgresStore) Migrations() ([]*Migration, error) {
+	return migrationStatus(
+		s.handle.DB,
+		dialect.PostgresDialect{},
+		postgresMigrations,
+	)
+}
+
 // StoreWorkflowRunEvent implements the Store interface.
 func (s *postgresStore) StoreWorkflowRunEvent(event *github.WorkflowRunEvent) error {
-	return storeWorkflowRunEvent(s.handle, event)
+	return storeWorkflowRunEvent(s.handle, upsertWorkflowRunQuery, event)
 }
 
 // GetWorkflowRuns implements the Store interface.
@@ -167,14 +243,45 @@ func (s *postgresStore) GetWorkflowRuns(window time.Duration) ([]*WorkflowRun, e
 	return getWorkflowRuns(s.handle, window)
 }
 
+// WalkWorkflowRuns implements the Store interface.
+func (s *postgresStore) WalkWorkflowRuns(window time.Duration, limit int, fn func(*WorkflowRun) error) error {
+	return walkWorkflowRuns(s.handle, walkWorkflowRunsQuery, window, limit, fn)
+}
+
 // PruneWorkflowRuns implements the Store interface.
-func (s *postgresStore) PruneWorkflowRuns(timeframe time.Duration) error {
-	return pruneWorkflowRuns(s.handle, timeframe)
+func (s *postgresStore) PruneWorkflowRuns(retention Retention, limit int) (map[string]int64, error) {
+	return pruneWorkflowRuns(
+		s.handle,
+		postgresPruneQuery("workflow_runs", "updated_at < :timeframe"),
+		postgresPruneQuery("workflow_runs", "owner=:owner AND repo=:repo AND updated_at < :timeframe"),
+		retention,
+		limit,
+	)
+}
+
+// CountWorkflowRuns implements the Store interface.
+func (s *postgresStore) CountWorkflowRuns() (int64, error) {
+	return countWorkflowRuns(s.handle)
+}
+
+// ListWorkflowRuns implements the Store interface.
+func (s *postgresStore) ListWorkflowRuns(offset, limit int) ([]*WorkflowRun, error) {
+	return listWorkflowRuns(s.handle, listWorkflowRunsQuery, offset, limit)
+}
+
+// ImportWorkflowRuns implements the Store interface.
+func (s *postgresStore) ImportWorkflowRuns(records []*WorkflowRun) error {
+	return importWorkflowRuns(s.handle, upsertWorkflowRunQuery, records)
+}
+
+// QueryWorkflowRuns implements the Store interface.
+func (s *postgresStore) QueryWorkflowRuns(filter *Filter) ([]*WorkflowRun, error) {
+	return queryWorkflowRuns(s.handle, queryWorkflowRunsOrder, filter)
 }
 
 // StoreWorkflowJobEvent implements the Store interface.
 func (s *postgresStore) StoreWorkflowJobEvent(event *github.WorkflowJobEvent) error {
-	return storeWorkflowJobEvent(s.handle, event)
+	return storeWorkflowJobEvent(s.handle, upsertWorkflowJobQuery, event)
 }
 
 // GetWorkflowJobs implements the Store interface.
@@ -182,9 +289,155 @@ func (s *postgresStore) GetWorkflowJobs(window time.Duration) ([]*WorkflowJob, e
 	return getWorkflowJobs(s.handle, window)
 }
 
+// WalkWorkflowJobs implements the Store interface.
+func (s *postgresStore) WalkWorkflowJobs(window time.Duration, limit int, fn func(*WorkflowJob) error) error {
+	return walkWorkflowJobs(s.handle, walkWorkflowJobsQuery, window, limit, fn)
+}
+
+// GetWorkflowJobStats implements the Store interface.
+func (s *postgresStore) GetWorkflowJobStats(window time.Duration) ([]*WorkflowJobStats, error) {
+	return getWorkflowJobStats(s.handle, selectWorkflowJobStatsQuery, window)
+}
+
 // PruneWorkflowJobs implements the Store interface.
-func (s *postgresStore) PruneWorkflowJobs(timeframe time.Duration) error {
-	return pruneWorkflowJobs(s.handle, timeframe)
+func (s *postgresStore) PruneWorkflowJobs(retention Retention, limit int) (map[string]int64, error) {
+	return pruneWorkflowJobs(
+		s.handle,
+		postgresPruneQuery("workflow_jobs", "created_at < :timeframe"),
+		postgresPruneQuery("workflow_jobs", "owner=:owner AND repo=:repo AND created_at < :timeframe"),
+		retention,
+		limit,
+	)
+}
+
+// CountWorkflowJobs implements the Store interface.
+func (s *postgresStore) CountWorkflowJobs() (int64, error) {
+	return countWorkflowJobs(s.handle)
+}
+
+// ListWorkflowJobs implements the Store interface.
+func (s *postgresStore) ListWorkflowJobs(offset, limit int) ([]*WorkflowJob, error) {
+	return listWorkflowJobs(s.handle, listWorkflowJobsQuery, offset, limit)
+}
+
+// ImportWorkflowJobs implements the Store interface.
+func (s *postgresStore) ImportWorkflowJobs(records []*WorkflowJob) error {
+	return importWorkflowJobs(s.handle, upsertWorkflowJobQuery, records)
+}
+
+// QueryWorkflowJobs implements the Store interface.
+func (s *postgresStore) QueryWorkflowJobs(filter *Filter) ([]*WorkflowJob, error) {
+	return queryWorkflowJobs(s.handle, queryWorkflowJobsOrder, filter)
+}
+
+// RollupWorkflowRuns implements the Store interface.
+func (s *postgresStore) RollupWorkflowRuns(timeframe time.Duration) (int64, error) {
+	return rollupWorkflowRuns(s.handle, timeframe)
+}
+
+// GetWorkflowRunRollups implements the Store interface.
+func (s *postgresStore) GetWorkflowRunRollups(window time.Duration) ([]*WorkflowRunRollup, error) {
+	return getWorkflowRunRollups(s.handle, window)
+}
+
+// PruneWorkflowRunRollups implements the Store interface.
+func (s *postgresStore) PruneWorkflowRunRollups(timeframe time.Duration, limit int) (int64, error) {
+	return pruneWorkflowRunRollups(s.handle, postgresPruneQuery("workflow_run_rollups", "bucket < :timeframe"), timeframe, limit)
+}
+
+// CountWorkflowRunRollups implements the Store interface.
+func (s *postgresStore) CountWorkflowRunRollups() (int64, error) {
+	return countWorkflowRunRollups(s.handle)
+}
+
+// ListWorkflowRunRollups implements the Store interface.
+func (s *postgresStore) ListWorkflowRunRollups(offset, limit int) ([]*WorkflowRunRollup, error) {
+	return listWorkflowRunRollups(s.handle, listWorkflowRunRollupsQuery, offset, limit)
+}
+
+// ImportWorkflowRunRollups implements the Store interface.
+func (s *postgresStore) ImportWorkflowRunRollups(records []*WorkflowRunRollup) error {
+	return importWorkflowRunRollups(s.handle, records)
+}
+
+// StoreDeploymentProtectionRuleEvent implements the Store interface.
+func (s *postgresStore) StoreDeploymentProtectionRuleEvent(event *github.DeploymentProtectionRuleEvent) error {
+	return storeDeploymentProtectionRuleEvent(s.handle, upsertDeploymentRequestQuery, event)
+}
+
+// StoreDeploymentReviewEvent implements the Store interface.
+func (s *postgresStore) StoreDeploymentReviewEvent(event *github.DeploymentReviewEvent) error {
+	return storeDeploymentReviewEvent(s.handle, upsertDeploymentRequestQuery, upsertDeploymentReviewQuery, event)
+}
+
+// GetDeploymentReviews implements the Store interface.
+func (s *postgresStore) GetDeploymentReviews(window time.Duration) ([]*DeploymentReview, error) {
+	return getDeploymentReviews(s.handle, window)
+}
+
+// PruneDeploymentReviews implements the Store interface.
+func (s *postgresStore) PruneDeploymentReviews(timeframe time.Duration, limit int) (int64, error) {
+	return pruneDeploymentReviews(s.handle, postgresPruneQuery("deployment_reviews", "requested_at < :timeframe"), timeframe, limit)
+}
+
+// CountDeploymentReviews implements the Store interface.
+func (s *postgresStore) CountDeploymentReviews() (int64, error) {
+	return countDeploymentReviews(s.handle)
+}
+
+// ListDeploymentReviews implements the Store interface.
+func (s *postgresStore) ListDeploymentReviews(offset, limit int) ([]*DeploymentReview, error) {
+	return listDeploymentReviews(s.handle, listDeploymentReviewsQuery, offset, limit)
+}
+
+// ImportDeploymentReviews implements the Store interface.
+func (s *postgresStore) ImportDeploymentReviews(records []*DeploymentReview) error {
+	return importDeploymentReviews(s.handle, records)
+}
+
+// GetCacheEntry implements the Store interface.
+func (s *postgresStore) GetCacheEntry(key string) (*CacheEntry, error) {
+	return getCacheEntry(s.handle, key)
+}
+
+// StoreCacheEntry implements the Store interface.
+func (s *postgresStore) StoreCacheEntry(record *CacheEntry) error {
+	return storeCacheEntry(s.handle, record)
+}
+
+// PruneCacheEntries implements the Store interface.
+func (s *postgresStore) PruneCacheEntries(timeframe time.Duration, limit int) (int64, error) {
+	return pruneCacheEntries(s.handle, postgresPruneQuery("http_cache", "updated_at < :timeframe"), timeframe, limit)
+}
+
+// AcquireLease implements the Store interface.
+func (s *postgresStore) AcquireLease(name, holder string, ttl time.Duration) (bool, error) {
+	return acquireLease(s.handle, name, holder, ttl)
+}
+
+// ReleaseLease implements the Store interface.
+func (s *postgresStore) ReleaseLease(name, holder string) error {
+	return releaseLease(s.handle, name, holder)
+}
+
+// GetTableStats implements the Store interface.
+func (s *postgresStore) GetTableStats() ([]*TableStats, error) {
+	return getTableStats(s.handle)
+}
+
+// GetSize implements the Store interface.
+func (s *postgresStore) GetSize() (int64, error) {
+	return getSize(s.handle, postgresSizeQuery)
+}
+
+// postgresPruneQuery deletes a batch of records, locked rows get skipped to
+// avoid blocking concurrent exporters.
+func postgresPruneQuery(table, condition string) string {
+	return fmt.Sprintf(
+		"DELETE FROM %[1]s WHERE ctid IN (SELECT ctid FROM %[1]s WHERE %[2]s LIMIT :limit FOR UPDATE SKIP LOCKED);",
+		table,
+		condition,
+	)
 }
 
 func (s *postgresStore) dsn() string {
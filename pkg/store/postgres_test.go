@@ -0,0 +1,17 @@
+package store
+
+import (
+	"testing"
+)
+
+func TestPostgresConcurrentWorkflowRuns(t *testing.T) {
+	testConcurrentWorkflowRuns(t, testDSNStore(t, "GITHUB_EXPORTER_TEST_POSTGRES_DSN"))
+}
+
+func TestPostgresConcurrentWorkflowJobs(t *testing.T) {
+	testConcurrentWorkflowJobs(t, testDSNStore(t, "GITHUB_EXPORTER_TEST_POSTGRES_DSN"))
+}
+
+func TestPostgresConcurrentDeploymentReviews(t *testing.T) {
+	testConcurrentDeploymentReviews(t, testDSNStore(t, "GITHUB_EXPORTER_TEST_POSTGRES_DSN"))
+}
@@ -0,0 +1,91 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ryanuber/go-glob"
+)
+
+const (
+	// RetentionDefault defines the rule name of records which are not matched
+	// by any retention rule.
+	RetentionDefault = "default"
+)
+
+// RetentionRule defines a purge window for repositories matching the pattern.
+// Patterns without a slash only match the owner, otherwise they get matched
+// against owner/repo. Like for all other patterns a wildcard also matches
+// slashes.
+type RetentionRule struct {
+	Pattern string
+	Window  time.Duration
+}
+
+// Retention defines the default purge window and optional rules overriding
+// it, the first matching rule wins.
+type Retention struct {
+	Window time.Duration
+	Rules  []RetentionRule
+}
+
+// Resolve returns the name of the matching rule and its purge window.
+func (r Retention) Resolve(owner, repo string) (string, time.Duration) {
+	for _, rule := range r.Rules {
+		name := owner
+
+		if strings.Contains(rule.Pattern, "/") {
+			name = owner + "/" + repo
+		}
+
+		if glob.Glob(rule.Pattern, name) {
+			return rule.Pattern, rule.Window
+		}
+	}
+
+	return RetentionDefault, r.Window
+}
+
+// Shortest returns the shortest purge window any repository could resolve to.
+func (r Retention) Shortest() time.Duration {
+	result := r.Window
+
+	for _, rule := range r.Rules {
+		result = min(result, rule.Window)
+	}
+
+	return result
+}
+
+// ParseRetentionRules parses rules defined as pattern=duration, like
+// promhippie/*=2160h or sandbox-*=48h.
+func ParseRetentionRules(values []string) ([]RetentionRule, error) {
+	result := make([]RetentionRule, 0, len(values))
+
+	for _, value := range values {
+		idx := strings.LastIndex(value, "=")
+
+		if idx < 1 {
+			return nil, fmt.Errorf("invalid retention rule %q, expected pattern=duration", value)
+		}
+
+		pattern := strings.TrimSpace(value[:idx])
+		window, err := time.ParseDuration(strings.TrimSpace(value[idx+1:]))
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid retention window %q: %w", value, err)
+		}
+
+		if window <= 0 {
+			return nil, fmt.Errorf("invalid retention window %q: must be positive", value)
+		}
+
+		result = append(result, RetentionRule{
+			Pattern: pattern,
+			Window:  window,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetentionResolve(t *testing.T) {
+	retention := Retention{
+		Window: 24 * time.Hour,
+		Rules: []RetentionRule{
+			{Pattern: "promhippie/github_exporter", Window: 90 * 24 * time.Hour},
+			{Pattern: "sandbox-*", Window: 48 * time.Hour},
+			{Pattern: "promhippie/*", Window: 30 * 24 * time.Hour},
+			{Pattern: "*/archive-*", Window: 12 * time.Hour},
+		},
+	}
+
+	tests := []struct {
+		owner  string
+		repo   string
+		rule   string
+		window time.Duration
+	}{
+		{"promhippie", "github_exporter", "promhippie/github_exporter", 90 * 24 * time.Hour},
+		{"promhippie", "dockerhub_exporter", "promhippie/*", 30 * 24 * time.Hour},
+		{"sandbox-one", "anything", "sandbox-*", 48 * time.Hour},
+		{"webhippie", "archive-2020", "*/archive-*", 12 * time.Hour},
+		{"other", "repo", RetentionDefault, 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		rule, window := retention.Resolve(tt.owner, tt.repo)
+		assert.Equal(t, tt.rule, rule, tt.owner+"/"+tt.repo)
+		assert.Equal(t, tt.window, window, tt.owner+"/"+tt.repo)
+	}
+}
+
+func TestRetentionShortest(t *testing.T) {
+	assert.Equal(t, 24*time.Hour, Retention{Window: 24 * time.Hour}.Shortest())
+
+	assert.Equal(t, 6*time.Hour, Retention{
+		Window: 24 * time.Hour,
+		Rules: []RetentionRule{
+			{Pattern: "promhippie/*", Window: 90 * 24 * time.Hour},
+			{Pattern: "sandbox-*", Window: 6 * time.Hour},
+		},
+	}.Shortest())
+}
+
+func TestParseRetentionRules(t *testing.T) {
+	rules, err := ParseRetentionRules([]string{"promhippie/*=2160h", " sandbox-* = 48h "})
+	require.NoError(t, err)
+	assert.Equal(t, []RetentionRule{
+		{Pattern: "promhippie/*", Window: 2160 * time.Hour},
+		{Pattern: "sandbox-*", Window: 48 * time.Hour},
+	}, rules)
+
+	for _, value := range []string{"promhippie", "=48h", "promhippie=later", "promhippie=-1h"} {
+		_, err := ParseRetentionRules([]string{value})
+		assert.Error(t, err, value)
+	}
+}
@@ -74,7 +74,74 @@ var (
 				PRIMARY KEY(owner, repo, identifier)
 			);`,
 		},
+		{
+			Version:     4,
+			Description: "Creating table deployment_reviews",
+			Script: `CREATE TABLE deployment_reviews (
+				owner TEXT NOT NULL,
+				repo TEXT NOT NULL,
+				run_id BIGINT NOT NULL,
+				environment TEXT NOT NULL,
+				status TEXT,
+				approver TEXT,
+				requested_at BIGINT,
+				reviewed_at BIGINT,
+				PRIMARY KEY(owner, repo, run_id, environment)
+			);`,
+		},
+		{
+			Version:     5,
+			Description: "Creating table workflow_run_rollups",
+			Script: `CREATE TABLE workflow_run_rollups (
+				owner TEXT NOT NULL,
+				repo TEXT NOT NULL,
+				workflow_id BIGINT NOT NULL,
+				name TEXT,
+				bucket BIGINT NOT NULL,
+				total BIGINT,
+				success BIGINT,
+				failure BIGINT,
+				duration BIGINT,
+				PRIMARY KEY(owner, repo, workflow_id, bucket)
+			);`,
+		},
+		{
+			Version:     6,
+			Description: "Creating table leases",
+			Script: `CREATE TABLE leases (
+				name TEXT NOT NULL,
+				holder TEXT NOT NULL,
+				expires_at BIGINT NOT NULL,
+				PRIMARY KEY(name)
+			);`,
+		},
+		{
+			Version:     7,
+			Description: "Creating table http_cache",
+			Script: `CREATE TABLE http_cache (
+				cache_key TEXT NOT NULL,
+				etag TEXT NOT NULL,
+				last_modified TEXT NOT NULL,
+				header TEXT NOT NULL,
+				body BLOB NOT NULL,
+				updated_at BIGINT NOT NULL,
+				PRIMARY KEY(cache_key)
+			);`,
+		},
+		{
+			Version:     8,
+			Description: "Creating index for workflow_runs retention",
+			Script:      `CREATE INDEX workflow_runs_retention_idx ON workflow_runs (owner, repo, updated_at);`,
+		},
+		{
+			Version:     9,
+			Description: "Creating index for workflow_jobs retention",
+			Script:      `CREATE INDEX workflow_jobs_retention_idx ON workflow_jobs (owner, repo, created_at);`,
+		},
 	}
+
+	// sqliteSizeQuery calculates the size of the database file based on the pages.
+	sqliteSizeQuery = `SELECT page_count * page_size FROM pragma_page_count(), pragma_page_size();`
 )
 
 // sqliteStore implements the Store interface for SQLite.
@@ -144,9 +211,18 @@ func (s *sqliteStore) Migrate() error {
 	return nil
 }
 
+// Migrations returns the status of all db migrations.
+func (s *sqliteStore) Migrations() ([]*Migration, error) {
+	return migrationStatus(
+		s.handle.DB,
+		dialect.SqliteDialect{},
+		sqliteMigrations,
+	)
+}
+
 // StoreWorkflowRunEvent implements the Store interface.
 func (s *sqliteStore) StoreWorkflowRunEvent(event *github.WorkflowRunEvent) error {
-	return storeWorkflowRunEvent(s.handle, event)
+	return storeWorkflowRunEvent(s.handle, upsertWorkflowRunQuery, event)
 }
 
 // GetWorkflowRuns implements the Store interface.
@@ -154,14 +230,45 @@ func (s *sqliteStore) GetWorkflowRuns(window time.Duration) ([]*WorkflowRun, err
 	return getWorkflowRuns(s.handle, window)
 }
 
+// WalkWorkflowRuns implements the Store interface.
+func (s *sqliteStore) WalkWorkflowRuns(window time.Duration, limit int, fn func(*WorkflowRun) error) error {
+	return walkWorkflowRuns(s.handle, walkWorkflowRunsQuery, window, limit, fn)
+}
+
 // PruneWorkflowRuns implements the Store interface.
-func (s *sqliteStore) PruneWorkflowRuns(timeframe time.Duration) error {
-	return pruneWorkflowRuns(s.handle, timeframe)
+func (s *sqliteStore) PruneWorkflowRuns(retention Retention, limit int) (map[string]int64, error) {
+	return pruneWorkflowRuns(
+		s.handle,
+		sqlitePruneQuery("workflow_runs", "updated_at < :timeframe"),
+		sqlitePruneQuery("workflow_runs", "owner=:owner AND repo=:repo AND updated_at < :timeframe"),
+		retention,
+		limit,
+	)
+}
+
+// CountWorkflowRuns implements the Store interface.
+func (s *sqliteStore) CountWorkflowRuns() (int64, error) {
+	return countWorkflowRuns(s.handle)
+}
+
+// ListWorkflowRuns implements the Store interface.
+func (s *sqliteStore) ListWorkflowRuns(offset, limit int) ([]*WorkflowRun, error) {
+	return listWorkflowRuns(s.handle, listWorkflowRunsQuery, offset, limit)
+}
+
+// ImportWorkflowRuns implements the Store interface.
+func (s *sqliteStore) ImportWorkflowRuns(records []*WorkflowRun) error {
+	return importWorkflowRuns(s.handle, upsertWorkflowRunQuery, records)
+}
+
+// QueryWorkflowRuns implements the Store interface.
+func (s *sqliteStore) QueryWorkflowRuns(filter *Filter) ([]*WorkflowRun, error) {
+	return queryWorkflowRuns(s.handle, queryWorkflowRunsOrder, filter)
 }
 
 // StoreWorkflowJobEvent implements the Store interface.
 func (s *sqliteStore) StoreWorkflowJobEvent(event *github.WorkflowJobEvent) error {
-	return storeWorkflowJobEvent(s.handle, event)
+	return storeWorkflowJobEvent(s.handle, upsertWorkflowJobQuery, event)
 }
 
 // GetWorkflowJobs implements the Store interface.
@@ -169,9 +276,154 @@ func (s *sqliteStore) GetWorkflowJobs(window time.Duration) ([]*WorkflowJob, err
 	return getWorkflowJobs(s.handle, window)
 }
 
+// WalkWorkflowJobs implements the Store interface.
+func (s *sqliteStore) WalkWorkflowJobs(window time.Duration, limit int, fn func(*WorkflowJob) error) error {
+	return walkWorkflowJobs(s.handle, walkWorkflowJobsQuery, window, limit, fn)
+}
+
+// GetWorkflowJobStats implements the Store interface.
+func (s *sqliteStore) GetWorkflowJobStats(window time.Duration) ([]*WorkflowJobStats, error) {
+	return getWorkflowJobStats(s.handle, selectWorkflowJobStatsQuery, window)
+}
+
 // PruneWorkflowJobs implements the Store interface.
-func (s *sqliteStore) PruneWorkflowJobs(timeframe time.Duration) error {
-	return pruneWorkflowJobs(s.handle, timeframe)
+func (s *sqliteStore) PruneWorkflowJobs(retention Retention, limit int) (map[string]int64, error) {
+	return pruneWorkflowJobs(
+		s.handle,
+		sqlitePruneQuery("workflow_jobs", "created_at < :timeframe"),
+		sqlitePruneQuery("workflow_jobs", "owner=:owner AND repo=:repo AND created_at < :timeframe"),
+		retention,
+		limit,
+	)
+}
+
+// CountWorkflowJobs implements the Store interface.
+func (s *sqliteStore) CountWorkflowJobs() (int64, error) {
+	return countWorkflowJobs(s.handle)
+}
+
+// ListWorkflowJobs implements the Store interface.
+func (s *sqliteStore) ListWorkflowJobs(offset, limit int) ([]*WorkflowJob, error) {
+	return listWorkflowJobs(s.handle, listWorkflowJobsQuery, offset, limit)
+}
+
+// ImportWorkflowJobs implements the Store interface.
+func (s *sqliteStore) ImportWorkflowJobs(records []*WorkflowJob) error {
+	return importWorkflowJobs(s.handle, upsertWorkflowJobQuery, records)
+}
+
+// QueryWorkflowJobs implements the Store interface.
+func (s *sqliteStore) QueryWorkflowJobs(filter *Filter) ([]*WorkflowJob, error) {
+	return queryWorkflowJobs(s.handle, queryWorkflowJobsOrder, filter)
+}
+
+// RollupWorkflowRuns implements the Store interface.
+func (s *sqliteStore) RollupWorkflowRuns(timeframe time.Duration) (int64, error) {
+	return rollupWorkflowRuns(s.handle, timeframe)
+}
+
+// GetWorkflowRunRollups implements the Store interface.
+func (s *sqliteStore) GetWorkflowRunRollups(window time.Duration) ([]*WorkflowRunRollup, error) {
+	return getWorkflowRunRollups(s.handle, window)
+}
+
+// PruneWorkflowRunRollups implements the Store interface.
+func (s *sqliteStore) PruneWorkflowRunRollups(timeframe time.Duration, limit int) (int64, error) {
+	return pruneWorkflowRunRollups(s.handle, sqlitePruneQuery("workflow_run_rollups", "bucket < :timeframe"), timeframe, limit)
+}
+
+// CountWorkflowRunRollups implements the Store interface.
+func (s *sqliteStore) CountWorkflowRunRollups() (int64, error) {
+	return countWorkflowRunRollups(s.handle)
+}
+
+// ListWorkflowRunRollups implements the Store interface.
+func (s *sqliteStore) ListWorkflowRunRollups(offset, limit int) ([]*WorkflowRunRollup, error) {
+	return listWorkflowRunRollups(s.handle, listWorkflowRunRollupsQuery, offset, limit)
+}
+
+// ImportWorkflowRunRollups implements the Store interface.
+func (s *sqliteStore) ImportWorkflowRunRollups(records []*WorkflowRunRollup) error {
+	return importWorkflowRunRollups(s.handle, records)
+}
+
+// StoreDeploymentProtectionRuleEvent implements the Store interface.
+func (s *sqliteStore) StoreDeploymentProtectionRuleEvent(event *github.DeploymentProtectionRuleEvent) error {
+	return storeDeploymentProtectionRuleEvent(s.handle, upsertDeploymentRequestQuery, event)
+}
+
+// StoreDeploymentReviewEvent implements the Store interface.
+func (s *sqliteStore) StoreDeploymentReviewEvent(event *github.DeploymentReviewEvent) error {
+	return storeDeploymentReviewEvent(s.handle, upsertDeploymentRequestQuery, upsertDeploymentReviewQuery, event)
+}
+
+// GetDeploymentReviews implements the Store interface.
+func (s *sqliteStore) GetDeploymentReviews(window time.Duration) ([]*DeploymentReview, error) {
+	return getDeploymentReviews(s.handle, window)
+}
+
+// PruneDeploymentReviews implements the Store interface.
+func (s *sqliteStore) PruneDeploymentReviews(timeframe time.Duration, limit int) (int64, error) {
+	return pruneDeploymentReviews(s.handle, sqlitePruneQuery("deployment_reviews", "requested_at < :timeframe"), timeframe, limit)
+}
+
+// CountDeploymentReviews implements the Store interface.
+func (s *sqliteStore) CountDeploymentReviews() (int64, error) {
+	return countDeploymentReviews(s.handle)
+}
+
+// ListDeploymentReviews implements the Store interface.
+func (s *sqliteStore) ListDeploymentReviews(offset, limit int) ([]*DeploymentReview, error) {
+	return listDeploymentReviews(s.handle, listDeploymentReviewsQuery, offset, limit)
+}
+
+// ImportDeploymentReviews implements the Store interface.
+func (s *sqliteStore) ImportDeploymentReviews(records []*DeploymentReview) error {
+	return importDeploymentReviews(s.handle, records)
+}
+
+// GetCacheEntry implements the Store interface.
+func (s *sqliteStore) GetCacheEntry(key string) (*CacheEntry, error) {
+	return getCacheEntry(s.handle, key)
+}
+
+// StoreCacheEntry implements the Store interface.
+func (s *sqliteStore) StoreCacheEntry(record *CacheEntry) error {
+	return storeCacheEntry(s.handle, record)
+}
+
+// PruneCacheEntries implements the Store interface.
+func (s *sqliteStore) PruneCacheEntries(timeframe time.Duration, limit int) (int64, error) {
+	return pruneCacheEntries(s.handle, sqlitePruneQuery("http_cache", "updated_at < :timeframe"), timeframe, limit)
+}
+
+// AcquireLease implements the Store interface.
+func (s *sqliteStore) AcquireLease(name, holder string, ttl time.Duration) (bool, error) {
+	return acquireLease(s.handle, name, holder, ttl)
+}
+
+// ReleaseLease implements the Store interface.
+func (s *sqliteStore) ReleaseLease(name, holder string) error {
+	return releaseLease(s.handle, name, holder)
+}
+
+// GetTableStats implements the Store interface.
+func (s *sqliteStore) GetTableStats() ([]*TableStats, error) {
+	return getTableStats(s.handle)
+}
+
+// GetSize implements the Store interface.
+func (s *sqliteStore) GetSize() (int64, error) {
+	return getSize(s.handle, sqliteSizeQuery)
+}
+
+// sqlitePruneQuery deletes a batch of records.
+func sqlitePruneQuery(table, condition string) string {
+	return fmt.Sprintf(
+		"DELETE FROM %[1]s WHERE rowid IN (SELECT rowid FROM %[1]s WHERE %[2]s LIMIT :limit);",
+		table,
+		condition,
+	)
 }
 
 func (s *sqliteStore) dsn() string {
@@ -0,0 +1,375 @@
+//go:build sqlite
+
+package store
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSqliteStore(t *testing.T) Store {
+	t.Helper()
+
+	s, err := NewSqliteStore(
+		fmt.Sprintf("sqlite://%s", path.Join(t.TempDir(), "test.db")),
+		slog.Default(),
+	)
+
+	require.NoError(t, err)
+
+	_, err = s.Open()
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = s.Close() })
+	require.NoError(t, s.Migrate())
+
+	return s
+}
+
+func TestSqliteConcurrentWorkflowRuns(t *testing.T) {
+	testConcurrentWorkflowRuns(t, testSqliteStore(t))
+}
+
+func TestSqliteConcurrentWorkflowJobs(t *testing.T) {
+	testConcurrentWorkflowJobs(t, testSqliteStore(t))
+}
+
+func TestSqliteConcurrentDeploymentReviews(t *testing.T) {
+	testConcurrentDeploymentReviews(t, testSqliteStore(t))
+}
+
+func TestSqlitePruneWorkflowJobs(t *testing.T) {
+	s := testSqliteStore(t)
+	now := time.Now()
+
+	for i := range 25 {
+		require.NoError(t, s.StoreWorkflowJobEvent(&github.WorkflowJobEvent{
+			Repo: &github.Repository{
+				Name:  github.Ptr("github_exporter"),
+				Owner: &github.User{Login: github.Ptr("promhippie")},
+			},
+			WorkflowJob: &github.WorkflowJob{
+				ID:        github.Ptr(int64(i + 1)),
+				RunID:     github.Ptr(int64(1)),
+				Status:    github.Ptr("queued"),
+				CreatedAt: &github.Timestamp{Time: now.Add(-2 * time.Hour)},
+			},
+		}))
+	}
+
+	deleted, err := s.PruneWorkflowJobs(Retention{Window: time.Hour}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(25), deleted[RetentionDefault])
+
+	deleted, err = s.PruneWorkflowJobs(Retention{Window: time.Hour}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), deleted[RetentionDefault])
+}
+
+func TestSqlitePruneRetention(t *testing.T) {
+	s := testSqliteStore(t)
+	now := time.Now()
+
+	for i, owner := range []string{"promhippie", "promhippie", "sandbox-one", "other"} {
+		require.NoError(t, s.StoreWorkflowJobEvent(&github.WorkflowJobEvent{
+			Repo: &github.Repository{
+				Name:  github.Ptr("github_exporter"),
+				Owner: &github.User{Login: github.Ptr(owner)},
+			},
+			WorkflowJob: &github.WorkflowJob{
+				ID:        github.Ptr(int64(i + 1)),
+				RunID:     github.Ptr(int64(1)),
+				Status:    github.Ptr("queued"),
+				CreatedAt: &github.Timestamp{Time: now.Add(-72 * time.Hour)},
+			},
+		}))
+	}
+
+	deleted, err := s.PruneWorkflowJobs(Retention{
+		Window: 24 * time.Hour,
+		Rules: []RetentionRule{
+			{Pattern: "promhippie/*", Window: 90 * 24 * time.Hour},
+			{Pattern: "sandbox-*", Window: 48 * time.Hour},
+		},
+	}, 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"promhippie/*": 0, "sandbox-*": 1, RetentionDefault: 1}, deleted)
+
+	count, err := s.CountWorkflowJobs()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestSqliteWalkWorkflowJobs(t *testing.T) {
+	s := testSqliteStore(t)
+	now := time.Now().Truncate(time.Second)
+
+	for i := range 3 {
+		require.NoError(t, s.StoreWorkflowJobEvent(&github.WorkflowJobEvent{
+			Repo: &github.Repository{
+				Name:  github.Ptr("github_exporter"),
+				Owner: &github.User{Login: github.Ptr("promhippie")},
+			},
+			WorkflowJob: &github.WorkflowJob{
+				ID:        github.Ptr(int64(i + 1)),
+				RunID:     github.Ptr(int64(1)),
+				Status:    github.Ptr("queued"),
+				CreatedAt: &github.Timestamp{Time: now.Add(time.Duration(i) * time.Second)},
+			},
+		}))
+	}
+
+	ids := make([]int64, 0)
+
+	err := s.WalkWorkflowJobs(time.Hour, 2, func(record *WorkflowJob) error {
+		ids = append(ids, record.Identifier)
+		return nil
+	})
+
+	assert.ErrorIs(t, err, ErrLimitReached)
+	assert.Equal(t, []int64{3, 2}, ids)
+
+	ids = ids[:0]
+
+	err = s.WalkWorkflowJobs(time.Hour, 3, func(record *WorkflowJob) error {
+		ids = append(ids, record.Identifier)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 2, 1}, ids)
+
+	records, err := s.GetWorkflowJobs(time.Hour)
+	require.NoError(t, err)
+	assert.Len(t, records, 3)
+}
+
+func TestSqliteTableStats(t *testing.T) {
+	s := testSqliteStore(t)
+	now := time.Now().Truncate(time.Second)
+
+	require.NoError(t, s.StoreWorkflowJobEvent(&github.WorkflowJobEvent{
+		Repo: &github.Repository{
+			Name:  github.Ptr("github_exporter"),
+			Owner: &github.User{Login: github.Ptr("promhippie")},
+		},
+		WorkflowJob: &github.WorkflowJob{
+			ID:        github.Ptr(int64(1)),
+			RunID:     github.Ptr(int64(1)),
+			Status:    github.Ptr("queued"),
+			CreatedAt: &github.Timestamp{Time: now},
+		},
+	}))
+
+	records, err := s.GetTableStats()
+	require.NoError(t, err)
+	require.Len(t, records, 6)
+
+	assert.Equal(t, &TableStats{Table: "workflow_runs"}, records[0])
+	assert.Equal(t, &TableStats{Table: "workflow_jobs", Rows: 1, Oldest: now.Unix(), Newest: now.Unix()}, records[1])
+
+	size, err := s.GetSize()
+	require.NoError(t, err)
+	assert.Greater(t, size, int64(0))
+}
+
+func TestSqliteRollupWorkflowRuns(t *testing.T) {
+	s := testSqliteStore(t)
+
+	updated := time.Now().Add(-2 * time.Hour).Truncate(time.Hour).Add(time.Minute)
+	require.NoError(t, s.ImportWorkflowRuns(testRollupRuns(updated)))
+
+	for range 2 {
+		written, err := s.RollupWorkflowRuns(3 * time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), written)
+	}
+
+	records, err := s.GetWorkflowRunRollups(24 * time.Hour)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	assert.Equal(t, int64(2), records[0].Total)
+	assert.Equal(t, int64(1), records[0].Success)
+	assert.Equal(t, int64(1), records[0].Failure)
+	assert.Equal(t, int64(120), records[0].Duration)
+
+	deleted, err := s.PruneWorkflowRunRollups(time.Minute, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
+
+func TestSqliteQueryWorkflowRuns(t *testing.T) {
+	s := testSqliteStore(t)
+	now := time.Now().Unix()
+
+	require.NoError(t, s.ImportWorkflowRuns([]*WorkflowRun{
+		{Owner: "promhippie", Repo: "github_exporter", WorkflowID: 1, Number: 1, Name: "ci", Branch: "main", Status: "failure", UpdatedAt: now - 60},
+		{Owner: "promhippie", Repo: "github_exporter", WorkflowID: 1, Number: 2, Name: "ci", Branch: "main", Status: "failure", UpdatedAt: now},
+		{Owner: "promhippie", Repo: "github_exporter", WorkflowID: 2, Number: 1, Name: "docs", Branch: "main", Status: "failure", UpdatedAt: now},
+	}))
+
+	records, err := s.QueryWorkflowRuns(&Filter{Workflow: "1", Status: "failure", Since: now - 120, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, 2, records[0].Number)
+
+	records, err = s.QueryWorkflowRuns(&Filter{Workflow: "ci", Offset: 1, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, 1, records[0].Number)
+
+	jobs, err := s.QueryWorkflowJobs(&Filter{Owner: "promhippie", Status: "failure"})
+	require.NoError(t, err)
+	assert.Len(t, jobs, 0)
+}
+
+func TestSqliteMigrations(t *testing.T) {
+	s, err := NewSqliteStore(
+		fmt.Sprintf("sqlite://%s", path.Join(t.TempDir(), "test.db")),
+		slog.Default(),
+	)
+
+	require.NoError(t, err)
+
+	_, err = s.Open()
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = s.Close() })
+
+	migrations, err := s.Migrations()
+	require.NoError(t, err)
+	require.Len(t, migrations, len(sqliteMigrations))
+
+	for _, migration := range migrations {
+		assert.Equal(t, MigrationPending, migration.Status)
+	}
+
+	exists := 0
+	require.NoError(t, s.(*sqliteStore).handle.Get(&exists, "SELECT COUNT(*) FROM sqlite_master WHERE name = 'darwin_migrations';"))
+	assert.Zero(t, exists, "expected the status to keep the migration table absent")
+
+	require.NoError(t, s.Migrate())
+
+	migrations, err = s.Migrations()
+	require.NoError(t, err)
+
+	for _, migration := range migrations {
+		assert.Equal(t, MigrationApplied, migration.Status)
+		assert.NotZero(t, migration.AppliedAt)
+	}
+}
+
+func TestSqliteLease(t *testing.T) {
+	s := testSqliteStore(t)
+
+	acquired, err := s.AcquireLease("leader", "first", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	acquired, err = s.AcquireLease("leader", "second", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	acquired, err = s.AcquireLease("leader", "first", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	acquired, err = s.AcquireLease("leader", "first", -time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	acquired, err = s.AcquireLease("leader", "second", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired, "expired lease should be taken over")
+
+	require.NoError(t, s.ReleaseLease("leader", "first"))
+
+	acquired, err = s.AcquireLease("leader", "first", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, acquired, "release of another holder should be ignored")
+
+	require.NoError(t, s.ReleaseLease("leader", "second"))
+
+	acquired, err = s.AcquireLease("leader", "first", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
+
+func TestSqliteCacheEntries(t *testing.T) {
+	s := testSqliteStore(t)
+
+	_, err := s.GetCacheEntry("key")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	for _, etag := range []string{`"v1"`, `"v2"`} {
+		require.NoError(t, s.StoreCacheEntry(&CacheEntry{
+			Key:       "key",
+			ETag:      etag,
+			Header:    `{}`,
+			Body:      []byte(`{"login":"promhippie"}`),
+			UpdatedAt: time.Now().Add(-2 * time.Hour).Unix(),
+		}))
+	}
+
+	record, err := s.GetCacheEntry("key")
+	require.NoError(t, err)
+	assert.Equal(t, `"v2"`, record.ETag)
+	assert.Equal(t, []byte(`{"login":"promhippie"}`), record.Body)
+
+	deleted, err := s.PruneCacheEntries(time.Hour, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
+
+func TestSqliteDeploymentReviews(t *testing.T) {
+	testDeploymentReviews(t, testSqliteStore(t))
+}
+
+func TestSqliteImportDeploymentReviews(t *testing.T) {
+	s := testSqliteStore(t)
+
+	records := []*DeploymentReview{
+		{Owner: "promhippie", Repo: "github_exporter", RunID: 2, Environment: "production", Status: "pending", RequestedAt: 100},
+		{Owner: "promhippie", Repo: "github_exporter", RunID: 1, Environment: "production", Status: "approved", Approver: "tboerger", RequestedAt: 100, ReviewedAt: 200},
+	}
+
+	require.NoError(t, s.ImportDeploymentReviews(records))
+	require.NoError(t, s.ImportDeploymentReviews(records[1:]))
+
+	count, err := s.CountDeploymentReviews()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	listed, err := s.ListDeploymentReviews(0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []*DeploymentReview{records[1]}, listed)
+}
+
+func TestSqliteImportWorkflowRunRollups(t *testing.T) {
+	s := testSqliteStore(t)
+
+	records := []*WorkflowRunRollup{
+		{Owner: "promhippie", Repo: "github_exporter", WorkflowID: 1, Name: "build", Bucket: 7200, Total: 3, Success: 2, Failure: 1, Duration: 30},
+		{Owner: "promhippie", Repo: "github_exporter", WorkflowID: 1, Name: "build", Bucket: 3600, Total: 1, Success: 1, Duration: 10},
+	}
+
+	require.NoError(t, s.ImportWorkflowRunRollups(records))
+	require.NoError(t, s.ImportWorkflowRunRollups(records[1:]))
+
+	count, err := s.CountWorkflowRunRollups()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	listed, err := s.ListWorkflowRunRollups(0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []*WorkflowRunRollup{records[1]}, listed)
+}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"maps"
@@ -15,6 +16,21 @@ import (
 var (
 	// Drivers defines the list of registered database drivers.
 	Drivers = make(map[string]driver, 0)
+
+	// ErrOutdated gets returned if an event got delivered out of order and
+	// would overwrite a more recent state.
+	ErrOutdated = errors.New("event is older than the stored state")
+
+	// ErrLimitReached gets returned if a walk stopped at the maximum number of
+	// rows while more records are available.
+	ErrLimitReached = errors.New("maximum number of rows reached")
+
+	// ErrUnsupported gets returned if the driver does not support an
+	// optional operation.
+	ErrUnsupported = errors.New("operation not supported by driver")
+
+	// ErrNotFound gets returned if a requested record does not exist.
+	ErrNotFound = errors.New("record not found")
 )
 
 type driver func(dsn string, logger *slog.Logger) (Store, error)
@@ -24,17 +40,59 @@ type Store interface {
 	// WorkflowRunEvent
 	StoreWorkflowRunEvent(*github.WorkflowRunEvent) error
 	GetWorkflowRuns(time.Duration) ([]*WorkflowRun, error)
-	PruneWorkflowRuns(time.Duration) error
+	WalkWorkflowRuns(time.Duration, int, func(*WorkflowRun) error) error
+	PruneWorkflowRuns(Retention, int) (map[string]int64, error)
+	CountWorkflowRuns() (int64, error)
+	ListWorkflowRuns(int, int) ([]*WorkflowRun, error)
+	ImportWorkflowRuns([]*WorkflowRun) error
+	QueryWorkflowRuns(*Filter) ([]*WorkflowRun, error)
 
 	// WorkflowJobEvent
 	StoreWorkflowJobEvent(*github.WorkflowJobEvent) error
 	GetWorkflowJobs(time.Duration) ([]*WorkflowJob, error)
-	PruneWorkflowJobs(time.Duration) error
+	WalkWorkflowJobs(time.Duration, int, func(*WorkflowJob) error) error
+	GetWorkflowJobStats(time.Duration) ([]*WorkflowJobStats, error)
+	PruneWorkflowJobs(Retention, int) (map[string]int64, error)
+	CountWorkflowJobs() (int64, error)
+	ListWorkflowJobs(int, int) ([]*WorkflowJob, error)
+	ImportWorkflowJobs([]*WorkflowJob) error
+	QueryWorkflowJobs(*Filter) ([]*WorkflowJob, error)
+
+	// WorkflowRunRollup
+	RollupWorkflowRuns(time.Duration) (int64, error)
+	GetWorkflowRunRollups(time.Duration) ([]*WorkflowRunRollup, error)
+	PruneWorkflowRunRollups(time.Duration, int) (int64, error)
+	CountWorkflowRunRollups() (int64, error)
+	ListWorkflowRunRollups(int, int) ([]*WorkflowRunRollup, error)
+	ImportWorkflowRunRollups([]*WorkflowRunRollup) error
+
+	// DeploymentReview
+	StoreDeploymentProtectionRuleEvent(*github.DeploymentProtectionRuleEvent) error
+	StoreDeploymentReviewEvent(*github.DeploymentReviewEvent) error
+	GetDeploymentReviews(time.Duration) ([]*DeploymentReview, error)
+	PruneDeploymentReviews(time.Duration, int) (int64, error)
+	CountDeploymentReviews() (int64, error)
+	ListDeploymentReviews(int, int) ([]*DeploymentReview, error)
+	ImportDeploymentReviews([]*DeploymentReview) error
+
+	// CacheEntry
+	GetCacheEntry(string) (*CacheEntry, error)
+	StoreCacheEntry(*CacheEntry) error
+	PruneCacheEntries(time.Duration, int) (int64, error)
+
+	// Lease
+	AcquireLease(string, string, time.Duration) (bool, error)
+	ReleaseLease(string, string) error
+
+	// Stats
+	GetTableStats() ([]*TableStats, error)
+	GetSize() (int64, error)
 
 	Open() (bool, error)
 	Close() error
 	Ping() (bool, error)
 	Migrate() error
+	Migrations() ([]*Migration, error)
 }
 
 // New initializes a new database driver supported by current os.
@@ -0,0 +1,235 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDSNStore opens and migrates the store for the DSN defined by the
+// environment variable, the test gets skipped without it.
+func testDSNStore(t *testing.T, env string) Store {
+	t.Helper()
+
+	dsn := os.Getenv(env)
+
+	if dsn == "" {
+		t.Skipf("%s is not defined", env)
+	}
+
+	s, err := New(dsn, slog.Default())
+	require.NoError(t, err)
+
+	_, err = s.Open()
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = s.Close() })
+	require.NoError(t, s.Migrate())
+
+	return s
+}
+
+// testGuardOwner returns a unique owner, that way the guard tests don't
+// interfere with records of previous runs on shared databases.
+func testGuardOwner() string {
+	return fmt.Sprintf("guard-%d", time.Now().UnixNano())
+}
+
+// testConcurrentWorkflowRuns stores out of order events of the same run
+// concurrently, only the most recent state must be kept.
+func testConcurrentWorkflowRuns(t *testing.T, s Store) {
+	owner := testGuardOwner()
+	now := time.Now().Truncate(time.Second)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+
+	for i := range 50 {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			status := "in_progress"
+
+			if i == 49 {
+				status = "completed"
+			}
+
+			errs <- s.StoreWorkflowRunEvent(&github.WorkflowRunEvent{
+				Repo: &github.Repository{
+					Name:  github.Ptr("github_exporter"),
+					Owner: &github.User{Login: github.Ptr(owner)},
+				},
+				WorkflowRun: &github.WorkflowRun{
+					ID:         github.Ptr(int64(1)),
+					WorkflowID: github.Ptr(int64(1)),
+					RunNumber:  github.Ptr(1),
+					Status:     github.Ptr(status),
+					CreatedAt:  &github.Timestamp{Time: now},
+					UpdatedAt:  &github.Timestamp{Time: now.Add(time.Duration(i) * time.Second)},
+				},
+			})
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	records, err := s.QueryWorkflowRuns(&Filter{Owner: owner})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	assert.Equal(t, "completed", records[0].Status)
+	assert.Equal(t, now.Add(49*time.Second).Unix(), records[0].UpdatedAt)
+}
+
+// testConcurrentWorkflowJobs stores out of order events of the same job
+// concurrently, the job must never regress to a previous state.
+func testConcurrentWorkflowJobs(t *testing.T, s Store) {
+	owner := testGuardOwner()
+	now := time.Now().Truncate(time.Second)
+	states := []string{"queued", "waiting", "in_progress", "completed"}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+
+	for i := range 100 {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			job := &github.WorkflowJob{
+				ID:        github.Ptr(int64(1)),
+				RunID:     github.Ptr(int64(1)),
+				Status:    github.Ptr(states[i%len(states)]),
+				CreatedAt: &github.Timestamp{Time: now},
+			}
+
+			if i%len(states) >= 2 {
+				job.StartedAt = &github.Timestamp{Time: now.Add(time.Second)}
+			}
+
+			if i%len(states) == 3 {
+				job.CompletedAt = &github.Timestamp{Time: now.Add(time.Minute)}
+			}
+
+			errs <- s.StoreWorkflowJobEvent(&github.WorkflowJobEvent{
+				Repo: &github.Repository{
+					Name:  github.Ptr("github_exporter"),
+					Owner: &github.User{Login: github.Ptr(owner)},
+				},
+				WorkflowJob: job,
+			})
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil && !errors.Is(err, ErrOutdated) {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	}
+
+	records, err := s.QueryWorkflowJobs(&Filter{Owner: owner})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	assert.Equal(t, "completed", records[0].Status)
+	assert.Equal(t, now.Add(time.Minute).Unix(), records[0].CompletedAt)
+
+	err = s.StoreWorkflowJobEvent(&github.WorkflowJobEvent{
+		Repo: &github.Repository{
+			Name:  github.Ptr("github_exporter"),
+			Owner: &github.User{Login: github.Ptr(owner)},
+		},
+		WorkflowJob: &github.WorkflowJob{
+			ID:        github.Ptr(int64(1)),
+			RunID:     github.Ptr(int64(1)),
+			Status:    github.Ptr("queued"),
+			CreatedAt: &github.Timestamp{Time: now},
+		},
+	})
+
+	assert.ErrorIs(t, err, ErrOutdated)
+}
+
+// testConcurrentDeploymentReviews stores redelivered requests and reviews of
+// the same review concurrently, the review must be stored exactly once.
+func testConcurrentDeploymentReviews(t *testing.T, s Store) {
+	owner := testGuardOwner()
+	now := time.Now().Truncate(time.Second)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+
+	for i := range 50 {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			event := &github.DeploymentReviewEvent{
+				Action:      github.Ptr("requested"),
+				Environment: github.Ptr("production"),
+				Repo: &github.Repository{
+					Name:  github.Ptr("github_exporter"),
+					Owner: &github.User{Login: github.Ptr(owner)},
+				},
+				WorkflowRun: &github.WorkflowRun{
+					ID: github.Ptr(int64(1)),
+				},
+				WorkflowJobRun: &github.WorkflowJobRun{
+					Environment: github.Ptr("production"),
+					CreatedAt:   &github.Timestamp{Time: now},
+					UpdatedAt:   &github.Timestamp{Time: now.Add(time.Minute)},
+				},
+			}
+
+			if i%2 == 1 {
+				event.Action = github.Ptr("approved")
+				event.Approver = &github.User{Login: github.Ptr("tboerger")}
+			}
+
+			errs <- s.StoreDeploymentReviewEvent(event)
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	records, err := s.GetDeploymentReviews(time.Hour)
+	require.NoError(t, err)
+
+	matched := make([]*DeploymentReview, 0)
+
+	for _, record := range records {
+		if record.Owner == owner {
+			matched = append(matched, record)
+		}
+	}
+
+	require.Len(t, matched, 1)
+	assert.Equal(t, "approved", matched[0].Status)
+	assert.Equal(t, now.Unix(), matched[0].RequestedAt)
+	assert.Equal(t, now.Add(time.Minute).Unix(), matched[0].ReviewedAt)
+}
@@ -1,28 +1,35 @@
 package store
 
 import (
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	matrixRegexp = regexp.MustCompile(`^(.*?)\s*\(([^()]*)\)$`)
 )
 
 // WorkflowRun defines the type returned by GitHub.
 type WorkflowRun struct {
-	Owner string `db:"owner"`
-	Repo  string `db:"repo"`
+	Owner string `db:"owner" json:"owner"`
+	Repo  string `db:"repo" json:"repo"`
 
-	WorkflowID int64  `db:"workflow_id"`
-	Event      string `db:"event"`
-	Name       string `db:"name"`
-	Title      string `db:"title"`
-	Status     string `db:"status"`
-	Branch     string `db:"branch"`
-	SHA        string `db:"sha"`
-	Number     int    `db:"number"`
-	Attempt    int    `db:"attempt"`
-	Actor      string `db:"actor"`
-	Identifier int64  `db:"identifier"`
-	CreatedAt  int64  `db:"created_at"`
-	UpdatedAt  int64  `db:"updated_at"`
-	StartedAt  int64  `db:"started_at"`
+	WorkflowID int64  `db:"workflow_id" json:"workflow_id"`
+	Event      string `db:"event" json:"event"`
+	Name       string `db:"name" json:"name"`
+	Title      string `db:"title" json:"title"`
+	Status     string `db:"status" json:"status"`
+	Branch     string `db:"branch" json:"branch"`
+	SHA        string `db:"sha" json:"sha"`
+	Number     int    `db:"number" json:"number"`
+	Attempt    int    `db:"attempt" json:"attempt"`
+	Actor      string `db:"actor" json:"actor"`
+	Identifier int64  `db:"identifier" json:"identifier"`
+	CreatedAt  int64  `db:"created_at" json:"created_at"`
+	UpdatedAt  int64  `db:"updated_at" json:"updated_at"`
+	StartedAt  int64  `db:"started_at" json:"started_at"`
 }
 
 // ByLabel returns values by the defined list of labels.
@@ -61,26 +68,26 @@ func (r *WorkflowRun) ByLabel(label string) string {
 
 // WorkflowJob defines the type returned by GitHub.
 type WorkflowJob struct {
-	Owner string `db:"owner"`
-	Repo  string `db:"repo"`
+	Owner string `db:"owner" json:"owner"`
+	Repo  string `db:"repo" json:"repo"`
 
-	Name            string `db:"name"`
-	Status          string `db:"status"`
-	Conclusion      string `db:"conclusion"`
-	Branch          string `db:"branch"`
-	SHA             string `db:"sha"`
-	Identifier      int64  `db:"identifier"`
-	RunID           int64  `db:"run_id"`
-	RunAttempt      int    `db:"run_attempt"`
-	CreatedAt       int64  `db:"created_at"`
-	StartedAt       int64  `db:"started_at"`
-	CompletedAt     int64  `db:"completed_at"`
-	Labels          string `db:"labels"`
-	RunnerID        int64  `db:"runner_id"`
-	RunnerName      string `db:"runner_name"`
-	RunnerGroupID   int64  `db:"runner_group_id"`
-	RunnerGroupName string `db:"runner_group_name"`
-	WorkflowName    string `db:"workflow_name"`
+	Name            string `db:"name" json:"name"`
+	Status          string `db:"status" json:"status"`
+	Conclusion      string `db:"conclusion" json:"conclusion"`
+	Branch          string `db:"branch" json:"branch"`
+	SHA             string `db:"sha" json:"sha"`
+	Identifier      int64  `db:"identifier" json:"identifier"`
+	RunID           int64  `db:"run_id" json:"run_id"`
+	RunAttempt      int    `db:"run_attempt" json:"run_attempt"`
+	CreatedAt       int64  `db:"created_at" json:"created_at"`
+	StartedAt       int64  `db:"started_at" json:"started_at"`
+	CompletedAt     int64  `db:"completed_at" json:"completed_at"`
+	Labels          string `db:"labels" json:"labels"`
+	RunnerID        int64  `db:"runner_id" json:"runner_id"`
+	RunnerName      string `db:"runner_name" json:"runner_name"`
+	RunnerGroupID   int64  `db:"runner_group_id" json:"runner_group_id"`
+	RunnerGroupName string `db:"runner_group_name" json:"runner_group_name"`
+	WorkflowName    string `db:"workflow_name" json:"workflow_name"`
 }
 
 // ByLabel returns values by the defined list of labels.
@@ -122,7 +129,145 @@ func (r *WorkflowJob) ByLabel(label string) string {
 		return r.WorkflowName
 	case "conclusion":
 		return r.Conclusion
+	case "caller_job":
+		caller, _, _ := SplitJobName(r.Name)
+		return caller
+	case "logical_job":
+		_, logical, _ := SplitJobName(r.Name)
+		return logical
+	case "matrix":
+		_, _, matrix := SplitJobName(r.Name)
+		return matrix
 	}
 
 	return ""
 }
+
+// WorkflowJobStats defines the aggregated stats of a workflow job.
+type WorkflowJobStats struct {
+	Owner string `db:"owner"`
+	Repo  string `db:"repo"`
+
+	WorkflowName string `db:"workflow_name"`
+	Name         string `db:"name"`
+	Total        int64  `db:"total"`
+	Failures     int64  `db:"failures"`
+	Duration     int64  `db:"duration"`
+	MaxDuration  int64  `db:"max_duration"`
+}
+
+// SplitJobName splits job names of matrix expansions and reusable workflow
+// calls like `build / test (linux, 1.22)` into the calling job, the logical
+// job and the matrix values.
+func SplitJobName(name string) (caller, logical, matrix string) {
+	segments := strings.Split(name, " / ")
+	stripped := make([]string, 0, len(segments))
+	matrices := make([]string, 0)
+
+	for _, segment := range segments {
+		if match := matrixRegexp.FindStringSubmatch(segment); match != nil {
+			stripped = append(stripped, match[1])
+			matrices = append(matrices, match[2])
+
+			continue
+		}
+
+		stripped = append(stripped, segment)
+	}
+
+	return strings.Join(stripped[:len(stripped)-1], " / "),
+		stripped[len(stripped)-1],
+		strings.Join(matrices, " / ")
+}
+
+// DeploymentReview defines the type returned by GitHub.
+type DeploymentReview struct {
+	Owner string `db:"owner"`
+	Repo  string `db:"repo"`
+
+	RunID       int64  `db:"run_id"`
+	Environment string `db:"environment"`
+	Status      string `db:"status"`
+	Approver    string `db:"approver"`
+	RequestedAt int64  `db:"requested_at"`
+	ReviewedAt  int64  `db:"reviewed_at"`
+}
+
+// WorkflowRunRollup defines the hourly aggregation of finished workflow runs.
+type WorkflowRunRollup struct {
+	Owner string `db:"owner"`
+	Repo  string `db:"repo"`
+
+	WorkflowID int64  `db:"workflow_id"`
+	Name       string `db:"name"`
+	Bucket     int64  `db:"bucket"`
+	Total      int64  `db:"total"`
+	Success    int64  `db:"success"`
+	Failure    int64  `db:"failure"`
+	Duration   int64  `db:"duration"`
+}
+
+// CacheEntry defines a cached API response including the validators.
+type CacheEntry struct {
+	Key          string `db:"cache_key"`
+	ETag         string `db:"etag"`
+	LastModified string `db:"last_modified"`
+	Header       string `db:"header"`
+	Body         []byte `db:"body"`
+	UpdatedAt    int64  `db:"updated_at"`
+}
+
+// Filter defines the optional conditions and the page for querying records.
+type Filter struct {
+	Owner    string
+	Repo     string
+	Workflow string
+	Branch   string
+	Status   string
+	Since    int64
+	Until    int64
+	Offset   int
+	Limit    int
+}
+
+// ParseTimestamp parses a timestamp of the filter in RFC3339, as date or as
+// unix seconds.
+func ParseTimestamp(val string) (int64, error) {
+	if parsed, err := time.Parse(time.RFC3339, val); err == nil {
+		return parsed.Unix(), nil
+	}
+
+	if parsed, err := time.Parse(time.DateOnly, val); err == nil {
+		return parsed.Unix(), nil
+	}
+
+	return strconv.ParseInt(val, 10, 64)
+}
+
+const (
+	// MigrationApplied defines a migration which has been applied.
+	MigrationApplied = "applied"
+
+	// MigrationPending defines a migration which has not been applied yet.
+	MigrationPending = "pending"
+
+	// MigrationChanged defines an applied migration with a different script.
+	MigrationChanged = "changed"
+)
+
+// Migration defines a database migration and its status.
+type Migration struct {
+	Version     float64
+	Description string
+	Script      string
+	Status      string
+	AppliedAt   int64
+}
+
+// TableStats defines the row count and the record range of a table.
+type TableStats struct {
+	Table  string `db:"name"`
+	Rows   int64  `db:"total"`
+	Oldest int64  `db:"oldest"`
+	Newest int64  `db:"newest"`
+}
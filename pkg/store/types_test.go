@@ -0,0 +1,48 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitJobName(t *testing.T) {
+	tests := []struct {
+		name    string
+		caller  string
+		logical string
+		matrix  string
+	}{
+		{"test", "", "test", ""},
+		{"test (linux, 1.22)", "", "test", "linux, 1.22"},
+		{"build / test", "build", "test", ""},
+		{"build / test (linux, 1.22)", "build", "test", "linux, 1.22"},
+		{"build (amd64) / test (linux)", "build", "test", "amd64 / linux"},
+		{"release / build / publish", "release / build", "publish", ""},
+		{"lint (go) extra", "", "lint (go) extra", ""},
+	}
+
+	for _, tt := range tests {
+		caller, logical, matrix := SplitJobName(tt.name)
+
+		assert.Equal(t, tt.caller, caller, tt.name)
+		assert.Equal(t, tt.logical, logical, tt.name)
+		assert.Equal(t, tt.matrix, matrix, tt.name)
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	for val, expected := range map[string]int64{
+		"2024-01-01":           1704067200,
+		"2024-01-01T00:00:00Z": 1704067200,
+		"1704067200":           1704067200,
+	} {
+		result, err := ParseTimestamp(val)
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+	}
+
+	_, err := ParseTimestamp("yesterday")
+	assert.Error(t, err)
+}
@@ -0,0 +1,400 @@
+package transport
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/promhippie/github_exporter/pkg/store"
+)
+
+// CacheEntry defines a cached response including the validators.
+type CacheEntry struct {
+	ETag         string
+	LastModified string
+	Header       http.Header
+	Body         []byte
+}
+
+// Cache defines the storage for cached responses.
+type Cache interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, entry *CacheEntry)
+}
+
+// CacheObserver gets called with the collector of the request and whether
+// the cached response has been used.
+type CacheObserver func(collector string, hit bool)
+
+// cacheTransport sends conditional requests for cached responses, the API
+// does not count responses with 304 against the primary rate limit.
+type cacheTransport struct {
+	base    http.RoundTripper
+	cache   Cache
+	observe CacheObserver
+}
+
+// Caching wraps the transport to revalidate cached responses based on the
+// ETag and Last-Modified headers. Only GET requests get cached.
+func Caching(base http.RoundTripper, cache Cache, observe CacheObserver) http.RoundTripper {
+	return &cacheTransport{
+		base:    base,
+		cache:   cache,
+		observe: observe,
+	}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	entry, ok := t.cache.Get(key)
+
+	if ok {
+		req = req.Clone(req.Context())
+
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+
+	if err != nil {
+		return resp, err
+	}
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		t.observe(Collector(req.Context()), true)
+		return cachedResponse(req, resp, entry), nil
+	}
+
+	t.observe(Collector(req.Context()), false)
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	etag := resp.Header.Get("ETag")
+	modified := resp.Header.Get("Last-Modified")
+
+	if etag == "" && modified == "" {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.cache.Set(key, &CacheEntry{
+		ETag:         etag,
+		LastModified: modified,
+		Header:       resp.Header.Clone(),
+		Body:         body,
+	})
+
+	return resp, nil
+}
+
+// cachedResponse builds the response from the cache, the headers of the 304
+// response like the rate limits take precedence over the cached headers.
+func cachedResponse(req *http.Request, resp *http.Response, entry *CacheEntry) *http.Response {
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	header := entry.Header.Clone()
+
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	for name, values := range resp.Header {
+		header[name] = values
+	}
+
+	header.Set("Content-Length", strconv.Itoa(len(entry.Body)))
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         resp.Proto,
+		ProtoMajor:    resp.ProtoMajor,
+		ProtoMinor:    resp.ProtoMinor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(entry.Body)),
+		ContentLength: int64(len(entry.Body)),
+		Request:       req,
+	}
+}
+
+// cacheKey builds the key of the request based on the URL and the accepted
+// media type. Credentials are not part of the key as every cached response
+// gets revalidated with the credentials of the current request, only pinned
+// credentials are part of it as their responses differ per credential.
+func cacheKey(req *http.Request) string {
+	key := req.URL.String() + "\n" + req.Header.Get("Accept")
+
+	if name := CredentialName(req.Context()); name != "" {
+		key = key + "\n" + name
+	}
+
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// memoryCache keeps a limited number of responses, the least recently used
+// responses get evicted first.
+type memoryCache struct {
+	size    int
+	mutex   sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+// NewMemoryCache initializes a cache keeping up to size responses in memory.
+func NewMemoryCache(size int) Cache {
+	return &memoryCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Get implements the Cache interface.
+func (c *memoryCache) Get(key string) (*CacheEntry, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, ok := c.entries[key]
+
+	if !ok {
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return elem.Value.(*memoryCacheItem).entry, true
+}
+
+// Set implements the Cache interface.
+func (c *memoryCache) Set(key string, entry *CacheEntry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*memoryCacheItem).entry = entry
+		c.order.MoveToFront(elem)
+
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&memoryCacheItem{
+		key:   key,
+		entry: entry,
+	})
+
+	for c.size > 0 && c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryCacheItem).key)
+	}
+}
+
+const (
+	// cacheExpireInterval defines how often the store cache deletes expired
+	// responses from the store.
+	cacheExpireInterval = 15 * time.Minute
+
+	// cacheExpireBatch defines the number of expired responses deleted within
+	// a single statement.
+	cacheExpireBatch = 1000
+)
+
+// CacheDropObserver gets called whenever a response could not be queued for
+// persisting as the queue is full.
+type CacheDropObserver func()
+
+// StoreCache keeps the responses in memory and persists them within the
+// store, that way the cache survives restarts of the exporter. Responses get
+// written by Run in the background, requests never wait for the store. Run
+// also expires responses older than the window on its own, persisted
+// responses beyond the window are never served.
+type StoreCache struct {
+	memory  Cache
+	db      store.Store
+	logger  *slog.Logger
+	window  time.Duration
+	queue   chan *store.CacheEntry
+	dropped CacheDropObserver
+	expired time.Time
+}
+
+// NewStoreCache initializes a cache backed by the memory cache and the store,
+// up to size responses get queued until Run persists them.
+func NewStoreCache(memory Cache, db store.Store, window time.Duration, size int, dropped CacheDropObserver, logger *slog.Logger) *StoreCache {
+	return &StoreCache{
+		memory:  memory,
+		db:      db,
+		logger:  logger.With("cache", "store"),
+		window:  window,
+		queue:   make(chan *store.CacheEntry, size),
+		dropped: dropped,
+		expired: time.Now(),
+	}
+}
+
+// Get implements the Cache interface.
+func (c *StoreCache) Get(key string) (*CacheEntry, bool) {
+	if entry, ok := c.memory.Get(key); ok {
+		return entry, true
+	}
+
+	record, err := c.db.GetCacheEntry(key)
+
+	if err != nil {
+		if !errors.Is(err, store.ErrNotFound) {
+			c.logger.Error("Failed to fetch cache entry",
+				"err", err,
+			)
+		}
+
+		return nil, false
+	}
+
+	if c.window > 0 && record.UpdatedAt < time.Now().Add(-c.window).Unix() {
+		return nil, false
+	}
+
+	entry := &CacheEntry{
+		ETag:         record.ETag,
+		LastModified: record.LastModified,
+		Body:         record.Body,
+	}
+
+	if err := json.Unmarshal([]byte(record.Header), &entry.Header); err != nil {
+		c.logger.Error("Failed to decode cache entry",
+			"err", err,
+		)
+
+		return nil, false
+	}
+
+	c.memory.Set(key, entry)
+	return entry, true
+}
+
+// Set implements the Cache interface. The response gets dropped from the
+// store if the queue is full, it's still cached in memory.
+func (c *StoreCache) Set(key string, entry *CacheEntry) {
+	c.memory.Set(key, entry)
+
+	header, err := json.Marshal(entry.Header)
+
+	if err != nil {
+		c.logger.Error("Failed to encode cache entry",
+			"err", err,
+		)
+
+		return
+	}
+
+	select {
+	case c.queue <- &store.CacheEntry{
+		Key:          key,
+		ETag:         entry.ETag,
+		LastModified: entry.LastModified,
+		Header:       string(header),
+		Body:         entry.Body,
+		UpdatedAt:    time.Now().Unix(),
+	}:
+	default:
+		c.logger.Debug("Dropped cache entry, queue is full")
+
+		if c.dropped != nil {
+			c.dropped()
+		}
+	}
+}
+
+// Run persists the queued responses until the context gets canceled, the
+// remaining queued responses get persisted before it returns.
+func (c *StoreCache) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			c.drain()
+			return nil
+		case record := <-c.queue:
+			c.write(record)
+		}
+	}
+}
+
+// drain persists all responses which are still queued.
+func (c *StoreCache) drain() {
+	for {
+		select {
+		case record := <-c.queue:
+			c.write(record)
+		default:
+			return
+		}
+	}
+}
+
+// write persists the response and expires outdated responses.
+func (c *StoreCache) write(record *store.CacheEntry) {
+	if err := c.db.StoreCacheEntry(record); err != nil {
+		c.logger.Error("Failed to store cache entry",
+			"err", err,
+		)
+	}
+
+	if c.window > 0 && time.Since(c.expired) >= cacheExpireInterval {
+		c.expire()
+	}
+}
+
+// expire deletes the responses older than the window from the store.
+func (c *StoreCache) expire() {
+	c.expired = time.Now()
+	deleted, err := c.db.PruneCacheEntries(c.window, cacheExpireBatch)
+
+	if err != nil {
+		c.logger.Error("Failed to expire cache entries",
+			"err", err,
+		)
+
+		return
+	}
+
+	c.logger.Debug("Expired cache entries",
+		"deleted", deleted,
+	)
+}
@@ -0,0 +1,159 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/promhippie/github_exporter/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCacheRequest(t *testing.T, client *http.Client, url string) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(
+		WithCollector(context.Background(), "org"),
+		http.MethodGet,
+		url,
+		nil,
+	)
+
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, string(body)
+}
+
+func TestCaching(t *testing.T) {
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.Header().Set("X-RateLimit-Remaining", "4999")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = io.WriteString(w, `{"login":"promhippie"}`)
+	}))
+
+	defer server.Close()
+
+	results := make(map[bool]int)
+	client := &http.Client{
+		Transport: Caching(http.DefaultTransport, NewMemoryCache(10), func(collector string, hit bool) {
+			assert.Equal(t, "org", collector)
+			results[hit]++
+		}),
+	}
+
+	for range 3 {
+		status, body := testCacheRequest(t, client, server.URL+"/orgs/promhippie")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, `{"login":"promhippie"}`, body)
+	}
+
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, map[bool]int{false: 1, true: 2}, results)
+}
+
+func TestMemoryCacheEviction(t *testing.T) {
+	cache := NewMemoryCache(2)
+
+	cache.Set("a", &CacheEntry{ETag: "a"})
+	cache.Set("b", &CacheEntry{ETag: "b"})
+
+	_, ok := cache.Get("a")
+	require.True(t, ok)
+
+	cache.Set("c", &CacheEntry{ETag: "c"})
+
+	_, ok = cache.Get("b")
+	assert.False(t, ok)
+
+	_, ok = cache.Get("a")
+	assert.True(t, ok)
+}
+
+func TestStoreCache(t *testing.T) {
+	db, err := store.New("memory://", slog.Default())
+	require.NoError(t, err)
+
+	cache := NewStoreCache(NewMemoryCache(10), db, time.Hour, 10, nil, slog.Default())
+
+	cache.Set("key", &CacheEntry{
+		ETag:   `"v1"`,
+		Header: http.Header{"Content-Type": []string{"application/json"}},
+		Body:   []byte(`{}`),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.NoError(t, cache.Run(ctx))
+	assert.Empty(t, cache.queue, "expected queued entries to get drained")
+
+	entry, ok := NewStoreCache(NewMemoryCache(10), db, time.Hour, 10, nil, slog.Default()).Get("key")
+	require.True(t, ok)
+	assert.Equal(t, `"v1"`, entry.ETag)
+	assert.Equal(t, "application/json", entry.Header.Get("Content-Type"))
+	assert.Equal(t, []byte(`{}`), entry.Body)
+
+	_, ok = NewStoreCache(NewMemoryCache(10), db, time.Hour, 10, nil, slog.Default()).Get("missing")
+	assert.False(t, ok)
+}
+
+func TestStoreCacheExpired(t *testing.T) {
+	db, err := store.New("memory://", slog.Default())
+	require.NoError(t, err)
+
+	require.NoError(t, db.StoreCacheEntry(&store.CacheEntry{
+		Key:       "key",
+		ETag:      `"v1"`,
+		Header:    "{}",
+		UpdatedAt: time.Now().Add(-2 * time.Hour).Unix(),
+	}))
+
+	_, ok := NewStoreCache(NewMemoryCache(10), db, time.Hour, 10, nil, slog.Default()).Get("key")
+	assert.False(t, ok)
+}
+
+func TestStoreCacheDropped(t *testing.T) {
+	db, err := store.New("memory://", slog.Default())
+	require.NoError(t, err)
+
+	dropped := 0
+
+	cache := &StoreCache{
+		memory:  NewMemoryCache(10),
+		db:      db,
+		logger:  slog.Default(),
+		queue:   make(chan *store.CacheEntry, 1),
+		dropped: func() { dropped++ },
+	}
+
+	cache.Set("first", &CacheEntry{ETag: `"v1"`})
+	cache.Set("second", &CacheEntry{ETag: `"v2"`})
+
+	assert.Equal(t, 1, dropped)
+	assert.Len(t, cache.queue, 1)
+
+	_, ok := cache.Get("second")
+	assert.True(t, ok, "expected dropped entries to stay in memory")
+}
@@ -0,0 +1,63 @@
+package transport
+
+import (
+	"context"
+)
+
+const (
+	// UnknownCollector defines the collector name of untagged requests.
+	UnknownCollector = "unknown"
+)
+
+type collectorKey struct{}
+
+type ownerKey struct{}
+
+type credentialKey struct{}
+
+// WithCollector tags the context with the name of the collector, that way
+// the transports are able to attribute requests to a collector.
+func WithCollector(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, collectorKey{}, name)
+}
+
+// Collector returns the collector name the context has been tagged with.
+func Collector(ctx context.Context) string {
+	if name, ok := ctx.Value(collectorKey{}).(string); ok && name != "" {
+		return name
+	}
+
+	return UnknownCollector
+}
+
+// WithOwner tags the context with the owner of the requested resources, that
+// way requests without the owner in the path like GraphQL queries can still
+// be routed to the matching app installation.
+func WithOwner(ctx context.Context, owner string) context.Context {
+	return context.WithValue(ctx, ownerKey{}, owner)
+}
+
+// Owner returns the owner the context has been tagged with.
+func Owner(ctx context.Context) string {
+	if owner, ok := ctx.Value(ownerKey{}).(string); ok {
+		return owner
+	}
+
+	return ""
+}
+
+// WithCredential tags the context with the name of a pooled credential, that
+// way the request gets sent with this credential instead of the one with the
+// most remaining requests.
+func WithCredential(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, credentialKey{}, name)
+}
+
+// CredentialName returns the credential name the context has been tagged with.
+func CredentialName(ctx context.Context) string {
+	if name, ok := ctx.Value(credentialKey{}).(string); ok {
+		return name
+	}
+
+	return ""
+}
@@ -0,0 +1,238 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bradleyfalzon/ghinstallation/v2"
+	"github.com/google/go-github/v90/github"
+)
+
+const (
+	// installationRetry defines the delay to retry a failed refresh.
+	installationRetry = time.Minute
+)
+
+var (
+	// ErrNoInstallation gets returned if the app is not installed anywhere or
+	// not installed for the owner of the requested resource.
+	ErrNoInstallation = errors.New("no app installation available")
+)
+
+// InstallationObserver gets called after every refresh of the installations.
+type InstallationObserver func(count int, err error)
+
+// Installations routes requests to the installation of the GitHub app which
+// belongs to the owner of the requested resource. The installations get
+// discovered and refreshed in the background by Run, requests never wait for
+// a refresh.
+type Installations struct {
+	apps     *ghinstallation.AppsTransport
+	client   *github.Client
+	fallback int64
+	interval time.Duration
+	logger   *slog.Logger
+	observe  InstallationObserver
+
+	refresh    sync.Mutex
+	mutex      sync.RWMutex
+	next       time.Time
+	transports map[int64]*ghinstallation.Transport
+	owners     map[string]int64
+	ids        []int64
+}
+
+// NewInstallations prepares the routing, the client has to be authenticated
+// as the app to list the installations. Requests which can't be assigned to
+// an owner are sent through the fallback installation or the oldest one,
+// requests for an owner without installation fail with ErrNoInstallation.
+func NewInstallations(apps *ghinstallation.AppsTransport, client *github.Client, fallback int64, interval time.Duration, logger *slog.Logger, observe InstallationObserver) *Installations {
+	return &Installations{
+		apps:       apps,
+		client:     client,
+		fallback:   fallback,
+		interval:   interval,
+		logger:     logger.With("transport", "installations"),
+		observe:    observe,
+		transports: make(map[int64]*ghinstallation.Transport),
+		owners:     make(map[string]int64),
+	}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (i *Installations) RoundTrip(req *http.Request) (*http.Response, error) {
+	tr, err := i.route(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tr.RoundTrip(req)
+}
+
+// Run refreshes the installations on the interval until the context gets
+// canceled, failed refreshes get retried earlier.
+func (i *Installations) Run(ctx context.Context) error {
+	for {
+		i.refresh.Lock()
+		wait := time.Until(i.next)
+		i.refresh.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+			i.Refresh(ctx, false)
+		}
+	}
+}
+
+// Refresh lists the installations of the app if the refresh interval passed
+// or if it's forced. Transports of known installations are kept to reuse
+// their access tokens.
+func (i *Installations) Refresh(ctx context.Context, force bool) {
+	i.refresh.Lock()
+	defer i.refresh.Unlock()
+
+	if !force && time.Now().Before(i.next) {
+		return
+	}
+
+	installations, err := i.list(ctx)
+
+	if err != nil {
+		i.logger.Error("Failed to refresh app installations",
+			"err", err,
+		)
+
+		i.next = time.Now().Add(min(i.interval, installationRetry))
+		i.observe(len(i.ids), err)
+
+		return
+	}
+
+	transports := make(map[int64]*ghinstallation.Transport, len(installations))
+	owners := make(map[string]int64, len(installations))
+	ids := make([]int64, 0, len(installations))
+
+	i.mutex.RLock()
+
+	for _, installation := range installations {
+		id := installation.GetID()
+
+		if tr, ok := i.transports[id]; ok {
+			transports[id] = tr
+		} else {
+			transports[id] = ghinstallation.NewFromAppsTransport(i.apps, id)
+
+			i.logger.Info("Discovered app installation",
+				"id", id,
+				"account", installation.GetAccount().GetLogin(),
+			)
+		}
+
+		owners[strings.ToLower(installation.GetAccount().GetLogin())] = id
+		ids = append(ids, id)
+	}
+
+	i.mutex.RUnlock()
+	slices.Sort(ids)
+
+	i.mutex.Lock()
+	i.transports = transports
+	i.owners = owners
+	i.ids = ids
+	i.mutex.Unlock()
+
+	i.next = time.Now().Add(i.interval)
+	i.observe(len(ids), nil)
+}
+
+// list fetches all installations of the app.
+func (i *Installations) list(ctx context.Context) ([]*github.Installation, error) {
+	opts := &github.ListOptions{
+		PerPage: 100,
+	}
+
+	result := make([]*github.Installation, 0)
+
+	for {
+		installations, resp, err := i.client.Apps.ListInstallations(ctx, opts)
+
+		if err != nil {
+			return nil, err
+		}
+
+		_ = resp.Body.Close()
+		result = append(result, installations...)
+
+		if resp.NextPage == 0 {
+			return result, nil
+		}
+
+		opts.Page = resp.NextPage
+	}
+}
+
+// route selects the installation transport for the owner of the request, an
+// owner tagged on the context takes precedence over the requested path. Only
+// requests without owner use the fallback, otherwise another installation
+// would answer for resources it has no access to.
+func (i *Installations) route(req *http.Request) (http.RoundTripper, error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	owner := strings.ToLower(Owner(req.Context()))
+
+	if owner == "" {
+		owner = installationOwner(req)
+	}
+
+	if owner != "" {
+		if id, ok := i.owners[owner]; ok {
+			return i.transports[id], nil
+		}
+
+		return nil, ErrNoInstallation
+	}
+
+	if tr, ok := i.transports[i.fallback]; ok {
+		return tr, nil
+	}
+
+	if len(i.ids) > 0 {
+		return i.transports[i.ids[0]], nil
+	}
+
+	return nil, ErrNoInstallation
+}
+
+// installationOwner detects the owner of the requested resource based on the
+// path or the search query.
+func installationOwner(req *http.Request) string {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(req.URL.Path, "/api/v3"), "/"), "/")
+
+	if len(parts) >= 2 {
+		switch parts[0] {
+		case "orgs", "repos", "users", "enterprises":
+			return strings.ToLower(parts[1])
+		case "search":
+			for _, term := range strings.Fields(req.URL.Query().Get("q")) {
+				for _, prefix := range []string{"user:", "org:", "repo:"} {
+					if owner, ok := strings.CutPrefix(term, prefix); ok {
+						owner, _, _ = strings.Cut(owner, "/")
+						return strings.ToLower(owner)
+					}
+				}
+			}
+		}
+	}
+
+	return ""
+}
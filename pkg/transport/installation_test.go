@@ -0,0 +1,181 @@
+package transport
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bradleyfalzon/ghinstallation/v2"
+	"github.com/google/go-github/v90/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallations(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	privateKey := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+
+	var (
+		mutex    sync.Mutex
+		accounts = []string{"Promhippie"}
+		tokens   = make(map[string]string)
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		switch {
+		case r.URL.Path == "/app/installations":
+			result := make([]*github.Installation, 0, len(accounts))
+
+			for idx, account := range accounts {
+				result = append(result, &github.Installation{
+					ID:      github.Ptr(int64(idx + 1)),
+					Account: &github.User{Login: github.Ptr(account)},
+				})
+			}
+
+			_ = json.NewEncoder(w).Encode(result)
+		case strings.HasPrefix(r.URL.Path, "/app/installations/"):
+			id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/app/installations/"), "/access_tokens")
+
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(&github.InstallationToken{
+				Token:     github.Ptr("token-" + id),
+				ExpiresAt: &github.Timestamp{Time: time.Now().Add(time.Hour)},
+			})
+		default:
+			tokens[r.URL.Path] = r.Header.Get("Authorization")
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+
+	defer server.Close()
+
+	apps, err := ghinstallation.NewAppsTransport(http.DefaultTransport, 1, privateKey)
+	require.NoError(t, err)
+	apps.BaseURL = server.URL
+
+	client, err := github.NewClient(
+		github.WithTransport(apps),
+		github.WithURLs(&server.URL, &server.URL),
+	)
+	require.NoError(t, err)
+
+	var count int
+
+	installations := NewInstallations(apps, client, 0, time.Hour, slog.New(slog.DiscardHandler), func(c int, err error) {
+		assert.NoError(t, err)
+		count = c
+	})
+
+	installations.Refresh(context.Background(), true)
+	assert.Equal(t, 1, count)
+
+	mutex.Lock()
+	accounts = append(accounts, "webhippie")
+	mutex.Unlock()
+
+	installations.Refresh(context.Background(), false)
+	assert.Equal(t, 1, count)
+
+	installations.Refresh(context.Background(), true)
+	assert.Equal(t, 2, count)
+
+	httpClient := &http.Client{
+		Transport: installations,
+	}
+
+	for _, path := range []string{"/orgs/promhippie", "/repos/webhippie/example", "/meta"} {
+		resp, err := httpClient.Get(server.URL + path)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+
+	req, err := http.NewRequestWithContext(WithOwner(context.Background(), "Webhippie"), http.MethodPost, server.URL+"/graphql", nil)
+	require.NoError(t, err)
+
+	resp, err := httpClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, "token token-1", tokens["/orgs/promhippie"])
+	assert.Equal(t, "token token-2", tokens["/graphql"])
+	assert.Equal(t, "token token-2", tokens["/repos/webhippie/example"])
+	assert.Equal(t, "token token-1", tokens["/meta"])
+
+	_, err = httpClient.Get(server.URL + "/orgs/unknown")
+	assert.ErrorIs(t, err, ErrNoInstallation)
+
+	mutex.Lock()
+	accounts = append(accounts, "unknown")
+	mutex.Unlock()
+
+	installations.interval = 10 * time.Millisecond
+	installations.next = time.Now()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() {
+		done <- installations.Run(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		_, err := installations.route(httptest.NewRequest(http.MethodGet, "/orgs/unknown", nil))
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestInstallationsEmpty(t *testing.T) {
+	installations := NewInstallations(nil, nil, 0, time.Hour, slog.New(slog.DiscardHandler), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/orgs/promhippie", nil)
+	_, err := installations.route(req)
+
+	assert.ErrorIs(t, err, ErrNoInstallation)
+}
+
+func TestInstallationOwner(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"/orgs/Promhippie/repos", "promhippie"},
+		{"/api/v3/orgs/promhippie/actions/runners", "promhippie"},
+		{"/repos/webhippie/example/actions/runs", "webhippie"},
+		{"/users/tboerger", "tboerger"},
+		{"/enterprises/example/actions/runners", "example"},
+		{"/search/repositories?q=" + "archived:false+org:promhippie", "promhippie"},
+		{"/search/issues?q=" + "repo:webhippie/example+is:open", "webhippie"},
+		{"/search/repositories?q=" + "stars:>10", ""},
+		{"/meta", ""},
+		{"/graphql", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			assert.Equal(t, tt.want, installationOwner(req))
+		})
+	}
+}
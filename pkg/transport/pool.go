@@ -0,0 +1,285 @@
+package transport
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+)
+
+const (
+	// poolBlockFallback defines how long a rate limited credential gets taken
+	// out of rotation if the response does not define a reset time.
+	poolBlockFallback = time.Minute
+)
+
+// Credential defines a single credential of the pool, the transport has to
+// authenticate the requests on its own.
+type Credential struct {
+	Name      string
+	Transport http.RoundTripper
+}
+
+// CredentialObserver gets called with the credential of every response, the
+// rate is nil if the response does not contain rate limit headers.
+type CredentialObserver func(credential, resource string, rate *github.Rate, limited bool)
+
+// poolState defines the last known rate limit of a credential and resource.
+type poolState struct {
+	remaining int
+	reset     time.Time
+	blocked   time.Time
+}
+
+// poolTransport distributes requests across multiple credentials.
+type poolTransport struct {
+	credentials []*Credential
+	observe     CredentialObserver
+	mutex       sync.Mutex
+	states      []map[string]*poolState
+}
+
+// Pool distributes the requests to the credential with the most remaining
+// requests. Rate limited credentials are taken out of rotation until their
+// reset time, replayable requests get retried with the next credential.
+// Requests with a context tagged by WithCredential always use that credential.
+func Pool(credentials []*Credential, observe CredentialObserver) http.RoundTripper {
+	states := make([]map[string]*poolState, len(credentials))
+
+	for i := range states {
+		states[i] = make(map[string]*poolState)
+	}
+
+	return &poolTransport{
+		credentials: credentials,
+		observe:     observe,
+		states:      states,
+	}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *poolTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resource := poolResource(req)
+	pinned := t.pinned(CredentialName(req.Context()))
+	tried := make(map[int]bool, len(t.credentials))
+
+	for {
+		idx := pinned
+
+		if idx < 0 {
+			idx = t.pick(resource, tried)
+		}
+
+		tried[idx] = true
+
+		credential := t.credentials[idx]
+		resp, err := credential.Transport.RoundTrip(req)
+
+		if err != nil {
+			return resp, err
+		}
+
+		rate, ok := ParseRate(resp.Header)
+		limited := poolLimited(resp)
+
+		t.update(idx, resource, resp.Header, rate, ok, limited)
+
+		if ok {
+			t.observe(credential.Name, resource, &rate, limited)
+		} else {
+			t.observe(credential.Name, resource, nil, limited)
+		}
+
+		if pinned >= 0 || !limited || len(tried) == len(t.credentials) || !t.available(resource, tried) {
+			return resp, nil
+		}
+
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, nil
+			}
+
+			body, err := req.GetBody()
+
+			if err != nil {
+				return resp, nil
+			}
+
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+}
+
+// pick selects the credential with the most remaining requests, credentials
+// with an unknown budget are preferred. If all credentials are rate limited
+// the one getting available first gets used.
+func (t *poolTransport) pick(resource string, tried map[int]bool) int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	result, best := -1, -1
+	fallback := -1
+
+	for i := range t.credentials {
+		if tried[i] {
+			continue
+		}
+
+		state := t.state(i, resource)
+
+		if state.blocked.After(now) {
+			if fallback < 0 || state.blocked.Before(t.state(fallback, resource).blocked) {
+				fallback = i
+			}
+
+			continue
+		}
+
+		remaining := state.remaining
+
+		if state.reset.Before(now) {
+			remaining = math.MaxInt
+		}
+
+		if remaining > best {
+			result, best = i, remaining
+		}
+	}
+
+	if result < 0 {
+		return fallback
+	}
+
+	return result
+}
+
+// pinned returns the index of the named credential or -1 if the name does
+// not match any credential.
+func (t *poolTransport) pinned(name string) int {
+	if name == "" {
+		return -1
+	}
+
+	for i, credential := range t.credentials {
+		if credential.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// available checks if any untried credential is not rate limited.
+func (t *poolTransport) available(resource string, tried map[int]bool) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+
+	for i := range t.credentials {
+		if !tried[i] && !t.state(i, resource).blocked.After(now) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// update stores the rate limit reported by the response.
+func (t *poolTransport) update(idx int, resource string, header http.Header, rate github.Rate, ok, limited bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	state := t.state(idx, resource)
+
+	if ok {
+		state.remaining = rate.Remaining
+		state.reset = rate.Reset.Time
+	}
+
+	if limited {
+		state.blocked = poolBlocked(header, state.reset)
+	}
+}
+
+// state returns the state of the credential and resource, the mutex has to
+// be held by the caller.
+func (t *poolTransport) state(idx int, resource string) *poolState {
+	state, ok := t.states[idx][resource]
+
+	if !ok {
+		state = &poolState{}
+		t.states[idx][resource] = state
+	}
+
+	return state
+}
+
+// poolLimited checks if the response has been rejected by a rate limit.
+func poolLimited(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+
+	return resp.Header.Get(headerRateRemaining) == "0" || resp.Header.Get("Retry-After") != ""
+}
+
+// poolBlocked calculates until when a rate limited credential gets skipped.
+func poolBlocked(header http.Header, reset time.Time) time.Time {
+	now := time.Now()
+
+	if delay, ok := RetryAfter(header); ok {
+		return now.Add(delay)
+	}
+
+	if reset.After(now) {
+		return reset
+	}
+
+	return now.Add(poolBlockFallback)
+}
+
+// poolResource detects the rate limit resource of the request.
+func poolResource(req *http.Request) string {
+	path := strings.TrimPrefix(req.URL.Path, "/api/v3")
+
+	switch {
+	case strings.HasSuffix(path, "/graphql"):
+		return "graphql"
+	case strings.HasPrefix(path, "/search/"):
+		return "search"
+	default:
+		return "core"
+	}
+}
+
+// tokenTransport authenticates requests with a static token.
+type tokenTransport struct {
+	base  http.RoundTripper
+	token string
+}
+
+// Token wraps the transport to authenticate requests with the token.
+func Token(base http.RoundTripper, token string) http.RoundTripper {
+	return &tokenTransport{
+		base:  base,
+		token: token,
+	}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+
+	return t.base.RoundTrip(req)
+}
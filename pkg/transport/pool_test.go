@@ -0,0 +1,108 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool(t *testing.T) {
+	remaining := map[string]int{
+		"Bearer first":  2,
+		"Bearer second": 100,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		reset := time.Now().Add(time.Hour).Unix()
+
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+
+		if remaining[auth] == 0 {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		remaining[auth]--
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining[auth]))
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	defer server.Close()
+
+	requests := make(map[string]int)
+	limited := make(map[string]int)
+
+	client := &http.Client{
+		Transport: Pool([]*Credential{
+			{Name: "first", Transport: Token(http.DefaultTransport, "first")},
+			{Name: "second", Transport: Token(http.DefaultTransport, "second")},
+		}, func(credential, resource string, _ *github.Rate, rateLimited bool) {
+			assert.Equal(t, "core", resource)
+			requests[credential]++
+
+			if rateLimited {
+				limited[credential]++
+			}
+		}),
+	}
+
+	for range 4 {
+		resp, err := client.Get(server.URL + "/orgs/promhippie")
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	// Both credentials are unknown initially, afterwards the second one has
+	// the bigger budget and serves all following requests.
+	assert.Equal(t, 1, requests["first"])
+	assert.Equal(t, 3, requests["second"])
+	assert.Equal(t, 0, limited["first"])
+
+	remaining["Bearer second"] = 0
+
+	resp, err := client.Get(server.URL + "/orgs/promhippie")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, limited["second"])
+	assert.Equal(t, 2, requests["first"])
+
+	// Pinned requests never rotate, even if the credential is rate limited.
+	req, err := http.NewRequestWithContext(WithCredential(context.Background(), "second"), http.MethodGet, server.URL+"/rate_limit", nil)
+	require.NoError(t, err)
+
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, 2, limited["second"])
+	assert.Equal(t, 2, requests["first"])
+}
+
+func TestPoolResource(t *testing.T) {
+	for path, resource := range map[string]string{
+		"/search/repositories": "search",
+		"/api/v3/search/code":  "search",
+		"/graphql":             "graphql",
+		"/api/graphql":         "graphql",
+		"/orgs/promhippie":     "core",
+	} {
+		req, err := http.NewRequest(http.MethodGet, "https://api.github.com"+path, nil)
+		require.NoError(t, err)
+		assert.Equal(t, resource, poolResource(req), path)
+	}
+}
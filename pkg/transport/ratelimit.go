@@ -0,0 +1,79 @@
+package transport
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+)
+
+const (
+	headerRateLimit     = "X-RateLimit-Limit"
+	headerRateRemaining = "X-RateLimit-Remaining"
+	headerRateUsed      = "X-RateLimit-Used"
+	headerRateReset     = "X-RateLimit-Reset"
+	headerRateResource  = "X-RateLimit-Resource"
+)
+
+// RateObserver gets called with the collector of the request and the rate
+// limit reported by the response headers.
+type RateObserver func(collector string, rate github.Rate)
+
+// rateLimitTransport observes the rate limit headers of every response.
+type rateLimitTransport struct {
+	base    http.RoundTripper
+	observe RateObserver
+}
+
+// RateLimit wraps the transport to report the rate limit headers of every
+// response to the observer.
+func RateLimit(base http.RoundTripper, observe RateObserver) http.RoundTripper {
+	return &rateLimitTransport{
+		base:    base,
+		observe: observe,
+	}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+
+	if err != nil {
+		return resp, err
+	}
+
+	if rate, ok := ParseRate(resp.Header); ok {
+		t.observe(Collector(req.Context()), rate)
+	}
+
+	return resp, nil
+}
+
+// ParseRate parses the rate limit headers, responses without a limit header
+// are not reported.
+func ParseRate(header http.Header) (github.Rate, bool) {
+	rate := github.Rate{
+		Resource: header.Get(headerRateResource),
+	}
+
+	limit, err := strconv.Atoi(header.Get(headerRateLimit))
+
+	if err != nil {
+		return rate, false
+	}
+
+	rate.Limit = limit
+	rate.Remaining, _ = strconv.Atoi(header.Get(headerRateRemaining))
+	rate.Used, _ = strconv.Atoi(header.Get(headerRateUsed))
+
+	if reset, err := strconv.ParseInt(header.Get(headerRateReset), 10, 64); err == nil {
+		rate.Reset = github.Timestamp{Time: time.Unix(reset, 0)}
+	}
+
+	if rate.Resource == "" {
+		rate.Resource = "core"
+	}
+
+	return rate, true
+}
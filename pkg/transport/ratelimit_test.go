@@ -0,0 +1,62 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/search" {
+			w.Header().Set("X-RateLimit-Limit", "30")
+			w.Header().Set("X-RateLimit-Remaining", "29")
+			w.Header().Set("X-RateLimit-Used", "1")
+			w.Header().Set("X-RateLimit-Reset", "1700000000")
+			w.Header().Set("X-RateLimit-Resource", "search")
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	defer server.Close()
+
+	observed := make(map[string]github.Rate)
+	client := &http.Client{
+		Transport: RateLimit(http.DefaultTransport, func(collector string, rate github.Rate) {
+			observed[collector] = rate
+		}),
+	}
+
+	for _, path := range []string{"/search", "/plain"} {
+		req, err := http.NewRequestWithContext(
+			WithCollector(context.Background(), "repo"),
+			http.MethodGet,
+			server.URL+path,
+			nil,
+		)
+
+		require.NoError(t, err)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+
+	require.Len(t, observed, 1)
+	assert.Equal(t, "search", observed["repo"].Resource)
+	assert.Equal(t, 30, observed["repo"].Limit)
+	assert.Equal(t, 29, observed["repo"].Remaining)
+	assert.Equal(t, 1, observed["repo"].Used)
+	assert.Equal(t, int64(1700000000), observed["repo"].Reset.Unix())
+}
+
+func TestCollector(t *testing.T) {
+	assert.Equal(t, UnknownCollector, Collector(context.Background()))
+	assert.Equal(t, "org", Collector(WithCollector(context.Background(), "org")))
+}
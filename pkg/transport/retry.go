@@ -0,0 +1,196 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// retryMaxDelay defines the longest delay before a retry if the request
+	// context does not define a deadline.
+	retryMaxDelay = time.Minute
+
+	// RetryServerError defines the reason for retries of 5xx responses.
+	RetryServerError = "server_error"
+
+	// RetryRateLimit defines the reason for retries of rate limited responses.
+	RetryRateLimit = "rate_limit"
+
+	// RetryNetwork defines the reason for retries of failed connections.
+	RetryNetwork = "network"
+)
+
+// RetryObserver gets called with the collector of the request and the reason
+// before every retry.
+type RetryObserver func(collector, reason string)
+
+// retryTransport retries transient errors and rate limited responses.
+type retryTransport struct {
+	base     http.RoundTripper
+	attempts int
+	backoff  time.Duration
+	observe  RetryObserver
+}
+
+// Retry wraps the transport to retry server errors, failed connections and
+// rate limited responses up to the defined number of attempts. Rate limited
+// responses are retried after Retry-After or X-RateLimit-Reset, everything
+// else with an exponential backoff. Retries which would exceed the deadline
+// of the request context are skipped.
+func Retry(base http.RoundTripper, attempts int, backoff time.Duration, observe RetryObserver) http.RoundTripper {
+	return &retryTransport{
+		base:     base,
+		attempts: attempts,
+		backoff:  backoff,
+		observe:  observe,
+	}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !retryable(req) {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+
+		if attempt >= t.attempts {
+			return resp, err
+		}
+
+		reason, delay := t.delay(ctx, attempt, resp, err)
+
+		if reason == "" || !retryDeadline(ctx, delay) {
+			return resp, err
+		}
+
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, err
+			}
+
+			body, berr := req.GetBody()
+
+			if berr != nil {
+				return nil, berr
+			}
+
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		t.observe(Collector(ctx), reason)
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// delay detects if the response should be retried and calculates the delay
+// before the next attempt, the reason is empty if it should not be retried.
+func (t *retryTransport) delay(ctx context.Context, attempt int, resp *http.Response, err error) (string, time.Duration) {
+	if err != nil {
+		if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return "", 0
+		}
+
+		return RetryNetwork, t.exponential(attempt)
+	}
+
+	switch {
+	case poolLimited(resp):
+		if delay, ok := RetryAfter(resp.Header); ok {
+			return RetryRateLimit, delay
+		}
+
+		if reset, err := strconv.ParseInt(resp.Header.Get(headerRateReset), 10, 64); err == nil {
+			return RetryRateLimit, max(time.Until(time.Unix(reset, 0)), 0) + time.Second
+		}
+
+		return RetryRateLimit, t.exponential(attempt)
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return RetryRateLimit, t.exponential(attempt)
+	case resp.StatusCode >= http.StatusInternalServerError && resp.StatusCode != http.StatusNotImplemented:
+		if delay, ok := RetryAfter(resp.Header); ok {
+			return RetryServerError, delay
+		}
+
+		return RetryServerError, t.exponential(attempt)
+	}
+
+	return "", 0
+}
+
+// exponential calculates the backoff for the attempt including some jitter.
+func (t *retryTransport) exponential(attempt int) time.Duration {
+	delay := min(t.backoff<<min(attempt, 16), retryMaxDelay)
+
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay + rand.N(delay/4+1)
+}
+
+// RetryAfter parses the Retry-After header, which could be defined in seconds
+// or as a HTTP date.
+func RetryAfter(header http.Header) (time.Duration, bool) {
+	val := header.Get("Retry-After")
+
+	if val == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(val); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+
+	if date, err := http.ParseTime(val); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
+
+// retryable checks if the request is safe to retry, beside read requests only
+// GraphQL queries with a replayable body are retried.
+func retryable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		return strings.HasSuffix(req.URL.Path, "/graphql") &&
+			(req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+	}
+
+	return false
+}
+
+// retryDeadline checks if the delay fits into the deadline of the context.
+func retryDeadline(ctx context.Context, delay time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok {
+		return time.Now().Add(delay).Before(deadline)
+	}
+
+	return delay <= retryMaxDelay
+}
@@ -0,0 +1,190 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		statuses []int
+		header   http.Header
+		want     int
+		requests int32
+		reasons  []string
+	}{
+		{
+			name:     "server error",
+			method:   http.MethodGet,
+			statuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK},
+			want:     http.StatusOK,
+			requests: 3,
+			reasons:  []string{RetryServerError, RetryServerError},
+		},
+		{
+			name:     "secondary rate limit",
+			method:   http.MethodGet,
+			statuses: []int{http.StatusForbidden, http.StatusOK},
+			header:   http.Header{"Retry-After": []string{"0"}},
+			want:     http.StatusOK,
+			requests: 2,
+			reasons:  []string{RetryRateLimit},
+		},
+		{
+			name:     "exhausted attempts",
+			method:   http.MethodGet,
+			statuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway},
+			want:     http.StatusBadGateway,
+			requests: 3,
+			reasons:  []string{RetryServerError, RetryServerError},
+		},
+		{
+			name:     "client error",
+			method:   http.MethodGet,
+			statuses: []int{http.StatusNotFound, http.StatusOK},
+			want:     http.StatusNotFound,
+			requests: 1,
+		},
+		{
+			name:     "unsafe method",
+			method:   http.MethodDelete,
+			statuses: []int{http.StatusBadGateway, http.StatusOK},
+			want:     http.StatusBadGateway,
+			requests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				idx := requests.Add(1) - 1
+
+				for key, values := range tt.header {
+					w.Header()[key] = values
+				}
+
+				w.WriteHeader(tt.statuses[idx])
+			}))
+
+			defer server.Close()
+
+			reasons := make([]string, 0)
+
+			client := &http.Client{
+				Transport: Retry(http.DefaultTransport, 2, time.Millisecond, func(collector, reason string) {
+					assert.Equal(t, "repo", collector)
+					reasons = append(reasons, reason)
+				}),
+			}
+
+			req, err := http.NewRequestWithContext(
+				WithCollector(context.Background(), "repo"),
+				tt.method,
+				server.URL+"/repos/promhippie/example",
+				nil,
+			)
+			require.NoError(t, err)
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+
+			assert.Equal(t, tt.want, resp.StatusCode)
+			assert.Equal(t, tt.requests, requests.Load())
+
+			if tt.reasons == nil {
+				assert.Empty(t, reasons)
+			} else {
+				assert.Equal(t, tt.reasons, reasons)
+			}
+		})
+	}
+}
+
+func TestRetryDeadline(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "4102444800")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: Retry(http.DefaultTransport, 3, time.Millisecond, func(_, _ string) {
+			t.Fatal("request should not be retried")
+		}),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/orgs/promhippie", nil)
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestRetryGraphQL(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := make([]byte, r.ContentLength)
+		_, _ = r.Body.Read(body)
+		assert.Equal(t, `{"query":"{viewer{login}}"}`, string(body))
+
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: Retry(http.DefaultTransport, 2, time.Millisecond, func(_, _ string) {}),
+	}
+
+	resp, err := client.Post(server.URL+"/graphql", "application/json", strings.NewReader(`{"query":"{viewer{login}}"}`))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestRetryAfter(t *testing.T) {
+	delay, ok := RetryAfter(http.Header{"Retry-After": []string{"30"}})
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, delay)
+
+	delay, ok = RetryAfter(http.Header{"Retry-After": []string{time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)}})
+	assert.True(t, ok)
+	assert.InDelta(t, time.Hour.Seconds(), delay.Seconds(), 2)
+
+	_, ok = RetryAfter(http.Header{})
+	assert.False(t, ok)
+}